@@ -0,0 +1,168 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrAsyncAPIGenerationFailed = errors.New("asyncapi generation failed")
+)
+
+const (
+	AsyncAPIVersion = "2.6.0"
+
+	asyncAPIWebSocketBindingVersion = "0.1.0"
+)
+
+// AsyncAPI is an AsyncAPI 2.6 document in its generic json representation.
+type AsyncAPI map[string]interface{}
+
+type asyncAPIDocument struct {
+	AsyncAPI   string                      `json:"asyncapi"`
+	Info       *openapi3.Info              `json:"info"`
+	Channels   map[string]*asyncAPIChannel `json:"channels"`
+	Components *asyncAPIComponents         `json:"components,omitempty"`
+}
+
+type asyncAPIComponents struct {
+	Schemas openapi3.Schemas `json:"schemas,omitempty"`
+}
+
+type asyncAPIChannel struct {
+	Description string                        `json:"description,omitempty"`
+	Parameters  map[string]*asyncAPIParameter `json:"parameters,omitempty"`
+	Publish     *asyncAPIOperation            `json:"publish,omitempty"`
+	Subscribe   *asyncAPIOperation            `json:"subscribe,omitempty"`
+	Bindings    map[string]interface{}        `json:"bindings,omitempty"`
+}
+
+type asyncAPIParameter struct {
+	Schema *openapi3.SchemaRef `json:"schema"`
+}
+
+type asyncAPIOperation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []map[string]string    `json:"tags,omitempty"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+type asyncAPIMessage struct {
+	Name    string              `json:"name"`
+	Payload *openapi3.SchemaRef `json:"payload"`
+}
+
+// AnnotateAsyncAPI returns an AsyncAPI 2.6 document of the channels matching all filters. Inbound
+// messages are documented as the publish operation (clients publish them to the channel) and outbound
+// messages as the subscribe operation. The payload of a message is its framed envelope.
+func (r *registry[T]) AnnotateAsyncAPI(info *openapi3.Info, filters ...EndpointFilter[T]) (AsyncAPI, error) {
+	schemas := make(openapi3.Schemas)
+	schemaGenerator := NewSchemaRefGenerator()
+
+	doc := &asyncAPIDocument{
+		AsyncAPI: AsyncAPIVersion,
+		Info:     info,
+		Channels: map[string]*asyncAPIChannel{},
+	}
+	for _, endpoint := range r.filteredEndpoints(filters) {
+		if !isChannel(endpoint) {
+			continue
+		}
+		if _, ok := doc.Channels[endpoint.Path]; ok {
+			return nil, fmt.Errorf("channel %s is registered more than once: %w", endpoint.Path, ErrAsyncAPIGenerationFailed)
+		}
+
+		channel := &asyncAPIChannel{
+			Description: endpoint.Description,
+		}
+		if endpoint.Parameters != nil {
+			parametersRef, err := schemaGenerator.GenerateSchemaRef(endpoint.Parameters, schemas)
+			if err != nil {
+				return nil, err
+			}
+			channel.Parameters = map[string]*asyncAPIParameter{}
+			for name, property := range parametersRef.Value.Properties {
+				channel.Parameters[name] = &asyncAPIParameter{Schema: property}
+			}
+		}
+		if endpoint.Protocol == ProtocolWebSocket {
+			binding := map[string]interface{}{
+				"method":         endpoint.Method,
+				"bindingVersion": asyncAPIWebSocketBindingVersion,
+			}
+			if endpoint.Query != nil {
+				queryRef, err := schemaGenerator.GenerateSchemaRef(endpoint.Query, schemas)
+				if err != nil {
+					return nil, err
+				}
+				binding["query"] = queryRef
+			}
+			channel.Bindings = map[string]interface{}{"ws": binding}
+		}
+
+		var tags []map[string]string
+		for _, tag := range endpoint.Tags {
+			tags = append(tags, map[string]string{"name": tag})
+		}
+		for _, direction := range []struct {
+			messages  []Message
+			suffix    string
+			operation **asyncAPIOperation
+		}{
+			{endpoint.Inbound, "Publish", &channel.Publish},
+			{endpoint.Outbound, "Subscribe", &channel.Subscribe},
+		} {
+			if len(direction.messages) == 0 {
+				continue
+			}
+			messages := make([]*asyncAPIMessage, 0, len(direction.messages))
+			for _, message := range direction.messages {
+				payload, err := messageSchemaRef(message, schemaGenerator, schemas)
+				if err != nil {
+					return nil, err
+				}
+				messages = append(messages, &asyncAPIMessage{Name: message.Name, Payload: payload})
+			}
+			*direction.operation = &asyncAPIOperation{
+				OperationID: endpoint.OperationID + direction.suffix,
+				Summary:     endpoint.Title,
+				Tags:        tags,
+				Message:     map[string]interface{}{"oneOf": messages},
+			}
+		}
+		doc.Channels[endpoint.Path] = channel
+	}
+	if len(schemas) > 0 {
+		doc.Components = &asyncAPIComponents{Schemas: schemas}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal asyncapi document: %w", err)
+	}
+	asyncAPI := AsyncAPI{}
+	if err := json.Unmarshal(b, &asyncAPI); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal asyncapi document: %w", err)
+	}
+	return asyncAPI, nil
+}
+
+// messageSchemaRef returns the schema of the envelope of the message.
+func messageSchemaRef(message Message, generator *SchemaRefGenerator, schemas openapi3.Schemas) (*openapi3.SchemaRef, error) {
+	envelope := openapi3.NewObjectSchema().
+		WithProperty("type", openapi3.NewStringSchema().WithEnum(message.Name))
+	envelope.Required = []string{"type"}
+	if message.Value != nil {
+		dataRef, err := generator.GenerateSchemaRef(message.Value, schemas)
+		if err != nil {
+			return nil, err
+		}
+		envelope.WithPropertyRef("data", dataRef)
+		envelope.Required = append(envelope.Required, "data")
+	}
+	return envelope.NewRef(), nil
+}
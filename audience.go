@@ -0,0 +1,99 @@
+package specs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrUnknownAudience = errors.New("unknown audience")
+)
+
+const (
+	VisibilityPublic   = "public"
+	VisibilityInternal = "internal"
+)
+
+// EndpointFilter selects the endpoints which are annotated into a document.
+type EndpointFilter[T interface{}] func(e *Endpoint[T]) bool
+
+// VisibilityFilter selects the endpoints with one of the visibilities. Endpoints without an explicit
+// visibility are public.
+func VisibilityFilter[T interface{}](visibilities ...string) EndpointFilter[T] {
+	return func(e *Endpoint[T]) bool {
+		visibility := e.Visibility
+		if visibility == "" {
+			visibility = VisibilityPublic
+		}
+		for _, v := range visibilities {
+			if v == visibility {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// TagFilter selects the endpoints with at least one of the tags.
+func TagFilter[T interface{}](tags ...string) EndpointFilter[T] {
+	return func(e *Endpoint[T]) bool {
+		for _, tag := range tags {
+			for _, t := range e.Tags {
+				if t == tag {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// NotFilter inverts the filter.
+func NotFilter[T interface{}](filter EndpointFilter[T]) EndpointFilter[T] {
+	return func(e *Endpoint[T]) bool {
+		return !filter(e)
+	}
+}
+
+func matchesFilters[T interface{}](e *Endpoint[T], filters []EndpointFilter[T]) bool {
+	for _, filter := range filters {
+		if !filter(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// filteredEndpoints returns the sorted endpoints which match all filters.
+func (r *registry[T]) filteredEndpoints(filters []EndpointFilter[T]) []*Endpoint[T] {
+	var endpoints []*Endpoint[T]
+	for _, endpoint := range r.sortedEndpoints() {
+		if matchesFilters(endpoint, filters) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// Audience defines a named audience (e.g. "public" or "partners") as the endpoints matching all filters.
+func (r *registry[T]) Audience(name string, filters ...EndpointFilter[T]) *registry[T] {
+	r.meta.audiences[name] = filters
+	return r
+}
+
+// Audiences returns the names of the defined audiences.
+func (r *registry[T]) Audiences() []string {
+	return sortedKeys(r.meta.audiences)
+}
+
+// AnnotateAudience annotates t with the endpoints of the named audience.
+func (r *registry[T]) AnnotateAudience(name string, t *openapi3.T) error {
+	filters, ok := r.meta.audiences[name]
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrUnknownAudience)
+	}
+	r.Annotate(t, filters...)
+	return nil
+}
@@ -0,0 +1,70 @@
+package specs
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type audienceTestNode struct {
+	Name     string              `json:"name"`
+	Children []*audienceTestNode `json:"children"`
+}
+
+func TestRegistry_AnnotateAudience(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", "").Tags("users").Response(200, []string{}, "Users found")
+	r.GET("/partners/users", "").Tags("partners").Response(200, []string{}, "Users found")
+	r.Group("/admin", GroupVisibility(VisibilityInternal)).
+		GET("/tree", "").
+		Response(200, audienceTestNode{}, "Tree found")
+
+	r.Audience("public", VisibilityFilter[string](VisibilityPublic), NotFilter(TagFilter[string]("partners")))
+	r.Audience("internal", VisibilityFilter[string](VisibilityPublic, VisibilityInternal))
+
+	tests := []struct {
+		audience   string
+		paths      []string
+		components int
+	}{
+		{audience: "public", paths: []string{"/users"}, components: 0},
+		{audience: "internal", paths: []string{"/admin/tree", "/partners/users", "/users"}, components: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.audience, func(t *testing.T) {
+			doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+			if err := r.AnnotateAudience(tt.audience, doc); err != nil {
+				t.Fatal(err)
+			}
+
+			paths := doc.Paths.InMatchingOrder()
+			if len(paths) != len(tt.paths) {
+				t.Fatalf("got paths %v, want %v", paths, tt.paths)
+			}
+			for _, path := range tt.paths {
+				if doc.Paths.Find(path).GetOperation(http.MethodGet) == nil {
+					t.Errorf("missing operation GET %s", path)
+				}
+			}
+			if len(doc.Components.Schemas) != tt.components {
+				t.Errorf("got %d component schemas, want %d", len(doc.Components.Schemas), tt.components)
+			}
+			loader := openapi3.NewLoader()
+			if err := loader.ResolveRefsIn(doc, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := doc.Validate(loader.Context); err != nil {
+				t.Errorf("invalid document: %v", err)
+			}
+		})
+	}
+
+	if err := r.AnnotateAudience("unknown", &openapi3.T{}); !errors.Is(err, ErrUnknownAudience) {
+		t.Errorf("got %v, want %v", err, ErrUnknownAudience)
+	}
+	if got := len(r.Eject()); got != 3 {
+		t.Errorf("got %d routes, want all 3 routes to be mounted", got)
+	}
+}
@@ -3,6 +3,10 @@ package specs
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 var (
@@ -13,21 +17,43 @@ var (
 )
 
 type Builder[T interface{}] interface {
+	OperationID(operationID string) Builder[T]
 	Title(title string) Builder[T]
 	Description(description string) Builder[T]
 	Deprecated() Builder[T]
+	Visibility(visibility string) Builder[T]
 	Tags(tags ...string) Builder[T]
+	Security(requirements ...openapi3.SecurityRequirement) Builder[T]
+	Middleware(middleware ...T) Builder[T]
 	Protocol(protocol string) Builder[T]
 	Status(status int) Builder[T]
 	Parameters(parameters interface{}) Builder[T]
 	Query(query interface{}) Builder[T]
 	Payload(data interface{}, mediaTypes ...string) Builder[T]
 	Response(status int, data interface{}, description string, mediaTypes ...string) Builder[T]
+	Stream(status int, events ...StreamEvent) Builder[T]
+	Paginated(pagination Pagination, description string) Builder[T]
+	Inbound(name string, data interface{}) Builder[T]
+	Outbound(name string, data interface{}) Builder[T]
+	Callback(name string, expression string, method string, payload interface{}, responses ...CallbackResponse) Builder[T]
 	Build() *Endpoint[T]
 }
 
 type builder[T interface{}] struct {
-	e *Endpoint[T]
+	e      *Endpoint[T]
+	routes map[string]*Endpoint[T]
+
+	// inheritedResponses are the statuses of responses declared by groups, which may be overridden
+	inheritedResponses map[int]struct{}
+
+	// group is the innermost group enclosing the endpoint, if any
+	group *group[T]
+
+	// codecs check the media types of payloads and responses, see WithCodecs
+	codecs *Codecs
+
+	// registrations are checked for duplicate operation IDs under StrictRoutes, nil otherwise
+	registrations *[]*Endpoint[T]
 }
 
 var (
@@ -38,6 +64,35 @@ func (b *builder[T]) panic(err error) {
 	panic(fmt.Errorf("failed to build endpoint (%s %s): %w", b.e.Method, b.e.Path, err))
 }
 
+// OperationID replaces the generated operation ID, e.g. to keep the IDs of an existing spec. Under
+// StrictRoutes, it panics if another endpoint uses the operation ID.
+func (b *builder[T]) OperationID(operationID string) Builder[T] {
+	if b.registrations != nil {
+		e := *b.e
+		e.OperationID = operationID
+		var conflicts []RouteConflict
+		for _, other := range *b.registrations {
+			if other == b.e {
+				continue
+			}
+			for _, conflict := range findRouteConflicts([]*Endpoint[T]{other}, &e) {
+				if conflict.Kind == ConflictDuplicateOperationID {
+					conflicts = append(conflicts, conflict)
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			b.panic(&RouteConflictError{Conflicts: conflicts})
+		}
+	}
+	if b.routes[b.e.OperationID] == b.e {
+		delete(b.routes, b.e.OperationID)
+	}
+	b.e.OperationID = operationID
+	b.routes[operationID] = b.e
+	return b
+}
+
 func (b *builder[T]) Title(title string) Builder[T] {
 	b.e.Title = title
 	return b
@@ -53,8 +108,24 @@ func (b *builder[T]) Deprecated() Builder[T] {
 	return b
 }
 
+func (b *builder[T]) Visibility(visibility string) Builder[T] {
+	b.e.Visibility = visibility
+	return b
+}
+
+// Tags replaces the tags of the endpoint. The tags of enclosing groups are kept.
 func (b *builder[T]) Tags(tags ...string) Builder[T] {
-	b.e.Tags = tags
+	b.e.Tags = b.group.mergeTags(tags)
+	return b
+}
+
+func (b *builder[T]) Security(requirements ...openapi3.SecurityRequirement) Builder[T] {
+	b.e.Security = append(openapi3.SecurityRequirements{}, requirements...)
+	return b
+}
+
+func (b *builder[T]) Middleware(middleware ...T) Builder[T] {
+	b.e.Middleware = append(b.e.Middleware, middleware...)
 	return b
 }
 
@@ -80,6 +151,9 @@ func (b *builder[T]) Query(query interface{}) Builder[T] {
 	if b.e.Query != nil {
 		b.panic(fmt.Errorf("query already defined: %w", ErrQueryAnnotationFailed))
 	}
+	if _, err := newQueryGrammar(reflect.TypeOf(query)); err != nil {
+		b.panic(fmt.Errorf("%v: %w", err, ErrQueryAnnotationFailed))
+	}
 	b.e.Query = query
 	return b
 }
@@ -94,6 +168,12 @@ func (b *builder[T]) Payload(data interface{}, mediaTypes ...string) Builder[T]
 				b.panic(fmt.Errorf("payload with media type %s already defined: %w", mediaType, ErrPayloadAnnotationFailed))
 			}
 		}
+		if mediaType != MediaTypeMultipartForm && containsFile(data) {
+			b.panic(fmt.Errorf("payload with media type %s cannot contain files: %w", mediaType, ErrPayloadAnnotationFailed))
+		}
+		if b.codecs != nil && !b.codecs.supports(mediaType) {
+			b.panic(fmt.Errorf("payload with media type %s has no codec: %w", mediaType, ErrPayloadAnnotationFailed))
+		}
 		b.e.Payload = append(b.e.Payload, Body{
 			MediaType: mediaType,
 			Value:     data,
@@ -107,15 +187,62 @@ func (b *builder[T]) Response(status int, data interface{}, description string,
 		b.e.Response = map[int]Response{}
 	}
 	if _, hasStatusDefined := b.e.Response[status]; hasStatusDefined {
-		b.panic(fmt.Errorf("response with status code %d already defined: %w", status, ErrResponseAnnotationFailed))
+		if _, isInherited := b.inheritedResponses[status]; !isInherited {
+			b.panic(fmt.Errorf("response with status code %d already defined: %w", status, ErrResponseAnnotationFailed))
+		}
+		delete(b.inheritedResponses, status)
 	}
-	for _, mediaType := range safeMediaTypes(mediaTypes) {
-		b.e.Response[status] = Response{
-			Description: description,
-			MediaType:   mediaType,
-			Value:       data,
+	mediaTypes = safeMediaTypes(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		// responses without a value (e.g. streams) are not encoded
+		if data != nil && b.codecs != nil && !b.codecs.supports(mediaType) {
+			b.panic(fmt.Errorf("response with media type %s has no codec: %w", mediaType, ErrResponseAnnotationFailed))
+		}
+	}
+	b.e.Response[status] = Response{
+		Description: description,
+		MediaType:   mediaTypes[0],
+		MediaTypes:  mediaTypes,
+		Value:       data,
+	}
+	return b
+}
+
+func (b *builder[T]) Stream(status int, events ...StreamEvent) Builder[T] {
+	if len(events) == 0 {
+		b.panic(fmt.Errorf("stream with status code %d declares no events: %w", status, ErrStreamAnnotationFailed))
+	}
+	names := map[string]struct{}{}
+	for _, event := range events {
+		if event.Name == "" || strings.ContainsAny(event.Name, "\r\n") {
+			b.panic(fmt.Errorf("invalid event name %q: %w", event.Name, ErrStreamAnnotationFailed))
+		}
+		if event.Value == nil {
+			b.panic(fmt.Errorf("event %s has no value: %w", event.Name, ErrStreamAnnotationFailed))
 		}
+		if _, ok := names[event.Name]; ok {
+			b.panic(fmt.Errorf("event %s already defined: %w", event.Name, ErrStreamAnnotationFailed))
+		}
+		names[event.Name] = struct{}{}
 	}
+
+	b.Response(status, nil, "Event stream", MediaTypeEventStream)
+	response := b.e.Response[status]
+	response.Events = append([]StreamEvent{}, events...)
+	b.e.Response[status] = response
+	return b
+}
+
+// Inbound declares a message received by the channel. Declaring messages makes the endpoint a
+// ProtocolWebSocket channel unless another protocol is set.
+func (b *builder[T]) Inbound(name string, data interface{}) Builder[T] {
+	b.e.Inbound = b.message(b.e.Inbound, name, data)
+	return b
+}
+
+// Outbound declares a message sent by the channel.
+func (b *builder[T]) Outbound(name string, data interface{}) Builder[T] {
+	b.e.Outbound = b.message(b.e.Outbound, name, data)
 	return b
 }
 
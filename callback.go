@@ -0,0 +1,146 @@
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrCallbackAnnotationFailed = errors.New("callback annotation failed")
+)
+
+// Callback is a request sent by the api to a url taken from the request of an operation, declared
+// using Builder.Callback.
+type Callback struct {
+	Name       string
+	Expression string
+	Method     string
+	Payload    interface{}
+	Response   map[int]Response
+}
+
+// CallbackResponse is a response expected from the receiver of a callback, see Respond.
+type CallbackResponse struct {
+	Status      int
+	Description string
+	Value       interface{}
+}
+
+// Respond declares a response of a callback with the status.
+func Respond(status int, data interface{}, description string) CallbackResponse {
+	return CallbackResponse{Status: status, Description: description, Value: data}
+}
+
+// Callback declares the request sent to the url of the runtime expression, e.g.
+// {$request.body#/callbackUrl}. Callbacks without responses expect a 200 response.
+func (b *builder[T]) Callback(name string, expression string, method string, payload interface{}, responses ...CallbackResponse) Builder[T] {
+	if name == "" {
+		b.panic(fmt.Errorf("callback name is empty: %w", ErrCallbackAnnotationFailed))
+	}
+	if !validCallbackExpression(expression) {
+		b.panic(fmt.Errorf("invalid expression %q of callback %s: %w", expression, name, ErrCallbackAnnotationFailed))
+	}
+	if !validMethod(method) {
+		b.panic(fmt.Errorf("invalid method %q of callback %s: %w", method, name, ErrCallbackAnnotationFailed))
+	}
+	if payload != nil && method == http.MethodGet {
+		b.panic(fmt.Errorf("callback %s sends a payload with method GET: %w", name, ErrCallbackAnnotationFailed))
+	}
+	for _, callback := range b.e.Callbacks {
+		if callback.Name == name && callback.Expression == expression && callback.Method == method {
+			b.panic(fmt.Errorf("callback %s %s %s already defined: %w", name, method, expression, ErrCallbackAnnotationFailed))
+		}
+	}
+
+	if len(responses) == 0 {
+		responses = []CallbackResponse{Respond(http.StatusOK, nil, "Callback received")}
+	}
+	callback := Callback{
+		Name:       name,
+		Expression: expression,
+		Method:     method,
+		Payload:    payload,
+		Response:   map[int]Response{},
+	}
+	for _, response := range responses {
+		if _, ok := callback.Response[response.Status]; ok {
+			b.panic(fmt.Errorf("response with status code %d of callback %s already defined: %w", response.Status, name, ErrCallbackAnnotationFailed))
+		}
+		callback.Response[response.Status] = Response{
+			Description: response.Description,
+			MediaType:   "application/json",
+			Value:       response.Value,
+		}
+	}
+	b.e.Callbacks = append(b.e.Callbacks, callback)
+	return b
+}
+
+// validCallbackExpression reports whether every {...} of the expression is a runtime expression
+// referring to the request or response of the operation.
+func validCallbackExpression(expression string) bool {
+	if expression == "" {
+		return false
+	}
+	for rest := expression; ; {
+		open := strings.IndexAny(rest, "{}")
+		if open == -1 {
+			return true
+		}
+		if rest[open] == '}' {
+			return false
+		}
+		close := strings.IndexAny(rest[open+1:], "{}")
+		if close == -1 || rest[open+1+close] == '{' {
+			return false
+		}
+		switch inner := rest[open+1 : open+1+close]; {
+		case inner == "$url", inner == "$method", inner == "$statusCode",
+			strings.HasPrefix(inner, "$request."), strings.HasPrefix(inner, "$response."):
+		default:
+			return false
+		}
+		rest = rest[open+close+2:]
+	}
+}
+
+func validMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// annotateCallbacks returns the callbacks of an operation, grouped by name and expression.
+func annotateCallbacks[T interface{}](callbacks []Callback, schemaGenerator *SchemaRefGenerator, formSchemaGenerator *SchemaRefGenerator, xmlSchemaGenerator *SchemaRefGenerator, schemas openapi3.Schemas) openapi3.Callbacks {
+	if len(callbacks) == 0 {
+		return nil
+	}
+
+	refs := openapi3.Callbacks{}
+	for _, callback := range callbacks {
+		e := &Endpoint[T]{
+			Method:   callback.Method,
+			Response: callback.Response,
+		}
+		if callback.Payload != nil {
+			e.Payload = []Body{{MediaType: "application/json", Value: callback.Payload}}
+		}
+
+		if refs[callback.Name] == nil {
+			refs[callback.Name] = &openapi3.CallbackRef{Value: &openapi3.Callback{}}
+		}
+		paths := *refs[callback.Name].Value
+		if paths[callback.Expression] == nil {
+			paths[callback.Expression] = &openapi3.PathItem{}
+		}
+		paths[callback.Expression].SetOperation(callback.Method, annotateOperation(e, schemaGenerator, formSchemaGenerator, xmlSchemaGenerator, schemas))
+	}
+	return refs
+}
@@ -0,0 +1,180 @@
+package specs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+var (
+	ErrMessageAnnotationFailed = errors.New("message annotation failed")
+	ErrMessageInvalid          = errors.New("message invalid")
+	ErrMessageNotHandled       = errors.New("message not handled")
+)
+
+const (
+	ProtocolHTTP      = "http"
+	ProtocolWebSocket = "ws"
+)
+
+// Message is a message exchanged over a channel, declared using Builder.Inbound and Builder.Outbound.
+// Messages are framed as a json envelope of their name and data, e.g. {"type":"join","data":{...}}.
+type Message struct {
+	Name  string
+	Value interface{}
+}
+
+// isChannel reports whether the endpoint is a message-based channel, which is documented by
+// AnnotateAsyncAPI instead of Annotate.
+func isChannel[T interface{}](e *Endpoint[T]) bool {
+	return e.Protocol != "" && e.Protocol != ProtocolHTTP && e.Protocol != "https"
+}
+
+// Channel registers a message-based channel using protocol (e.g. ProtocolWebSocket), which is opened
+// by a GET request to path.
+func (r *registry[T]) Channel(protocol string, path string, handler T) Builder[T] {
+	return r.Build(http.MethodGet, path, handler).Protocol(protocol)
+}
+
+func (b *builder[T]) message(messages []Message, name string, value interface{}) []Message {
+	if name == "" {
+		b.panic(fmt.Errorf("message name is empty: %w", ErrMessageAnnotationFailed))
+	}
+	for _, message := range messages {
+		if message.Name == name {
+			b.panic(fmt.Errorf("message %s already defined: %w", name, ErrMessageAnnotationFailed))
+		}
+	}
+	if b.e.Protocol == "" {
+		b.e.Protocol = ProtocolWebSocket
+	}
+	return append(messages, Message{
+		Name:  name,
+		Value: value,
+	})
+}
+
+// EncodeMessage frames v as the message name.
+func EncodeMessage(name string, v interface{}) ([]byte, error) {
+	var data json.RawMessage
+	if v != nil {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message %s: %w", name, err)
+		}
+		data = b
+	}
+	return json.Marshal(struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data,omitempty"`
+	}{name, data})
+}
+
+type DispatcherOption func(*dispatcherOptions)
+
+type dispatcherOptions struct {
+	validate StructValidator
+}
+
+// DispatcherValidator validates decoded messages using validate in addition to their schema, e.g. to
+// check the validate tags of the message types.
+func DispatcherValidator(validate StructValidator) DispatcherOption {
+	return func(o *dispatcherOptions) {
+		o.validate = validate
+	}
+}
+
+// Dispatcher decodes incoming messages of a channel, validates them against their schema and calls the
+// handler registered for their name using HandleMessage. It is independent of the websocket
+// implementation, which only has to pass the received frames to Dispatch.
+type Dispatcher struct {
+	messages map[string]reflect.Type
+	handlers map[string]func(ctx context.Context, data json.RawMessage) error
+	schemas  *SchemaValidator
+	validate StructValidator
+}
+
+// NewDispatcher returns a Dispatcher for the messages, usually the Inbound messages of an endpoint.
+func NewDispatcher(messages []Message, opts ...DispatcherOption) *Dispatcher {
+	options := &dispatcherOptions{}
+	for _, applyOption := range opts {
+		applyOption(options)
+	}
+
+	d := &Dispatcher{
+		messages: make(map[string]reflect.Type, len(messages)),
+		handlers: map[string]func(ctx context.Context, data json.RawMessage) error{},
+		schemas:  NewSchemaValidator(),
+		validate: options.validate,
+	}
+	for _, message := range messages {
+		var t reflect.Type
+		if message.Value != nil {
+			t = removeIndirect(reflect.TypeOf(message.Value))
+		}
+		d.messages[message.Name] = t
+	}
+	return d
+}
+
+// HandleMessage registers the handler of the message name. It panics if the message is not declared
+// or is declared with a type other than V.
+func HandleMessage[V interface{}](d *Dispatcher, name string, handler func(ctx context.Context, message V) error) {
+	declared, ok := d.messages[name]
+	if !ok {
+		panic(fmt.Errorf("message %s is not declared: %w", name, ErrMessageAnnotationFailed))
+	}
+	t := reflect.TypeOf((*V)(nil)).Elem()
+	if declared != nil && removeIndirect(t) != declared {
+		panic(fmt.Errorf("message %s is declared as %v, got handler of %v: %w", name, declared, t, ErrMessageAnnotationFailed))
+	}
+
+	d.handlers[name] = func(ctx context.Context, data json.RawMessage) error {
+		if declared == nil {
+			var zero V
+			return handler(ctx, zero)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("message %s has no data: %w", name, ErrMessageInvalid)
+		}
+		if err := d.schemas.ValidateJSON(declared, data); err != nil {
+			return fmt.Errorf("message %s does not match its schema: %v: %w", name, err, ErrMessageInvalid)
+		}
+		value := reflect.New(declared)
+		if err := json.Unmarshal(data, value.Interface()); err != nil {
+			return fmt.Errorf("failed to decode message %s: %v: %w", name, err, ErrMessageInvalid)
+		}
+		if d.validate != nil && declared.Kind() == reflect.Struct {
+			if err := d.validate.Struct(value.Interface()); err != nil {
+				return fmt.Errorf("message %s: %v: %w", name, err, ErrMessageInvalid)
+			}
+		}
+		if t.Kind() == reflect.Ptr {
+			return handler(ctx, value.Interface().(V))
+		}
+		return handler(ctx, value.Elem().Interface().(V))
+	}
+}
+
+// Dispatch decodes the framed message and calls its handler. Messages which are malformed, not declared
+// or do not pass validation are rejected with ErrMessageInvalid.
+func (d *Dispatcher) Dispatch(ctx context.Context, frame []byte) error {
+	var envelope struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(frame, &envelope); err != nil {
+		return fmt.Errorf("failed to decode message: %v: %w", err, ErrMessageInvalid)
+	}
+	if _, ok := d.messages[envelope.Type]; !ok {
+		return fmt.Errorf("message %q is not declared: %w", envelope.Type, ErrMessageInvalid)
+	}
+	handler, ok := d.handlers[envelope.Type]
+	if !ok {
+		return fmt.Errorf("message %s: %w", envelope.Type, ErrMessageNotHandled)
+	}
+	return handler(ctx, envelope.Data)
+}
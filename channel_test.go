@@ -0,0 +1,232 @@
+package specs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type channelTestRoom struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type channelTestQuery struct {
+	Token string `json:"token"`
+}
+
+type channelTestJoin struct {
+	Name string `json:"name" validate:"required,min=1"`
+}
+
+type channelTestChat struct {
+	Text string `json:"text" validate:"required"`
+}
+
+func TestAnnotateSkipsChannels(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.Channel(ProtocolWebSocket, "/rooms/{id}", "").Parameters(channelTestRoom{}).Inbound("join", channelTestJoin{})
+	r.GET("/rooms", "").Response(200, []channelTestRoom{}, "Rooms found")
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc)
+	if doc.Paths.Find("/rooms/{id}") != nil || doc.Paths.Find("/rooms") == nil {
+		t.Errorf("got paths %v", doc.Paths)
+	}
+}
+
+func TestAnnotateAsyncAPI(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.Channel(ProtocolWebSocket, "/rooms/{id}", "").
+		Title("Chat room").
+		Tags("chat").
+		Parameters(channelTestRoom{}).
+		Query(channelTestQuery{}).
+		Inbound("join", channelTestJoin{}).
+		Inbound("chat", &channelTestChat{}).
+		Inbound("leave", nil).
+		Outbound("chat", channelTestChat{})
+	r.GET("/rooms", "").Response(200, []channelTestRoom{}, "Rooms found")
+
+	doc, err := r.AnnotateAsyncAPI(&openapi3.Info{Title: "test", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(doc)
+	var got struct {
+		AsyncAPI string `json:"asyncapi"`
+		Channels map[string]struct {
+			Parameters map[string]interface{} `json:"parameters"`
+			Publish    struct {
+				OperationID string `json:"operationId"`
+				Message     struct {
+					OneOf []struct {
+						Name    string `json:"name"`
+						Payload struct {
+							Required   []string                          `json:"required"`
+							Properties map[string]map[string]interface{} `json:"properties"`
+						} `json:"payload"`
+					} `json:"oneOf"`
+				} `json:"message"`
+			} `json:"publish"`
+			Subscribe struct {
+				OperationID string `json:"operationId"`
+			} `json:"subscribe"`
+			Bindings struct {
+				WS struct {
+					Method string                 `json:"method"`
+					Query  map[string]interface{} `json:"query"`
+				} `json:"ws"`
+			} `json:"bindings"`
+		} `json:"channels"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	channel, ok := got.Channels["/rooms/{id}"]
+	if !ok || len(got.Channels) != 1 {
+		t.Fatalf("got channels %v", got.Channels)
+	}
+	messages := channel.Publish.Message.OneOf
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "should declare the version", got: got.AsyncAPI, want: AsyncAPIVersion},
+		{name: "should declare path parameters", got: channel.Parameters["id"] != nil, want: true},
+		{name: "should declare the websocket binding", got: channel.Bindings.WS.Method, want: "GET"},
+		{name: "should declare the query in the binding", got: channel.Bindings.WS.Query["properties"] != nil, want: true},
+		{name: "should declare inbound messages as publish", got: channel.Publish.OperationID, want: "getRoomsByIdPublish"},
+		{name: "should declare outbound messages as subscribe", got: channel.Subscribe.OperationID, want: "getRoomsByIdSubscribe"},
+		{name: "should declare every message", got: len(messages), want: 3},
+		{name: "should name messages", got: messages[0].Name, want: "join"},
+		{name: "should declare the envelope", got: messages[0].Payload.Properties["data"] != nil && messages[0].Payload.Properties["type"] != nil, want: true},
+		{name: "should not declare data of messages without value", got: messages[2].Payload.Properties["data"] == nil, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageAnnotationFailed(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func()
+	}{
+		{name: "should reject unnamed messages", build: func() {
+			NewRegistry[string]().Channel(ProtocolWebSocket, "/rooms", "").Inbound("", channelTestJoin{})
+		}},
+		{name: "should reject duplicate messages", build: func() {
+			NewRegistry[string]().Channel(ProtocolWebSocket, "/rooms", "").Inbound("join", channelTestJoin{}).Inbound("join", channelTestJoin{})
+		}},
+		{name: "should reject handlers of undeclared messages", build: func() {
+			HandleMessage(NewDispatcher(nil), "join", func(ctx context.Context, m channelTestJoin) error { return nil })
+		}},
+		{name: "should reject handlers of other types", build: func() {
+			HandleMessage(NewDispatcher([]Message{{Name: "join", Value: channelTestJoin{}}}), "join", func(ctx context.Context, m channelTestChat) error { return nil })
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrMessageAnnotationFailed) {
+					t.Errorf("got %v, want %v", err, ErrMessageAnnotationFailed)
+				}
+			}()
+			tt.build()
+		})
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	var (
+		joined string
+		chat   *channelTestChat
+		left   bool
+	)
+	d := NewDispatcher([]Message{
+		{Name: "join", Value: channelTestJoin{}},
+		{Name: "chat", Value: &channelTestChat{}},
+		{Name: "leave"},
+	})
+	HandleMessage(d, "join", func(ctx context.Context, m channelTestJoin) error {
+		joined = m.Name
+		return nil
+	})
+	HandleMessage(d, "chat", func(ctx context.Context, m *channelTestChat) error {
+		chat = m
+		return nil
+	})
+
+	frame, err := EncodeMessage("join", channelTestJoin{Name: "jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Dispatch(context.Background(), frame); err != nil || joined != "jane" {
+		t.Errorf("got %v, joined %q", err, joined)
+	}
+	if err := d.Dispatch(context.Background(), []byte(`{"type":"chat","data":{"text":"hi"}}`)); err != nil || chat == nil || chat.Text != "hi" {
+		t.Errorf("got %v, chat %v", err, chat)
+	}
+	if err := d.Dispatch(context.Background(), []byte(`{"type":"leave"}`)); !errors.Is(err, ErrMessageNotHandled) {
+		t.Errorf("got %v, want %v", err, ErrMessageNotHandled)
+	}
+	HandleMessage(d, "leave", func(ctx context.Context, m struct{}) error {
+		left = true
+		return nil
+	})
+	if err := d.Dispatch(context.Background(), []byte(`{"type":"leave"}`)); err != nil || !left {
+		t.Errorf("got %v, left %v", err, left)
+	}
+
+	invalidTests := []struct {
+		name  string
+		frame string
+	}{
+		{name: "should reject malformed frames", frame: `{"type":`},
+		{name: "should reject undeclared messages", frame: `{"type":"kick","data":{}}`},
+		{name: "should reject messages without data", frame: `{"type":"join"}`},
+		{name: "should reject data of the wrong type", frame: `{"type":"join","data":{"name":1}}`},
+		{name: "should reject invalid data", frame: `{"type":"join","data":{"name":""}}`},
+	}
+	for _, tt := range invalidTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.Dispatch(context.Background(), []byte(tt.frame)); !errors.Is(err, ErrMessageInvalid) {
+				t.Errorf("got %v, want %v", err, ErrMessageInvalid)
+			}
+		})
+	}
+}
+
+type channelTestValidatorFunc func(s interface{}) error
+
+func (f channelTestValidatorFunc) Struct(s interface{}) error {
+	return f(s)
+}
+
+func TestDispatcherValidator(t *testing.T) {
+	d := NewDispatcher([]Message{{Name: "join", Value: channelTestJoin{}}}, DispatcherValidator(channelTestValidatorFunc(func(s interface{}) error {
+		if s.(*channelTestJoin).Name == "admin" {
+			return errors.New("name is reserved")
+		}
+		return nil
+	})))
+	HandleMessage(d, "join", func(ctx context.Context, m channelTestJoin) error { return nil })
+
+	if err := d.Dispatch(context.Background(), []byte(`{"type":"join","data":{"name":"jane"}}`)); err != nil {
+		t.Errorf("got %v", err)
+	}
+	if err := d.Dispatch(context.Background(), []byte(`{"type":"join","data":{"name":"admin"}}`)); !errors.Is(err, ErrMessageInvalid) {
+		t.Errorf("got %v, want %v", err, ErrMessageInvalid)
+	}
+}
@@ -0,0 +1,140 @@
+// Command specs provides tooling around specs generated from a registry.
+//
+// Usage:
+//
+//	specs diff [-format text|json] [-fail-on-breaking=false] <baseline> <current>
+//	specs scaffold [-package name] [-registry name] <spec> [output]
+//
+// diff compares two OpenAPI 3 documents (json or yaml) and exits with status 1 if the current
+// document contains changes that break clients of the baseline, unless -fail-on-breaking=false.
+//
+// scaffold generates go types, registrations and handler stubs from an OpenAPI 3 document for
+// spec-first APIs. The output is written to stdout unless an output file is given.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jakoblorz/specs"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: specs <command> [arguments]\n\ncommands:\n")
+	fmt.Fprintf(os.Stderr, "  diff [-format text|json] [-fail-on-breaking=false] <baseline> <current>\n")
+	fmt.Fprintf(os.Stderr, "  scaffold [-package name] [-registry name] <spec> [output]\n")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "diff":
+		os.Exit(runDiff(os.Args[2:]))
+	case "scaffold":
+		os.Exit(runScaffold(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	failOnBreaking := flags.Bool("fail-on-breaking", true, "exit with status 1 if breaking changes are detected")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: specs diff [-format text|json] [-fail-on-breaking=false] <baseline> <current>\n")
+		return 2
+	}
+
+	base, err := loadSpec(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	current, err := loadSpec(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	diff, err := specs.DiffSpecs(base, current)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	switch *format {
+	case "json":
+		err = diff.WriteJSON(os.Stdout)
+	case "text":
+		err = diff.WriteText(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if *failOnBreaking && diff.Breaking() {
+		return 1
+	}
+	return 0
+}
+
+func runScaffold(args []string) int {
+	flags := flag.NewFlagSet("scaffold", flag.ExitOnError)
+	packageName := flags.String("package", "api", "package name of the generated code")
+	registryName := flags.String("registry", "", "existing registry variable to register the endpoints on, declares a new registry if empty")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		fmt.Fprintf(os.Stderr, "usage: specs scaffold [-package name] [-registry name] <spec> [output]\n")
+		return 2
+	}
+
+	t, err := loadSpec(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	opts := []specs.ScaffoldOption{specs.ScaffoldPackage(*packageName)}
+	if *registryName != "" {
+		opts = append(opts, specs.ScaffoldRegistry(*registryName))
+	}
+
+	var b bytes.Buffer
+	if err := specs.GenerateScaffold(&b, t, opts...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if flags.NArg() == 2 {
+		err = os.WriteFile(flags.Arg(1), b.Bytes(), 0644)
+	} else {
+		_, err = os.Stdout.Write(b.Bytes())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return 0
+}
+
+func loadSpec(path string) (*openapi3.T, error) {
+	t, err := openapi3.NewLoader().LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return t, nil
+}
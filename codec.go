@@ -0,0 +1,456 @@
+package specs
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrNotAcceptable        = errors.New("not acceptable")
+)
+
+const (
+	MediaTypeJSON    = "application/json"
+	MediaTypeXML     = "application/xml"
+	MediaTypeCBOR    = "application/cbor"
+	MediaTypeMsgPack = "application/msgpack"
+	MediaTypeText    = "text/plain"
+
+	MediaTypeOctetStream = "application/octet-stream"
+)
+
+// Codec decodes request bodies and encodes response bodies of a media type.
+type Codec interface {
+	Decode(r io.Reader, v interface{}) error
+	Encode(w io.Writer, v interface{}) error
+}
+
+// Codecs maps media types to codecs. Media types are matched ignoring their parameters (e.g.
+// charset) and may be registered as wildcards such as application/*+json or text/*, which match
+// if no codec is registered for the exact media type.
+type Codecs struct {
+	mu       sync.RWMutex
+	patterns []string
+	codecs   map[string]Codec
+}
+
+// DefaultCodecs contains JSON (including application/*+json), XML (including text/xml and
+// application/*+xml), CBOR, MessagePack, application/x-www-form-urlencoded, text/plain and
+// application/octet-stream. Multipart forms are decoded using BindForm.
+var DefaultCodecs = newDefaultCodecs()
+
+func NewCodecs() *Codecs {
+	return &Codecs{codecs: map[string]Codec{}}
+}
+
+func newDefaultCodecs() *Codecs {
+	c := NewCodecs()
+	c.Register(MediaTypeJSON, JSONCodec{})
+	c.Register("application/*+json", JSONCodec{})
+	c.Register(MediaTypeXML, XMLCodec{})
+	c.Register("text/xml", XMLCodec{})
+	c.Register("application/*+xml", XMLCodec{})
+	c.Register(MediaTypeCBOR, CBORCodec{})
+	c.Register(MediaTypeMsgPack, MsgPackCodec{})
+	c.Register("application/x-msgpack", MsgPackCodec{})
+	c.Register(MediaTypeURLEncodedForm, FormCodec{})
+	c.Register(MediaTypeText, TextCodec{})
+	c.Register(MediaTypeOctetStream, BinaryCodec{})
+	return c
+}
+
+// Register sets the codec of the media type, replacing a previously registered codec. It panics
+// if the media type is invalid.
+func (c *Codecs) Register(mediaType string, codec Codec) {
+	pattern, err := normalizeMediaType(mediaType)
+	if err != nil {
+		panic(fmt.Errorf("invalid media type %q: %v: %w", mediaType, err, ErrUnsupportedMediaType))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.codecs[pattern]; !ok {
+		c.patterns = append(c.patterns, pattern)
+	}
+	c.codecs[pattern] = codec
+}
+
+// Lookup returns the codec of the media type, which may contain parameters.
+func (c *Codecs) Lookup(mediaType string) (Codec, bool) {
+	mediaType, err := normalizeMediaType(mediaType)
+	if err != nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if codec, ok := c.codecs[mediaType]; ok {
+		return codec, true
+	}
+	// the most specific wildcard wins, e.g. application/*+json over application/*
+	var best string
+	for _, pattern := range c.patterns {
+		if matchMediaType(pattern, mediaType) && mediaTypeSpecificity(pattern) > mediaTypeSpecificity(best) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+	return c.codecs[best], true
+}
+
+// supports reports whether bodies of the media type can be decoded and encoded.
+func (c *Codecs) supports(mediaType string) bool {
+	if mt, err := normalizeMediaType(mediaType); err == nil && mt == MediaTypeMultipartForm {
+		return true
+	}
+	_, ok := c.Lookup(mediaType)
+	return ok
+}
+
+// Decode decodes r into v using the codec of the content type.
+func (c *Codecs) Decode(r io.Reader, contentType string, v interface{}) error {
+	codec, ok := c.Lookup(contentType)
+	if !ok {
+		return fmt.Errorf("no codec for %q: %w", contentType, ErrUnsupportedMediaType)
+	}
+	return codec.Decode(r, v)
+}
+
+// DecodeRequest decodes the request body into v using the codec of its Content-Type. Multipart forms
+// are decoded using BindForm, keeping at most maxMemory bytes in memory.
+func (c *Codecs) DecodeRequest(req *http.Request, v interface{}, maxMemory int64) error {
+	contentType := req.Header.Get("Content-Type")
+	if mediaType, err := normalizeMediaType(contentType); err == nil && mediaType == MediaTypeMultipartForm {
+		return BindForm(req, v, maxMemory)
+	}
+	return c.Decode(req.Body, contentType, v)
+}
+
+// WriteResponse writes v with status, encoded in the media type of mediaTypes preferred by the Accept
+// header of req. It fails with ErrNotAcceptable before writing if none of them is accepted, the
+// response is then usually 406 Not Acceptable. A nil v is written without body.
+func (c *Codecs) WriteResponse(w http.ResponseWriter, req *http.Request, status int, v interface{}, mediaTypes ...string) error {
+	if v == nil {
+		w.WriteHeader(status)
+		return nil
+	}
+
+	mediaType, err := Negotiate(req.Header.Get("Accept"), safeMediaTypes(mediaTypes))
+	if err != nil {
+		return err
+	}
+	codec, ok := c.Lookup(mediaType)
+	if !ok {
+		return fmt.Errorf("no codec for %q: %w", mediaType, ErrNotAcceptable)
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	return codec.Encode(w, v)
+}
+
+// MatchMediaType returns the media type of mediaTypes (e.g. the declared payloads) matching the
+// content type of a request, which may contain parameters and match wildcard media types.
+func MatchMediaType(contentType string, mediaTypes []string) (string, bool) {
+	normalized, err := normalizeMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	var best, bestPattern string
+	for _, mediaType := range mediaTypes {
+		pattern, err := normalizeMediaType(mediaType)
+		if err != nil || !matchMediaType(pattern, normalized) {
+			continue
+		}
+		if mediaTypeSpecificity(pattern) > mediaTypeSpecificity(bestPattern) {
+			best, bestPattern = mediaType, pattern
+		}
+	}
+	return best, best != ""
+}
+
+// Negotiate returns the media type of offered preferred by the Accept header, following the quality
+// values and specificity of the accepted media ranges. Offered media types are preferred in order if
+// the header is empty or accepts them equally. It fails with ErrNotAcceptable if none is accepted.
+func Negotiate(accept string, offered []string) (string, error) {
+	if len(offered) == 0 {
+		return "", fmt.Errorf("no media types offered: %w", ErrNotAcceptable)
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offered[0], nil
+	}
+
+	type mediaRange struct {
+		pattern string
+		q       float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		pattern, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{pattern: pattern, q: q})
+	}
+	// the most specific range accepting an offered media type determines its quality
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return mediaTypeSpecificity(ranges[i].pattern) > mediaTypeSpecificity(ranges[j].pattern)
+	})
+
+	var best string
+	var bestQ float64
+	for _, mediaType := range offered {
+		normalized, err := normalizeMediaType(mediaType)
+		if err != nil {
+			continue
+		}
+		for _, r := range ranges {
+			if !matchMediaType(r.pattern, normalized) {
+				continue
+			}
+			if r.q > bestQ {
+				best, bestQ = mediaType, r.q
+			}
+			break
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("none of %s is accepted by %q: %w", strings.Join(offered, ", "), accept, ErrNotAcceptable)
+	}
+	return best, nil
+}
+
+func normalizeMediaType(mediaType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(mediaType, "/") {
+		return "", fmt.Errorf("missing subtype")
+	}
+	return mediaType, nil
+}
+
+// matchMediaType reports whether the pattern (e.g. */*, application/*, application/*+json) matches
+// the media type, both without parameters.
+func matchMediaType(pattern string, mediaType string) bool {
+	patternType, patternSubtype, _ := strings.Cut(pattern, "/")
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	if patternType != "*" && patternType != typ {
+		return false
+	}
+	switch {
+	case patternSubtype == "*":
+		return true
+	case strings.HasPrefix(patternSubtype, "*+"):
+		return strings.HasSuffix(subtype, patternSubtype[1:])
+	}
+	return patternSubtype == subtype
+}
+
+func mediaTypeSpecificity(pattern string) int {
+	switch {
+	case pattern == "":
+		return -1
+	case pattern == "*/*":
+		return 0
+	case strings.HasSuffix(pattern, "/*"):
+		return 1
+	case strings.Contains(pattern, "/*+"):
+		return 2
+	}
+	return 3
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Decode(r io.Reader, v interface{}) error { return json.NewDecoder(r).Decode(v) }
+func (JSONCodec) Encode(w io.Writer, v interface{}) error { return json.NewEncoder(w).Encode(v) }
+
+type XMLCodec struct{}
+
+func (XMLCodec) Decode(r io.Reader, v interface{}) error { return xml.NewDecoder(r).Decode(v) }
+func (XMLCodec) Encode(w io.Writer, v interface{}) error { return xml.NewEncoder(w).Encode(v) }
+
+var (
+	cborHandle    = &codec.CborHandle{}
+	msgPackHandle = &codec.MsgpackHandle{}
+)
+
+// CBORCodec encodes structs using their codec tag, which falls back to the json tag.
+type CBORCodec struct{}
+
+func (CBORCodec) Decode(r io.Reader, v interface{}) error {
+	return codec.NewDecoder(r, cborHandle).Decode(v)
+}
+func (CBORCodec) Encode(w io.Writer, v interface{}) error {
+	return codec.NewEncoder(w, cborHandle).Encode(v)
+}
+
+// MsgPackCodec encodes structs using their codec tag, which falls back to the json tag.
+type MsgPackCodec struct{}
+
+func (MsgPackCodec) Decode(r io.Reader, v interface{}) error {
+	return codec.NewDecoder(r, msgPackHandle).Decode(v)
+}
+func (MsgPackCodec) Encode(w io.Writer, v interface{}) error {
+	return codec.NewEncoder(w, msgPackHandle).Encode(v)
+}
+
+// FormCodec decodes and encodes application/x-www-form-urlencoded structs, see BindForm.
+type FormCodec struct{}
+
+func (FormCodec) Decode(r io.Reader, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("destination must be a pointer to a struct, got %T: %w", v, ErrFormBindingFailed)
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return fmt.Errorf("failed to parse form: %v: %w", err, ErrFormBindingFailed)
+	}
+	return bindFormValues(rv.Elem(), form, nil)
+}
+
+func (FormCodec) Encode(w io.Writer, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("form must be a struct, got %T", v)
+	}
+
+	form := url.Values{}
+	for _, field := range GetTypeInfo(rv.Type()).Fields {
+		fv, err := rv.FieldByIndexErr(field.Index)
+		if err != nil || fv.IsZero() {
+			continue
+		}
+		for fv.Kind() == reflect.Ptr {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < fv.Len(); i++ {
+				form.Add(field.FormName(), formatFormValue(fv.Index(i)))
+			}
+			continue
+		}
+		form.Set(field.FormName(), formatFormValue(fv))
+	}
+	_, err := io.WriteString(w, form.Encode())
+	return err
+}
+
+func formatFormValue(v reflect.Value) string {
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	if v.Kind() == reflect.Slice {
+		return string(v.Bytes())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// TextCodec decodes into strings, byte slices and encoding.TextUnmarshaler and encodes strings, byte
+// slices and encoding.TextMarshaler.
+type TextCodec struct{}
+
+func (TextCodec) Decode(r io.Reader, v interface{}) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *string:
+		*v = string(body)
+	case *[]byte:
+		*v = body
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText(body)
+	default:
+		return fmt.Errorf("cannot decode text into %T", v)
+	}
+	return nil
+}
+
+func (TextCodec) Encode(w io.Writer, v interface{}) error {
+	var text []byte
+	switch v := v.(type) {
+	case string:
+		text = []byte(v)
+	case *string:
+		text = []byte(*v)
+	case []byte:
+		text = v
+	case encoding.TextMarshaler:
+		var err error
+		if text, err = v.MarshalText(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as text", v)
+	}
+	_, err := w.Write(text)
+	return err
+}
+
+// BinaryCodec passes bodies through, decoding into byte slices and io.Writer and encoding byte slices
+// and io.Reader.
+type BinaryCodec struct{}
+
+func (BinaryCodec) Decode(r io.Reader, v interface{}) error {
+	switch v := v.(type) {
+	case *[]byte:
+		body, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		*v = body
+		return nil
+	case io.Writer:
+		_, err := io.Copy(v, r)
+		return err
+	}
+	return fmt.Errorf("cannot decode binary into %T", v)
+}
+
+func (BinaryCodec) Encode(w io.Writer, v interface{}) error {
+	switch v := v.(type) {
+	case []byte:
+		_, err := w.Write(v)
+		return err
+	case io.Reader:
+		_, err := io.Copy(w, v)
+		return err
+	}
+	return fmt.Errorf("cannot encode %T as binary", v)
+}
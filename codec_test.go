@@ -0,0 +1,282 @@
+package specs
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type codecTestUser struct {
+	ID    string   `json:"id" xml:"id" form:"id"`
+	Name  string   `json:"name" xml:"name" form:"name"`
+	Roles []string `json:"roles" xml:"roles" form:"roles"`
+}
+
+func TestCodecsLookup(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      Codec
+	}{
+		{mediaType: "application/json", want: JSONCodec{}},
+		{mediaType: "application/json; charset=utf-8", want: JSONCodec{}},
+		{mediaType: "Application/JSON", want: JSONCodec{}},
+		{mediaType: "application/problem+json", want: JSONCodec{}},
+		{mediaType: "application/atom+xml", want: XMLCodec{}},
+		{mediaType: "text/xml; charset=utf-8", want: XMLCodec{}},
+		{mediaType: "application/cbor", want: CBORCodec{}},
+		{mediaType: "application/x-msgpack", want: MsgPackCodec{}},
+		{mediaType: "application/x-www-form-urlencoded", want: FormCodec{}},
+		{mediaType: "text/plain", want: TextCodec{}},
+		{mediaType: "application/octet-stream", want: BinaryCodec{}},
+		{mediaType: "image/png"},
+		{mediaType: "application/problem+yaml"},
+		{mediaType: "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			got, ok := DefaultCodecs.Lookup(tt.mediaType)
+			if ok != (tt.want != nil) || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+
+	t.Run("should prefer the most specific wildcard", func(t *testing.T) {
+		c := NewCodecs()
+		c.Register("*/*", TextCodec{})
+		c.Register("application/*", XMLCodec{})
+		c.Register("application/*+json", JSONCodec{})
+		if got, _ := c.Lookup("application/vnd.api+json"); got != (JSONCodec{}) {
+			t.Errorf("got %v", got)
+		}
+		if got, _ := c.Lookup("application/octet-stream"); got != (XMLCodec{}) {
+			t.Errorf("got %v", got)
+		}
+		if got, _ := c.Lookup("image/png"); got != (TextCodec{}) {
+			t.Errorf("got %v", got)
+		}
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+	offered := []string{MediaTypeJSON, MediaTypeXML, MediaTypeCBOR}
+	tests := []struct {
+		name    string
+		accept  string
+		want    string
+		wantErr error
+	}{
+		{name: "should prefer the first offered media type without accept", accept: "", want: MediaTypeJSON},
+		{name: "should prefer the first offered media type accepting any", accept: "*/*", want: MediaTypeJSON},
+		{name: "should select the accepted media type", accept: "application/xml", want: MediaTypeXML},
+		{name: "should follow quality values", accept: "application/json;q=0.5, application/cbor", want: MediaTypeCBOR},
+		{name: "should prefer specific ranges over wildcards", accept: "application/*;q=0.8, application/json;q=0", want: MediaTypeXML},
+		{name: "should match type wildcards", accept: "text/html, application/*;q=0.9", want: MediaTypeJSON},
+		{name: "should reject unaccepted media types", accept: "text/html", wantErr: ErrNotAcceptable},
+		{name: "should reject media types of quality 0", accept: "*/*;q=0", wantErr: ErrNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Negotiate(tt.accept, offered)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchMediaType(t *testing.T) {
+	declared := []string{MediaTypeJSON, "application/*+json", MediaTypeMultipartForm}
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{contentType: "application/json; charset=utf-8", want: MediaTypeJSON},
+		{contentType: "application/merge-patch+json", want: "application/*+json"},
+		{contentType: "multipart/form-data; boundary=abc", want: MediaTypeMultipartForm},
+		{contentType: "application/xml"},
+		{contentType: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got, ok := MatchMediaType(tt.contentType, declared); got != tt.want || ok != (tt.want != "") {
+				t.Errorf("got %s, %v, want %s", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodecsRoundTrip(t *testing.T) {
+	user := codecTestUser{ID: "1", Name: "John", Roles: []string{"admin", "user"}}
+	for _, mediaType := range []string{MediaTypeJSON, MediaTypeXML, MediaTypeCBOR, MediaTypeMsgPack, MediaTypeURLEncodedForm} {
+		t.Run(mediaType, func(t *testing.T) {
+			codec, _ := DefaultCodecs.Lookup(mediaType)
+			var buf bytes.Buffer
+			if err := codec.Encode(&buf, user); err != nil {
+				t.Fatal(err)
+			}
+			var got codecTestUser
+			if err := DefaultCodecs.Decode(&buf, mediaType+"; charset=utf-8", &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, user) {
+				t.Errorf("got %+v, want %+v", got, user)
+			}
+		})
+	}
+	t.Run("text/plain", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := (TextCodec{}).Encode(&buf, "hello"); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if err := DefaultCodecs.Decode(&buf, MediaTypeText, &got); err != nil || got != "hello" {
+			t.Errorf("got %q, %v", got, err)
+		}
+		if err := (TextCodec{}).Encode(&buf, user); err == nil {
+			t.Error("got no error encoding a struct as text")
+		}
+	})
+	t.Run("application/octet-stream", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := (BinaryCodec{}).Encode(&buf, strings.NewReader("\x00\x01")); err != nil {
+			t.Fatal(err)
+		}
+		var got []byte
+		if err := DefaultCodecs.Decode(&buf, MediaTypeOctetStream, &got); err != nil || string(got) != "\x00\x01" {
+			t.Errorf("got %q, %v", got, err)
+		}
+		if err := (BinaryCodec{}).Encode(&buf, user); err == nil {
+			t.Error("got no error encoding a struct as binary")
+		}
+	})
+	t.Run("should reject unknown media types", func(t *testing.T) {
+		if err := DefaultCodecs.Decode(strings.NewReader(""), "image/png", &codecTestUser{}); !errors.Is(err, ErrUnsupportedMediaType) {
+			t.Errorf("got %v, want %v", err, ErrUnsupportedMediaType)
+		}
+	})
+}
+
+func TestWriteResponse(t *testing.T) {
+	user := codecTestUser{ID: "1", Name: "John"}
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		wantBody        string
+		wantErr         error
+	}{
+		{name: "should encode the first media type by default", wantContentType: MediaTypeJSON, wantBody: `{"id":"1","name":"John","roles":null}`},
+		{name: "should encode the accepted media type", accept: "application/xml", wantContentType: MediaTypeXML, wantBody: `<codecTestUser><id>1</id><name>John</name></codecTestUser>`},
+		{name: "should reject unaccepted media types", accept: "text/html", wantErr: ErrNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			if err := DefaultCodecs.WriteResponse(rec, req, http.StatusOK, user, MediaTypeJSON, MediaTypeXML); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("got Content-Type %s, want %s", got, tt.wantContentType)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.wantBody {
+				t.Errorf("got %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("id=1&name=John"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	var got codecTestUser
+	if err := DefaultCodecs.DecodeRequest(req, &got, DefaultMaxFormMemory); err != nil {
+		t.Fatal(err)
+	}
+	if want := (codecTestUser{ID: "1", Name: "John"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDeclareMediaTypes(t *testing.T) {
+	t.Run("should annotate every media type of a response", func(t *testing.T) {
+		r := NewRegistry[string]()
+		r.GET("/users", "").Response(200, codecTestUser{}, "User found", MediaTypeJSON, MediaTypeXML, MediaTypeCBOR)
+
+		doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+		r.Annotate(doc)
+		content := doc.Paths.Find("/users").Get.Responses.Get(200).Value.Content
+		if len(content) != 3 || content.Get(MediaTypeXML) == nil {
+			t.Errorf("got content %v", content)
+		}
+		for _, e := range r.Eject() {
+			if got := e.Response[200]; got.MediaType != MediaTypeJSON || len(got.MediaTypes) != 3 {
+				t.Errorf("got response %+v", got)
+			}
+		}
+	})
+
+	tests := []struct {
+		name     string
+		register func(r *registry[string])
+		want     error
+	}{
+		{
+			name: "should reject payloads without codec",
+			register: func(r *registry[string]) {
+				r.POST("/", "").Payload(codecTestUser{}, "application/yaml")
+			},
+			want: ErrPayloadAnnotationFailed,
+		},
+		{
+			name: "should reject responses without codec",
+			register: func(r *registry[string]) {
+				r.GET("/", "").Response(200, codecTestUser{}, "", MediaTypeJSON, "image/png")
+			},
+			want: ErrResponseAnnotationFailed,
+		},
+		{
+			name: "should check media types against the codecs of the registry",
+			register: func(r *registry[string]) {
+				r.GET("/", "").Response(200, codecTestUser{}, "", MediaTypeCBOR)
+			},
+			want: ErrResponseAnnotationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, tt.want) {
+					t.Errorf("got %v, want %v", err, tt.want)
+				}
+			}()
+			codecs := NewCodecs()
+			codecs.Register(MediaTypeJSON, JSONCodec{})
+			tt.register(NewRegistry[string](WithCodecs(codecs)))
+		})
+	}
+
+	t.Run("should only document media types without codecs", func(t *testing.T) {
+		r := NewRegistry[string]()
+		r.POST("/reports", "").Payload(codecTestUser{}, "text/csv").Response(200, codecTestUser{}, "", "application/yaml")
+
+		doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+		r.Annotate(doc)
+		operation := doc.Paths.Find("/reports").Post
+		if operation.RequestBody.Value.Content.Get("text/csv") == nil || operation.Responses.Get(200).Value.Content.Get("application/yaml") == nil {
+			t.Errorf("got operation %+v", operation)
+		}
+	})
+}
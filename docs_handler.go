@@ -0,0 +1,263 @@
+package specs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+var (
+	//go:embed docs_handler.html
+	docsHandlerHTML     string
+	docsHandlerTemplate = template.Must(template.New("docs").Parse(docsHandlerHTML))
+)
+
+type docsOptions struct {
+	basePath string
+	audience string
+	servers  openapi3.Servers
+}
+
+type DocsOption func(*docsOptions)
+
+// DocsBasePath sets the path under which the documentation is served, defaults to /docs.
+func DocsBasePath(basePath string) DocsOption {
+	return func(o *docsOptions) {
+		o.basePath = basePath
+	}
+}
+
+// DocsAudience serves the document of the named audience (see Audience) instead of all endpoints.
+func DocsAudience(name string) DocsOption {
+	return func(o *docsOptions) {
+		o.audience = name
+	}
+}
+
+// DocsServers sets the servers of the document.
+func DocsServers(servers ...*openapi3.Server) DocsOption {
+	return func(o *docsOptions) {
+		o.servers = append(o.servers, servers...)
+	}
+}
+
+type docsHandler[T interface{}] struct {
+	r       *registry[T]
+	info    *openapi3.Info
+	options docsOptions
+	assets  http.Handler
+
+	mu     sync.Mutex
+	built  bool
+	files  map[string]*docsFile
+	uiHTML []byte
+}
+
+// docsFile is a pre-rendered representation of the document.
+type docsFile struct {
+	contentType string
+	etag        string
+	gzipETag    string
+	content     []byte
+	gzipped     []byte
+}
+
+// DocsHandler serves the document of the registry as <base>/openapi.json and <base>/openapi.yaml
+// and an embedded Swagger UI at <base>/. The document is built once on the first successful request,
+// hence all endpoints must be registered before the handler serves requests.
+func DocsHandler[T interface{}](r *registry[T], info *openapi3.Info, opts ...DocsOption) http.Handler {
+	options := docsOptions{
+		basePath: "/docs",
+	}
+	for _, applyOption := range opts {
+		applyOption(&options)
+	}
+	options.basePath = strings.TrimSuffix(options.basePath, "/")
+
+	return &docsHandler[T]{
+		r:       r,
+		info:    info,
+		options: options,
+		assets:  http.StripPrefix(options.basePath+"/assets", http.FileServer(http.FS(swaggerFiles.FS))),
+	}
+}
+
+func (h *docsHandler[T]) build() error {
+	t := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    h.info,
+		Servers: h.options.servers,
+	}
+	if h.options.audience != "" {
+		if err := h.r.AnnotateAudience(h.options.audience, t); err != nil {
+			return err
+		}
+	} else {
+		h.r.Annotate(t)
+	}
+
+	content, err := MarshalCanonicalJSON(t)
+	if err != nil {
+		return err
+	}
+	yamlContent, err := MarshalCanonicalYAML(t)
+	if err != nil {
+		return err
+	}
+
+	h.files = map[string]*docsFile{}
+	for name, file := range map[string]*docsFile{
+		"/openapi.json": {contentType: "application/json", content: content},
+		"/openapi.yaml": {contentType: "application/yaml", content: yamlContent},
+	} {
+		sum := sha256.Sum256(file.content)
+		file.etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		file.gzipETag = `"` + hex.EncodeToString(sum[:16]) + `-gzip"`
+
+		var gzipped bytes.Buffer
+		gz := gzip.NewWriter(&gzipped)
+		if _, err := gz.Write(file.content); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		file.gzipped = gzipped.Bytes()
+		h.files[name] = file
+	}
+
+	var ui bytes.Buffer
+	title := "API Documentation"
+	if h.info != nil && h.info.Title != "" {
+		title = h.info.Title
+	}
+	if err := docsHandlerTemplate.Execute(&ui, map[string]string{
+		"Title":      title,
+		"AssetsPath": h.options.basePath + "/assets",
+		"SpecPath":   h.options.basePath + "/openapi.json",
+	}); err != nil {
+		return err
+	}
+	h.uiHTML = ui.Bytes()
+	return nil
+}
+
+func (h *docsHandler[T]) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, h.options.basePath)
+	if len(path) == len(req.URL.Path) && h.options.basePath != "" {
+		http.NotFound(w, req)
+		return
+	}
+
+	if path == "" {
+		http.Redirect(w, req, h.options.basePath+"/", http.StatusMovedPermanently)
+		return
+	}
+	if strings.HasPrefix(path, "/assets/") {
+		h.assets.ServeHTTP(w, req)
+		return
+	}
+
+	if err := h.ensureBuilt(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if path == "/" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(h.uiHTML)
+		return
+	}
+
+	file, ok := h.files[path]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	content, etag := file.content, file.etag
+	if acceptsGzip(req) {
+		w.Header().Set("Content-Encoding", "gzip")
+		content, etag = file.gzipped, file.gzipETag
+	}
+
+	w.Header().Set("Content-Type", file.contentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Vary", "Accept-Encoding")
+	if matchesETag(req.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+	if req.Method == http.MethodHead {
+		return
+	}
+	w.Write(content)
+}
+
+// ensureBuilt builds the document unless a previous request did, failed builds are retried.
+func (h *docsHandler[T]) ensureBuilt() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.built {
+		return nil
+	}
+	if err := h.build(); err != nil {
+		return err
+	}
+	h.built = true
+	return nil
+}
+
+// acceptsGzip reports whether the Accept-Encoding header accepts gzip with a non-zero quality value.
+func acceptsGzip(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(encoding, ";")
+		if strings.TrimSpace(params[0]) != "gzip" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				var err error
+				if q, err = strconv.ParseFloat(value, 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		return q > 0
+	}
+	return false
+}
+
+// matchesETag reports whether the If-None-Match header lists the entity tag, using the weak
+// comparison of RFC 9110.
+func matchesETag(header string, etag string) bool {
+	for _, match := range strings.Split(header, ",") {
+		match = strings.TrimSpace(match)
+		if match == "*" || strings.TrimPrefix(match, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
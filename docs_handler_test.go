@@ -0,0 +1,148 @@
+package specs
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestDocsHandler(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users/{id}", "").Response(200, struct {
+		ID string `json:"id"`
+	}{}, "User found")
+	r.GET("/admin/stats", "").Visibility(VisibilityInternal).Response(200, "", "Stats")
+	r.Audience("public", VisibilityFilter[string](VisibilityPublic))
+
+	handler := DocsHandler(r, &openapi3.Info{Title: "Test API", Version: "1.0.0"}, DocsBasePath("/api/docs/"), DocsAudience("public"))
+
+	tests := []struct {
+		name        string
+		path        string
+		header      http.Header
+		status      int
+		contentType string
+		contains    string
+	}{
+		{name: "should serve json", path: "/api/docs/openapi.json", status: 200, contentType: "application/json", contains: `"operationId": "getUsersById"`},
+		{name: "should serve yaml", path: "/api/docs/openapi.yaml", status: 200, contentType: "application/yaml", contains: "operationId: getUsersById"},
+		{name: "should quote ambiguous yaml strings", path: "/api/docs/openapi.yaml", status: 200, contains: `"200":`},
+		{name: "should serve ui", path: "/api/docs/", status: 200, contentType: "text/html; charset=utf-8", contains: "/api/docs/assets/swagger-ui-bundle.js"},
+		{name: "should serve ui assets", path: "/api/docs/assets/swagger-ui.css", status: 200, contentType: "text/css; charset=utf-8"},
+		{name: "should redirect to ui", path: "/api/docs", status: 301},
+		{name: "should not serve unknown files", path: "/api/docs/openapi.xml", status: 404},
+		{name: "should not serve outside of base path", path: "/openapi.json", status: 404},
+		{name: "should respect etag", path: "/api/docs/openapi.json", header: http.Header{"If-None-Match": {"*"}}, status: 304},
+		{name: "should not match other etags", path: "/api/docs/openapi.json", header: http.Header{"If-None-Match": {`"a", W/"b"`}}, status: 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.status)
+			}
+			if tt.contentType != "" && rec.Header().Get("Content-Type") != tt.contentType {
+				t.Errorf("got content type %s, want %s", rec.Header().Get("Content-Type"), tt.contentType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("body does not contain %s:\n%s", tt.contains, rec.Body.String())
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/docs/openapi.json", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("response is not gzipped")
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "/admin/stats") {
+		t.Errorf("internal endpoint published to the public audience")
+	}
+}
+
+func TestDocsHandler_Encoding(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", "").Response(200, "", "Users found")
+	handler := DocsHandler(r, &openapi3.Info{Title: "Test API", Version: "1.0.0"})
+
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	identity := serve(nil).Header().Get("ETag")
+	gzipped := serve(http.Header{"Accept-Encoding": {"gzip"}}).Header().Get("ETag")
+	if identity == gzipped {
+		t.Fatalf("gzip and identity share the etag %s", identity)
+	}
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		status   int
+		encoding string
+	}{
+		{name: "should gzip with quality value", header: http.Header{"Accept-Encoding": {"deflate, gzip;q=0.5"}}, status: 200, encoding: "gzip"},
+		{name: "should not gzip with zero quality value", header: http.Header{"Accept-Encoding": {"gzip;q=0.0"}}, status: 200},
+		{name: "should not gzip with invalid quality value", header: http.Header{"Accept-Encoding": {"gzip;q=high"}}, status: 200},
+		{name: "should match etag lists", header: http.Header{"If-None-Match": {`"a", ` + identity}}, status: 304},
+		{name: "should match weak etags", header: http.Header{"If-None-Match": {"W/" + identity}}, status: 304},
+		{name: "should match gzip etag", header: http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {gzipped}}, status: 304, encoding: "gzip"},
+		{name: "should not match identity etag of gzip", header: http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {identity}}, status: 200, encoding: "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.header)
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("got encoding %q, want %q", got, tt.encoding)
+			}
+		})
+	}
+}
+
+func TestDocsHandler_RetryBuild(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", "").Response(200, "", "Users found")
+	handler := DocsHandler(r, &openapi3.Info{Title: "Test API", Version: "1.0.0"}, DocsAudience("public"))
+
+	serve := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+		return rec.Code
+	}
+	if status := serve(); status != http.StatusInternalServerError {
+		t.Fatalf("got status %d for an unknown audience, want %d", status, http.StatusInternalServerError)
+	}
+	r.Audience("public", VisibilityFilter[string](VisibilityPublic))
+	if status := serve(); status != http.StatusOK {
+		t.Errorf("got status %d after declaring the audience, want %d", status, http.StatusOK)
+	}
+}
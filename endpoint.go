@@ -1,9 +1,18 @@
 package specs
 
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
 type Response struct {
 	Description string
-	MediaType   string
-	Value       interface{}
+	// MediaType is the first of MediaTypes, the media types the response can be negotiated as
+	MediaType  string
+	MediaTypes []string
+	Value      interface{}
+
+	// Events are the events of a streamed response, declared using Builder.Stream
+	Events []StreamEvent
 }
 
 type Body struct {
@@ -17,9 +26,13 @@ type Endpoint[T interface{}] struct {
 	Description string
 
 	Deprecated bool
+	Visibility string
 	Tags       []string
+	Groups     []string
+	Security   openapi3.SecurityRequirements
 
-	Handler T
+	Handler    T
+	Middleware []T
 
 	Protocol string
 	Method   string
@@ -31,4 +44,25 @@ type Endpoint[T interface{}] struct {
 
 	Payload  []Body
 	Response map[int]Response
+
+	// Pagination is the style of a paginated endpoint, declared using Builder.Paginated
+	Pagination PaginationStyle
+
+	// Inbound and Outbound are the messages received and sent by a channel (see Protocol)
+	Inbound  []Message
+	Outbound []Message
+
+	// Callbacks are the requests sent to urls taken from the request, declared using Builder.Callback
+	Callbacks []Callback
+
+	// Source is the location of the registration, used to report route conflicts
+	Source Source
+}
+
+// mediaTypes returns the media types of the response, which defaults to MediaType.
+func (r Response) mediaTypes() []string {
+	if len(r.MediaTypes) > 0 {
+		return r.MediaTypes
+	}
+	return []string{r.MediaType}
 }
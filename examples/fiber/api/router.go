@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-playground/validator/v10"
@@ -8,6 +9,7 @@ import (
 	"github.com/jakoblorz/specs"
 	"github.com/mitchellh/mapstructure"
 	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
 )
@@ -52,6 +54,33 @@ func resolvePayload[T interface{}](c *fiber.Ctx) *T {
 	return val.(*T)
 }
 
+func decorateResponses(c *fiber.Ctx, responses map[int]specs.Response) {
+	ctx := c.UserContext()
+	c.SetUserContext(context.WithValue(ctx, "responses", responses))
+}
+
+// respond writes data with status, encoded in the media type of the declared response preferred by
+// the Accept header of the request.
+func respond(c *fiber.Ctx, status int, data interface{}) error {
+	var mediaTypes []string
+	if val := c.UserContext().Value("responses"); val != nil {
+		mediaTypes = val.(map[int]specs.Response)[status].MediaTypes
+	}
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{specs.MediaTypeJSON}
+	}
+
+	mediaType, err := specs.Negotiate(c.Get(fiber.HeaderAccept), mediaTypes)
+	if err != nil {
+		return c.Status(http.StatusNotAcceptable).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	codec, _ := specs.DefaultCodecs.Lookup(mediaType)
+	c.Status(status).Set(fiber.HeaderContentType, mediaType)
+	return codec.Encode(c.Response().BodyWriter(), data)
+}
+
 func decorateParams(c *fiber.Ctx, param interface{}) {
 	ctx := c.UserContext()
 	c.SetUserContext(context.WithValue(ctx, "params", param))
@@ -66,10 +95,14 @@ func resolveParams[T interface{}](c *fiber.Ctx) *T {
 }
 
 var (
-	router = specs.NewRegistry[fiber.Handler]()
+	router = specs.NewRegistry[fiber.Handler](specs.WithCodecs(specs.DefaultCodecs))
 )
 
 func Mount(app *fiber.App) {
+	if err := router.Check(); err != nil {
+		panic(err)
+	}
+
 	validate := validator.New()
 
 	for _, endpointPtr := range router.Eject() {
@@ -112,8 +145,12 @@ func Mount(app *fiber.App) {
 			decodeQuery = func(c *fiber.Ctx) bool {
 				queryStruct := safePtrClone(endpoint.Query)
 
-				if err := c.QueryParser(queryStruct); err != nil {
-					c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+				if err == nil {
+					err = specs.BindQuery(query, queryStruct)
+				}
+				if err != nil {
+					c.Status(http.StatusBadRequest).JSON(fiber.Map{
 						"message": err.Error(),
 					})
 					return false
@@ -133,11 +170,31 @@ func Mount(app *fiber.App) {
 
 		decodePayload := func(c *fiber.Ctx) bool { return true }
 		if endpoint.Payload != nil && endpoint.Method != http.MethodGet {
+			bodyForMediaType := make(map[string]interface{})
+			mediaTypes := make([]string, 0, len(endpoint.Payload))
+			for _, body := range endpoint.Payload {
+				bodyForMediaType[body.MediaType] = body.Value
+				mediaTypes = append(mediaTypes, body.MediaType)
+			}
+
 			decodePayload = func(c *fiber.Ctx) bool {
-				payloadStruct := safePtrClone(endpoint.Payload)
+				mediaType, ok := specs.MatchMediaType(c.Get(fiber.HeaderContentType), mediaTypes)
+				if !ok {
+					c.Status(http.StatusUnsupportedMediaType).JSON(fiber.Map{
+						"message": "unsupported media type",
+					})
+					return false
+				}
 
-				if err := c.BodyParser(payloadStruct); err != nil {
-					c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				payloadStruct := safePtrClone(bodyForMediaType[mediaType])
+				var err error
+				if mediaType == specs.MediaTypeMultipartForm {
+					err = c.BodyParser(payloadStruct)
+				} else {
+					err = specs.DefaultCodecs.Decode(bytes.NewReader(c.Body()), c.Get(fiber.HeaderContentType), payloadStruct)
+				}
+				if err != nil {
+					c.Status(http.StatusBadRequest).JSON(fiber.Map{
 						"message": err.Error(),
 					})
 					return false
@@ -155,7 +212,8 @@ func Mount(app *fiber.App) {
 			}
 		}
 
-		app.Add(endpoint.Method, URLParamsRegex.ReplaceAllString(endpoint.Path, ":$1"), func(c *fiber.Ctx) error {
+		handlers := append([]fiber.Handler{}, endpoint.Middleware...)
+		handlers = append(handlers, func(c *fiber.Ctx) error {
 			if !decodeParameters(c) {
 				return nil
 			}
@@ -165,6 +223,7 @@ func Mount(app *fiber.App) {
 			if !decodePayload(c) {
 				return nil
 			}
+			decorateResponses(c, endpoint.Response)
 
 			if err := endpoint.Handler(c); err != nil {
 				c.Status(http.StatusInternalServerError).JSON(fiber.Map{
@@ -173,9 +232,15 @@ func Mount(app *fiber.App) {
 			}
 			return nil
 		})
+
+		app.Add(endpoint.Method, URLParamsRegex.ReplaceAllString(endpoint.Path, ":$1"), handlers...)
 	}
 }
 
 func Annotate(t *openapi3.T) {
 	router.Annotate(t)
 }
+
+func DocsHandler(info *openapi3.Info, opts ...specs.DocsOption) http.Handler {
+	return specs.DocsHandler(router, info, opts...)
+}
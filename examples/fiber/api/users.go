@@ -1,15 +1,21 @@
 package api
 
 import (
+	"encoding/xml"
 	"github.com/gofiber/fiber/v2"
+	"github.com/jakoblorz/specs"
 	"net/http"
+	"net/url"
+)
+
+var (
+	users = router.Group("/api/users", specs.GroupName("Users"), specs.GroupTags("api", "users"))
 )
 
 func init() {
-	router.POST("/api/users", Handle_CreateNewUserRequest).
+	users.POST("", Handle_CreateNewUserRequest).
 		Title("Creates a new user").
 		Description("Creates a new user").
-		Tags("api", "users").
 		Payload(CreateNewUserRequest{}).
 		Response(201, CreateNewUserResponse{}, "User created")
 }
@@ -28,41 +34,47 @@ type CreateNewUserResponse struct {
 func Handle_CreateNewUserRequest(c *fiber.Ctx) error {
 	payload := resolvePayload[CreateNewUserRequest](c)
 
-	c.Status(http.StatusCreated).JSON(CreateNewUserResponse{
+	return respond(c, http.StatusCreated, CreateNewUserResponse{
 		ID:   "abc",
 		Name: payload.Name,
 		Age:  payload.Age,
 	})
-	return nil
 }
 
 func init() {
-	router.GET("/api/users", Handle_GetUsersRequest).
+	users.GET("", Handle_GetUsersRequest).
 		Title("Get all users").
 		Description("Get all users").
-		Tags("api", "users").
-		Query(GetUsersQueryParameters{}).
-		Response(200, GetUsersResponse{}, "Users found")
-}
-
-type GetUsersQueryParameters struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
+		Query(GetUsersQuery{}).
+		Paginated(specs.Paginate[GetUserResponse](specs.OffsetPagination), "Users found")
 }
 
-type GetUsersResponse struct {
-	Total int               `json:"total"`
-	Docs  []GetUserResponse `json:"docs"`
+type GetUsersQuery struct {
+	specs.OffsetParams
+	Sort   specs.Sort    `json:"sort" sortable:"name"`
+	Filter specs.Filters `json:"filter"`
+	Name   string        `json:"name" filterable:"eq,in"`
 }
 
 func Handle_GetUsersRequest(c *fiber.Ctx) error {
-	query := resolveQuery[GetUsersQueryParameters](c)
-
-	c.Status(http.StatusOK).JSON(GetUsersResponse{
-		Total: query.Limit,
-		Docs:  []GetUserResponse{},
-	})
-	return nil
+	u, err := url.Parse(c.OriginalURL())
+	if err != nil {
+		c.Status(http.StatusBadRequest).JSON(err.Error())
+		return nil
+	}
+	page, err := specs.ParsePageRequest(specs.OffsetPagination, u.Query())
+	if err != nil {
+		c.Status(http.StatusBadRequest).JSON(err.Error())
+		return nil
+	}
+
+	docs := []GetUserResponse{}
+	header := http.Header{}
+	page.SetHeaders(header, u, len(docs), "")
+	for name := range header {
+		c.Set(name, header.Get(name))
+	}
+	return respond(c, http.StatusOK, specs.NewPage(docs, len(docs)))
 }
 
 type DetailedURLParameters struct {
@@ -70,37 +82,34 @@ type DetailedURLParameters struct {
 }
 
 func init() {
-	router.GET("/api/users/{id}", Handle_GetUserRequest).
+	users.GET("/{id}", Handle_GetUserRequest).
 		Title("Get a User").
 		Description("Get a user").
-		Tags("api", "users").
 		Parameters(DetailedURLParameters{}).
-		Response(http.StatusOK, GetUserResponse{}, "User found")
+		Response(http.StatusOK, GetUserResponse{}, "User found", specs.MediaTypeJSON, specs.MediaTypeXML)
 }
 
 type GetUserResponse struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Age  int    `json:"age"`
+	XMLName xml.Name `json:"-" xml:"user"`
+	ID      string   `json:"id" xml:"id,attr"`
+	Name    string   `json:"name" xml:"name"`
+	Age     int      `json:"age" xml:"age"`
 }
 
 func Handle_GetUserRequest(c *fiber.Ctx) error {
 	params := resolveParams[DetailedURLParameters](c)
 
-	c.Status(http.StatusOK).JSON(GetUserResponse{
+	return respond(c, http.StatusOK, GetUserResponse{
 		ID:   params.UserID,
 		Name: "John Doe",
 		Age:  18,
 	})
-
-	return nil
 }
 
 func init() {
-	router.PUT("/api/users/{id}", Handle_UpdateUserRequest).
+	users.PUT("/{id}", Handle_UpdateUserRequest).
 		Title("Update a User").
 		Description("Update a user").
-		Tags("api", "users").
 		Parameters(DetailedURLParameters{}).
 		Payload(UpdateUserRequest{}).
 		Response(200, UpdateUserResponse{}, "User updated")
@@ -121,11 +130,9 @@ func Handle_UpdateUserRequest(c *fiber.Ctx) error {
 	params := resolveParams[DetailedURLParameters](c)
 	payload := resolvePayload[UpdateUserRequest](c)
 
-	c.Status(http.StatusOK).JSON(UpdateUserResponse{
+	return respond(c, http.StatusOK, UpdateUserResponse{
 		ID:   params.UserID,
 		Name: payload.Name,
 		Age:  payload.Age,
 	})
-
-	return nil
 }
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"reflect"
 	"regexp"
@@ -13,6 +15,10 @@ import (
 )
 
 var (
+	// MaxFormMemory is the number of bytes of multipart payloads kept in memory, the remaining
+	// files are stored in temporary files.
+	MaxFormMemory int64 = specs.DefaultMaxFormMemory
+
 	// URLParamsRegex is a regular expression to match URL parameters in the openapi spec format (e.g. {id}).
 	// It allows to replace the parameters with the gin-gonic format (e.g. :id).
 	URLParamsRegex = regexp.MustCompile(`\{([a-zA-Z0-9]+)\}`)
@@ -62,11 +68,35 @@ func resolvePayload(c *gin.Context, body interface{}) {
 	c.Set("payload", body)
 }
 
+// Respond writes data with status, encoded in the media type of the declared response preferred by
+// the Accept header of the request.
+func Respond(c *gin.Context, status int, data interface{}) {
+	var mediaTypes []string
+	if val, ok := c.Get("responses"); ok {
+		mediaTypes = val.(map[int]specs.Response)[status].MediaTypes
+	}
+	if err := specs.DefaultCodecs.WriteResponse(c.Writer, c.Request, status, data, mediaTypes...); err != nil {
+		if errors.Is(err, specs.ErrNotAcceptable) {
+			c.JSON(http.StatusNotAcceptable, err.Error())
+			return
+		}
+		c.Error(err)
+	}
+}
+
+func resolveResponses(c *gin.Context, responses map[int]specs.Response) {
+	c.Set("responses", responses)
+}
+
 var (
-	router = specs.NewRegistry[gin.HandlerFunc]()
+	router = specs.NewRegistry[gin.HandlerFunc](specs.OperationIDGenerator(specs.MethodPathOperationIDGenerator), specs.WithCodecs(specs.DefaultCodecs))
 )
 
 func Mount(r *gin.Engine) {
+	if err := router.Check(); err != nil {
+		panic(err)
+	}
+
 	validate := validator.New()
 
 	for _, endpointPtr := range router.Eject() {
@@ -108,7 +138,7 @@ func Mount(r *gin.Engine) {
 			decodeQuery = func(c *gin.Context) bool {
 				queryStruct := safePtrClone(endpoint.Query)
 
-				if err := c.ShouldBindQuery(queryStruct); err != nil {
+				if err := specs.BindQuery(c.Request.URL.Query(), queryStruct); err != nil {
 					c.JSON(http.StatusBadRequest, err.Error())
 					return false
 				}
@@ -126,19 +156,21 @@ func Mount(r *gin.Engine) {
 		if endpoint.Payload != nil && endpoint.Method != http.MethodGet {
 
 			bodyForMediaType := make(map[string]interface{})
+			mediaTypes := make([]string, 0, len(endpoint.Payload))
 			for _, body := range endpoint.Payload {
 				bodyForMediaType[body.MediaType] = body.Value
+				mediaTypes = append(mediaTypes, body.MediaType)
 			}
 
 			decodePayload = func(c *gin.Context) bool {
-				bodyAnnotation, ok := bodyForMediaType[c.ContentType()]
+				mediaType, ok := specs.MatchMediaType(c.GetHeader("Content-Type"), mediaTypes)
 				if !ok {
 					c.JSON(http.StatusUnsupportedMediaType, "unsupported media type")
 					return false
 				}
 
-				bodyStruct := safePtrClone(bodyAnnotation)
-				if err := c.ShouldBindJSON(bodyStruct); err != nil {
+				bodyStruct := safePtrClone(bodyForMediaType[mediaType])
+				if err := specs.DefaultCodecs.DecodeRequest(c.Request, bodyStruct, MaxFormMemory); err != nil {
 					c.JSON(http.StatusBadRequest, err.Error())
 					return false
 				}
@@ -152,7 +184,8 @@ func Mount(r *gin.Engine) {
 			}
 		}
 
-		r.Handle(endpoint.Method, URLParamsRegex.ReplaceAllString(endpoint.Path, ":$1"), func(c *gin.Context) {
+		handlers := append([]gin.HandlerFunc{}, endpoint.Middleware...)
+		handlers = append(handlers, func(c *gin.Context) {
 			if !decodeParameters(c) {
 				return
 			}
@@ -162,12 +195,23 @@ func Mount(r *gin.Engine) {
 			if !decodePayload(c) {
 				return
 			}
+			resolveResponses(c, endpoint.Response)
 
 			endpoint.Handler(c)
 		})
+
+		r.Handle(endpoint.Method, URLParamsRegex.ReplaceAllString(endpoint.Path, ":$1"), handlers...)
 	}
 }
 
 func Annotate(t *openapi3.T) {
 	router.Annotate(t)
 }
+
+func DocsHandler(info *openapi3.Info, opts ...specs.DocsOption) http.Handler {
+	return specs.DocsHandler(router, info, opts...)
+}
+
+func GenerateGoClient(w io.Writer) error {
+	return router.GenerateGoClient(w)
+}
@@ -1,14 +1,21 @@
 package api
 
 import (
+	"encoding/xml"
+	"mime/multipart"
+
 	"github.com/gin-gonic/gin"
+	"github.com/jakoblorz/specs"
+)
+
+var (
+	users = router.Group("/api/users", specs.GroupName("Users"), specs.GroupTags("api", "users"))
 )
 
 func init() {
-	router.POST("/api/users", Handle_CreateNewUserRequest).
+	users.POST("", Handle_CreateNewUserRequest).
 		Title("Creates a new user").
 		Description("Creates a new user").
-		Tags("api", "users").
 		Payload(CreateNewUserRequest{}).
 		Response(201, CreateNewUserResponse{}, "User created")
 }
@@ -28,7 +35,7 @@ func Handle_CreateNewUserRequest(c *gin.Context) {
 		payload = GetPayload[CreateNewUserRequest](c)
 	)
 
-	c.JSON(201, CreateNewUserResponse{
+	Respond(c, 201, CreateNewUserResponse{
 		ID:   "abc",
 		Name: payload.Name,
 		Nick: payload.Name + "nick",
@@ -36,33 +43,30 @@ func Handle_CreateNewUserRequest(c *gin.Context) {
 }
 
 func init() {
-	router.GET("/api/users", Handle_GetUsersRequest).
+	users.GET("", Handle_GetUsersRequest).
 		Title("Get all users").
 		Description("Get all users").
-		Tags("api", "users").
-		Query(GetUsersQueryParameters{}).
-		Response(200, GetUsersResponse{}, "Users found")
+		Query(GetUsersQuery{}).
+		Paginated(specs.Paginate[GetUserResponse](specs.OffsetPagination), "Users found")
 }
 
-type GetUsersQueryParameters struct {
-	Offset int `json:"offset"`
-	Limit  int `json:"limit"`
-}
-
-type GetUsersResponse struct {
-	Total int               `json:"total"`
-	Docs  []GetUserResponse `json:"items"`
+type GetUsersQuery struct {
+	specs.OffsetParams
+	Sort   specs.Sort    `json:"sort" sortable:"name"`
+	Filter specs.Filters `json:"filter"`
+	Name   string        `json:"name" filterable:"eq,in"`
 }
 
 func Handle_GetUsersRequest(c *gin.Context) {
-	var (
-		query = GetQuery[GetUsersQueryParameters](c)
-	)
+	page, err := specs.ParsePageRequest(specs.OffsetPagination, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(400, err.Error())
+		return
+	}
 
-	c.JSON(200, GetUsersResponse{
-		Total: query.Limit,
-		Docs:  []GetUserResponse{},
-	})
+	docs := []GetUserResponse{}
+	page.SetHeaders(c.Writer.Header(), c.Request.URL, len(docs), "")
+	Respond(c, 200, specs.NewPage(docs, len(docs)))
 }
 
 type DetailedURLParameters struct {
@@ -70,18 +74,18 @@ type DetailedURLParameters struct {
 }
 
 func init() {
-	router.GET("/api/users/{id}", Handle_GetUserRequest).
+	users.GET("/{id}", Handle_GetUserRequest).
 		Title("Get a User").
 		Description("Get a user").
-		Tags("api", "users").
 		Parameters(DetailedURLParameters{}).
-		Response(200, GetUserResponse{}, "User found")
+		Response(200, GetUserResponse{}, "User found", specs.MediaTypeJSON, specs.MediaTypeXML)
 }
 
 type GetUserResponse struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-	Nick string `json:"nick"`
+	XMLName xml.Name `json:"-" xml:"user"`
+	ID      string   `json:"id" xml:"id,attr"`
+	Name    string   `json:"name" xml:"name"`
+	Nick    string   `json:"nick" xml:"nick,omitempty"`
 }
 
 func Handle_GetUserRequest(c *gin.Context) {
@@ -89,7 +93,7 @@ func Handle_GetUserRequest(c *gin.Context) {
 		params = GetParams[DetailedURLParameters](c)
 	)
 
-	c.JSON(200, GetUserResponse{
+	Respond(c, 200, GetUserResponse{
 		ID:   params.UserID,
 		Name: "John Doe",
 		Nick: "John Doe" + "nick",
@@ -97,10 +101,9 @@ func Handle_GetUserRequest(c *gin.Context) {
 }
 
 func init() {
-	router.PUT("/api/users/{id}", Handle_UpdateUserRequest).
+	users.PUT("/{id}", Handle_UpdateUserRequest).
 		Title("Update a User").
 		Description("Update a user").
-		Tags("api", "users").
 		Parameters(DetailedURLParameters{}).
 		Payload(UpdateUserRequest{}).
 		Response(200, UpdateUserResponse{}, "User updated")
@@ -122,9 +125,42 @@ func Handle_UpdateUserRequest(c *gin.Context) {
 		payload = GetPayload[UpdateUserRequest](c)
 	)
 
-	c.JSON(200, UpdateUserResponse{
+	Respond(c, 200, UpdateUserResponse{
 		ID:   params.UserID,
 		Name: payload.Name,
 		Nick: payload.Name + "nick",
 	})
 }
+
+func init() {
+	users.PUT("/{id}/avatar", Handle_UploadUserAvatarRequest).
+		Title("Upload a User Avatar").
+		Description("Upload the avatar of a user").
+		Parameters(DetailedURLParameters{}).
+		Payload(UploadUserAvatarRequest{}, specs.MediaTypeMultipartForm).
+		Response(200, UploadUserAvatarResponse{}, "Avatar uploaded")
+}
+
+type UploadUserAvatarRequest struct {
+	Avatar *multipart.FileHeader `form:"avatar" contentType:"image/png, image/jpeg" validate:"required"`
+	Alt    string                `form:"alt"`
+}
+
+type UploadUserAvatarResponse struct {
+	ID   string `json:"id"`
+	Size int64  `json:"size"`
+	Alt  string `json:"alt"`
+}
+
+func Handle_UploadUserAvatarRequest(c *gin.Context) {
+	var (
+		params  = GetParams[DetailedURLParameters](c)
+		payload = GetPayload[UploadUserAvatarRequest](c)
+	)
+
+	Respond(c, 200, UploadUserAvatarResponse{
+		ID:   params.UserID,
+		Size: payload.Avatar.Size,
+		Alt:  payload.Alt,
+	})
+}
@@ -0,0 +1,341 @@
+// Code generated by github.com/jakoblorz/specs. DO NOT EDIT.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jakoblorz/specs"
+	"github.com/jakoblorz/specs/examples/gin-gonic/api"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Doer executes http requests. *http.Client implements Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer, e.g. to add authentication or tracing.
+type Middleware func(next Doer) Doer
+
+type Option func(*Client)
+
+// WithHTTPClient sets the client used to execute requests. Defaults to http.DefaultClient.
+func WithHTTPClient(doer Doer) Option {
+	return func(c *Client) {
+		c.doer = doer
+	}
+}
+
+// WithMiddleware wraps the http client with the middlewares, the first one being the outermost.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+type Client struct {
+	baseURL     string
+	doer        Doer
+	middlewares []Middleware
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		doer:    http.DefaultClient,
+	}
+	for _, applyOption := range opts {
+		applyOption(c)
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		c.doer = c.middlewares[i](c.doer)
+	}
+	return c
+}
+
+// Error is returned when the server responds with a status code that was not declared.
+type Error struct {
+	Code int
+	Body []byte
+}
+
+func (e *Error) StatusCode() int { return e.Code }
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.Code, e.Body)
+}
+
+func newError(res *http.Response) error {
+	body, _ := io.ReadAll(res.Body)
+	return &Error{Code: res.StatusCode, Body: body}
+}
+
+func (c *Client) newRequest(ctx context.Context, method string, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return http.NewRequestWithContext(ctx, method, u, body)
+}
+
+// addQueryValue adds v to the query unless it is nil. Zero values are only omitted if the field is
+// tagged omitempty, optional values without omitempty are declared as pointers.
+func addQueryValue(query url.Values, name string, v interface{}, omitEmpty bool) {
+	if encoder, ok := v.(interface{ EncodeQuery(string, url.Values) }); ok {
+		encoder.EncodeQuery(name, query)
+		return
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || omitEmpty && rv.IsZero() {
+		return
+	}
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			query.Add(name, fmt.Sprint(rv.Index(i).Interface()))
+		}
+		return
+	}
+	query.Set(name, fmt.Sprint(rv.Interface()))
+}
+
+// writeMultipartForm writes the values and files of a multipart payload and closes the writer.
+func writeMultipartForm(mw *multipart.Writer, form url.Values, files map[string][]*multipart.FileHeader) error {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range form[name] {
+			if err := mw.WriteField(name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	names = names[:0]
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, fh := range files[name] {
+			if fh == nil {
+				continue
+			}
+			if err := writeMultipartFile(mw, name, fh); err != nil {
+				return err
+			}
+		}
+	}
+	return mw.Close()
+}
+
+func writeMultipartFile(mw *multipart.Writer, name string, fh *multipart.FileHeader) error {
+	f, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	part, err := mw.CreateFormFile(name, fh.Filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, f)
+	return err
+}
+
+// GetApiUsers calls GET /api/users.
+//
+// Get all users
+func (c *Client) GetApiUsers(ctx context.Context, query api.GetUsersQuery) (*specs.Page[api.GetUserResponse], error) {
+	path := "/api" + "/users"
+	values := url.Values{}
+	addQueryValue(values, "filter", query.Filter, false)
+	addQueryValue(values, "limit", query.OffsetParams.Limit, true)
+	addQueryValue(values, "offset", query.OffsetParams.Offset, true)
+	addQueryValue(values, "sort", query.Sort, false)
+	var body io.Reader
+	req, err := c.newRequest(ctx, "GET", path, values, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		out := new(specs.Page[api.GetUserResponse])
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	return nil, newError(res)
+}
+
+// PostApiUsers calls POST /api/users.
+//
+// Creates a new user
+func (c *Client) PostApiUsers(ctx context.Context, payload api.CreateNewUserRequest) (*api.CreateNewUserResponse, error) {
+	path := "/api" + "/users"
+	values := url.Values{}
+	var body io.Reader
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.NewReader(b)
+	req, err := c.newRequest(ctx, "POST", path, values, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	res, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 201:
+		out := new(api.CreateNewUserResponse)
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	return nil, newError(res)
+}
+
+// GetApiUsersById calls GET /api/users/{id}.
+//
+// Get a User
+func (c *Client) GetApiUsersById(ctx context.Context, params api.DetailedURLParameters) (*api.GetUserResponse, error) {
+	path := "/api" + "/users" + "/" + url.PathEscape(fmt.Sprint(params.UserID))
+	values := url.Values{}
+	var body io.Reader
+	req, err := c.newRequest(ctx, "GET", path, values, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		out := new(api.GetUserResponse)
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	return nil, newError(res)
+}
+
+// PutApiUsersById calls PUT /api/users/{id}.
+//
+// Update a User
+func (c *Client) PutApiUsersById(ctx context.Context, params api.DetailedURLParameters, payload api.UpdateUserRequest) (*api.UpdateUserResponse, error) {
+	path := "/api" + "/users" + "/" + url.PathEscape(fmt.Sprint(params.UserID))
+	values := url.Values{}
+	var body io.Reader
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.NewReader(b)
+	req, err := c.newRequest(ctx, "PUT", path, values, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	res, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		out := new(api.UpdateUserResponse)
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	return nil, newError(res)
+}
+
+// PutApiUsersByIdAvatar calls PUT /api/users/{id}/avatar.
+//
+// Upload a User Avatar
+func (c *Client) PutApiUsersByIdAvatar(ctx context.Context, params api.DetailedURLParameters, payload api.UploadUserAvatarRequest) (*api.UploadUserAvatarResponse, error) {
+	path := "/api" + "/users" + "/" + url.PathEscape(fmt.Sprint(params.UserID)) + "/avatar"
+	values := url.Values{}
+	var body io.Reader
+	form := url.Values{}
+	addQueryValue(form, "alt", payload.Alt, false)
+	files := map[string][]*multipart.FileHeader{}
+	files["avatar"] = append(files["avatar"], payload.Avatar)
+	b := new(bytes.Buffer)
+	mw := multipart.NewWriter(b)
+	if err := writeMultipartForm(mw, form, files); err != nil {
+		return nil, err
+	}
+	body = b
+	req, err := c.newRequest(ctx, "PUT", path, values, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	res, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		out := new(api.UploadUserAvatarResponse)
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	return nil, newError(res)
+}
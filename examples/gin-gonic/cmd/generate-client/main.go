@@ -0,0 +1,23 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"github.com/jakoblorz/specs/examples/gin-gonic/api"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: %s <output file>", os.Args[0])
+	}
+
+	b := new(bytes.Buffer)
+	if err := api.GenerateGoClient(b); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(os.Args[1], b.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jakoblorz/specs"
+	"github.com/jakoblorz/specs/examples/gin-gonic/api"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: %s <output file>", os.Args[0])
+	}
+
+	t := new(openapi3.T)
+	t.OpenAPI = "3.0.3"
+	t.Info = &openapi3.Info{
+		Title:   "Example API",
+		Version: "1.0.0",
+	}
+	api.Annotate(t)
+
+	if err := specs.WriteSpecFile(os.Args[1], t); err != nil {
+		log.Fatal(err)
+	}
+}
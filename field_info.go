@@ -2,6 +2,7 @@ package specs
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -90,6 +91,128 @@ func (inFieldInfo *fieldInfo_JSON) Resolve(f reflect.StructField) (name string,
 	return
 }
 
+type fieldInfo_Form struct {
+	Form_Name        string
+	Form_ContentType string
+}
+
+func (inFieldInfo *fieldInfo_Form) Resolve(f reflect.StructField) (name string, fieldInfo *fieldInfo_Form) {
+	formTag := f.Tag.Get("form")
+	if formTag == "-" {
+		return
+	}
+	contentType := f.Tag.Get("contentType")
+	if formTag == "" && contentType == "" {
+		return
+	}
+
+	fieldInfo = inFieldInfo
+	fieldInfo.Form_ContentType = contentType
+	if name = strings.Split(formTag, ",")[0]; name != "" {
+		fieldInfo.Form_Name = name
+	}
+
+	return
+}
+
+type fieldInfo_Proto struct {
+	// Proto_Number is the field number of the proto tag, it is 0 if the tag is not a valid number
+	Proto_Number int
+}
+
+func (inFieldInfo *fieldInfo_Proto) Resolve(f reflect.StructField) (name string, fieldInfo *fieldInfo_Proto) {
+	protoTag, ok := f.Tag.Lookup("proto")
+	if !ok {
+		return
+	}
+
+	fieldInfo = inFieldInfo
+	if number, err := strconv.Atoi(strings.Split(protoTag, ",")[0]); err == nil && number > 0 {
+		fieldInfo.Proto_Number = number
+	}
+
+	return
+}
+
+type fieldInfo_Query struct {
+	Query_Sortable   []string
+	Query_Filterable []FilterOperator
+}
+
+func (inFieldInfo *fieldInfo_Query) Resolve(f reflect.StructField) (name string, fieldInfo *fieldInfo_Query) {
+	sortableTag, sortable := f.Tag.Lookup("sortable")
+	filterableTag, filterable := f.Tag.Lookup("filterable")
+	if !sortable && !filterable {
+		return
+	}
+
+	fieldInfo = inFieldInfo
+	for _, part := range strings.Split(sortableTag, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			fieldInfo.Query_Sortable = append(fieldInfo.Query_Sortable, part)
+		}
+	}
+	for _, part := range strings.Split(filterableTag, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			fieldInfo.Query_Filterable = append(fieldInfo.Query_Filterable, FilterOperator(part))
+		}
+	}
+
+	return
+}
+
+type fieldInfo_XML struct {
+	// XML_Name is the name of the element or attribute, fields without xml tag are named by their Go name
+	XML_Name string
+	// XML_Parents are the elements enclosing the element of a field tagged a>b>c, i.e. a and b
+	XML_Parents   []string
+	XML_Attr      bool
+	XML_CharData  bool
+	XML_InnerXML  bool
+	XML_Comment   bool
+	XML_OmitEmpty bool
+	XML_Omit      bool
+}
+
+func (inFieldInfo *fieldInfo_XML) Resolve(f reflect.StructField) (name string, fieldInfo *fieldInfo_XML) {
+	fieldInfo = inFieldInfo
+	xmlTag := f.Tag.Get("xml")
+	if xmlTag == "-" {
+		fieldInfo.XML_Omit = true
+		return
+	}
+
+	parts := strings.Split(xmlTag, ",")
+	if name = parts[0]; name == "" {
+		name = f.Name
+	}
+	// the namespace of "namespace-URL name" is not documented
+	if i := strings.LastIndexByte(name, ' '); i != -1 {
+		name = name[i+1:]
+	}
+	if path := strings.Split(name, ">"); len(path) > 1 {
+		fieldInfo.XML_Parents, name = path[:len(path)-1], path[len(path)-1]
+	}
+	fieldInfo.XML_Name = name
+
+	for _, part := range parts[1:] {
+		switch part {
+		case "attr":
+			fieldInfo.XML_Attr = true
+		case "chardata":
+			fieldInfo.XML_CharData = true
+		case "innerxml":
+			fieldInfo.XML_InnerXML = true
+		case "comment":
+			fieldInfo.XML_Comment = true
+		case "omitempty":
+			fieldInfo.XML_OmitEmpty = true
+		}
+	}
+
+	return
+}
+
 type Field struct {
 	Name  string
 	Type  reflect.Type
@@ -97,7 +220,24 @@ type Field struct {
 
 	*fieldInfo_JSON
 	*fieldInfo_BSON
+	*fieldInfo_Form
+	*fieldInfo_Proto
+	*fieldInfo_Query
 	*fieldInfo_Validator
+	*fieldInfo_XML
+}
+
+// JSONOmitEmpty reports whether encoding/json omits the field if it is empty.
+func (f Field) JSONOmitEmpty() bool {
+	return f.fieldInfo_JSON != nil && f.JSON_OmitEmpty
+}
+
+// FormName returns the name of the field in form payloads, which defaults to the json name.
+func (f Field) FormName() string {
+	if f.fieldInfo_Form != nil && f.Form_Name != "" {
+		return f.Form_Name
+	}
+	return f.Name
 }
 
 type Fields []Field
@@ -140,6 +280,10 @@ func (fields Fields) Append(parentIndex []int, t reflect.Type) Fields {
 		}
 
 		_, field.fieldInfo_BSON = new(fieldInfo_BSON).Resolve(f)
+		_, field.fieldInfo_Form = new(fieldInfo_Form).Resolve(f)
+		_, field.fieldInfo_Proto = new(fieldInfo_Proto).Resolve(f)
+		_, field.fieldInfo_Query = new(fieldInfo_Query).Resolve(f)
+		_, field.fieldInfo_XML = new(fieldInfo_XML).Resolve(f)
 		_, field.fieldInfo_Validator = new(fieldInfo_Validator).Resolve(f)
 
 		var jsonName string
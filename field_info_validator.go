@@ -177,6 +177,14 @@ func (inFieldInfo *fieldInfo_Validator) Resolve(f reflect.StructField) (name str
 	return
 }
 
+// ValidateTag returns the parsed validate tag of the field, or nil if the field has none.
+func (f Field) ValidateTag() *FieldTag {
+	if f.fieldInfo_Validator == nil {
+		return nil
+	}
+	return f.rootFieldTag
+}
+
 type fieldTagWalker struct {
 	rootFieldTag *FieldTag
 }
@@ -189,8 +197,16 @@ func createFieldTagWalker(v *fieldInfo_Validator) *fieldTagWalker {
 	return r
 }
 
+// Walk calls walkerFunc for each operator applying to the field itself. Flags without an operator
+// (e.g. omitempty) are skipped and operators following dive apply to the elements, hence they are not walked.
 func (v *fieldTagWalker) Walk(walkerFunc func(fieldTag *FieldTag)) {
 	for current := v.rootFieldTag; current != nil; current = current.Next {
+		if current.Type == TagTypeDive {
+			return
+		}
+		if current.Operator == "" {
+			continue
+		}
 		walkerFunc(current)
 	}
 }
@@ -0,0 +1,257 @@
+package specs
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrFormBindingFailed = errors.New("form binding failed")
+)
+
+const (
+	MediaTypeMultipartForm  = "multipart/form-data"
+	MediaTypeURLEncodedForm = "application/x-www-form-urlencoded"
+
+	// DefaultMaxFormMemory is the number of bytes of a multipart form kept in memory, the
+	// remaining file parts are stored in temporary files.
+	DefaultMaxFormMemory = 32 << 20
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf(multipart.FileHeader{})
+	fileType            = reflect.TypeOf(File{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// File is a file part of a multipart/form-data payload. Fields of type File, *multipart.FileHeader
+// or slices of them are declared as binary strings.
+type File struct {
+	*multipart.FileHeader
+}
+
+func isFormMediaType(mediaType string) bool {
+	return mediaType == MediaTypeMultipartForm || mediaType == MediaTypeURLEncodedForm
+}
+
+func isFileType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t == fileHeaderType || t == fileType
+}
+
+// containsFile reports whether a field of the payload is a file.
+func containsFile(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	t := removeIndirect(reflect.TypeOf(v))
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for _, field := range GetTypeInfo(t).Fields {
+		if isFileType(field.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// formEncoding declares the content types of the parts of a multipart payload, which are set
+// using the contentType tag.
+func formEncoding(v interface{}) map[string]*openapi3.Encoding {
+	if v == nil {
+		return nil
+	}
+	t := removeIndirect(reflect.TypeOf(v))
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var encoding map[string]*openapi3.Encoding
+	for _, field := range GetTypeInfo(t).Fields {
+		if field.fieldInfo_Form == nil || field.Form_ContentType == "" {
+			continue
+		}
+		if encoding == nil {
+			encoding = map[string]*openapi3.Encoding{}
+		}
+		encoding[field.FormName()] = &openapi3.Encoding{
+			ContentType: field.Form_ContentType,
+		}
+	}
+	return encoding
+}
+
+// BindForm decodes a multipart/form-data or application/x-www-form-urlencoded request body into
+// dst, which must be a pointer to a struct. Fields are named by their form tag and fall back to
+// their json name. At most maxMemory bytes of a multipart body are kept in memory.
+func BindForm(req *http.Request, dst interface{}, maxMemory int64) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("destination must be a pointer to a struct, got %T: %w", dst, ErrFormBindingFailed)
+	}
+	v = v.Elem()
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("invalid content type: %v: %w", err, ErrFormBindingFailed)
+	}
+
+	var files map[string][]*multipart.FileHeader
+	switch mediaType {
+	case MediaTypeMultipartForm:
+		if err := req.ParseMultipartForm(maxMemory); err != nil {
+			return fmt.Errorf("failed to parse multipart form: %v: %w", err, ErrFormBindingFailed)
+		}
+		files = req.MultipartForm.File
+	case MediaTypeURLEncodedForm:
+		if err := req.ParseForm(); err != nil {
+			return fmt.Errorf("failed to parse form: %v: %w", err, ErrFormBindingFailed)
+		}
+	default:
+		return fmt.Errorf("unsupported media type %s: %w", mediaType, ErrFormBindingFailed)
+	}
+
+	return bindFormValues(v, req.PostForm, files)
+}
+
+// bindFormValues sets the fields of the struct v from the form values and files.
+func bindFormValues(v reflect.Value, form url.Values, files map[string][]*multipart.FileHeader) error {
+	for _, field := range GetTypeInfo(v.Type()).Fields {
+		name := field.FormName()
+		values, parts := form[name], files[name]
+		if len(values) == 0 && len(parts) == 0 {
+			continue
+		}
+
+		fv, err := allocFieldByIndex(v, field.Index)
+		if err != nil {
+			return fmt.Errorf("form field %s: %v: %w", name, err, ErrFormBindingFailed)
+		}
+		if err := setFormValue(fv, values, parts); err != nil {
+			return fmt.Errorf("form field %s: %v: %w", name, err, ErrFormBindingFailed)
+		}
+	}
+	return nil
+}
+
+// allocFieldByIndex returns the nested field, allocating embedded pointers on the way.
+func allocFieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+func setFormValue(v reflect.Value, values []string, parts []*multipart.FileHeader) error {
+	if isFileType(v.Type()) && len(parts) == 0 {
+		return fmt.Errorf("expected a file")
+	}
+	switch v.Type() {
+	case fileHeaderType:
+		v.Set(reflect.ValueOf(*parts[0]))
+		return nil
+	case fileType:
+		v.Set(reflect.ValueOf(File{parts[0]}))
+		return nil
+	}
+	if len(values) == 0 && !isFileType(v.Type()) {
+		return fmt.Errorf("expected a value, got a file")
+	}
+
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0]))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.Type().Elem() == fileHeaderType {
+			v.Set(reflect.ValueOf(parts[0]))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := setFormValue(elem.Elem(), values, parts); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(values[0]))
+			return nil
+		}
+		n := len(values)
+		if isFileType(v.Type()) {
+			n = len(parts)
+		}
+		slice := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			var err error
+			if isFileType(v.Type()) {
+				err = setFormValue(slice.Index(i), nil, parts[i:i+1])
+			} else {
+				err = setFormValue(slice.Index(i), values[i:i+1], nil)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	value := values[0]
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package specs
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type formTestUpload struct {
+	Title       string                  `json:"title" form:"title" validate:"required"`
+	Tags        []string                `json:"tags" form:"tag"`
+	Count       int                     `json:"count" form:"count"`
+	At          time.Time               `json:"at" form:"at"`
+	Avatar      *multipart.FileHeader   `json:"avatar" form:"avatar" contentType:"image/png, image/jpeg" validate:"required"`
+	Attachments []*multipart.FileHeader `json:"attachments" form:"attachment"`
+	Document    File                    `json:"document" form:"document"`
+}
+
+type formTestLogin struct {
+	Username string `json:"username" form:"user" validate:"required"`
+	Remember bool   `json:"remember" form:"remember"`
+}
+
+func TestAnnotateForm(t *testing.T) {
+	r := NewRegistry[string]()
+	r.POST("/uploads", "").
+		Payload(formTestUpload{}, MediaTypeMultipartForm).
+		Response(204, nil, "Uploaded")
+	r.POST("/login", "").
+		Payload(formTestLogin{}, MediaTypeURLEncodedForm, "application/json").
+		Response(204, nil, "Logged in")
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc)
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Fatal(err)
+	}
+
+	upload := doc.Paths.Find("/uploads").Post.RequestBody.Value.Content.Get(MediaTypeMultipartForm)
+	login := doc.Paths.Find("/login").Post.RequestBody.Value.Content
+	properties := upload.Schema.Value.Properties
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "should name properties by the form tag", got: properties["tag"] != nil && properties["tags"] == nil, want: true},
+		{name: "should declare file headers as binary", got: properties["avatar"].Value.Format, want: "binary"},
+		{name: "should declare file header slices as binary items", got: properties["attachment"].Value.Items.Value.Format, want: "binary"},
+		{name: "should declare files as binary", got: properties["document"].Value.Format, want: "binary"},
+		{name: "should require fields by the form tag", got: strings.Join(upload.Schema.Value.Required, ","), want: "avatar,title"},
+		{name: "should declare the encoding of parts", got: upload.Encoding["avatar"].ContentType, want: "image/png, image/jpeg"},
+		{name: "should name url encoded properties by the form tag", got: login.Get(MediaTypeURLEncodedForm).Schema.Value.Properties["user"] != nil, want: true},
+		{name: "should keep json names for json payloads", got: login.Get("application/json").Schema.Value.Properties["username"] != nil, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayloadRejectsFilesWithoutMultipart(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrPayloadAnnotationFailed) {
+			t.Errorf("got %v, want %v", err, ErrPayloadAnnotationFailed)
+		}
+	}()
+	NewRegistry[string]().POST("/uploads", "").Payload(formTestUpload{}, MediaTypeURLEncodedForm)
+}
+
+func TestBindForm(t *testing.T) {
+	multipartRequest := func(fields map[string][]string, files map[string][]string) *http.Request {
+		body := new(bytes.Buffer)
+		w := multipart.NewWriter(body)
+		for name, values := range fields {
+			for _, value := range values {
+				w.WriteField(name, value)
+			}
+		}
+		for name, contents := range files {
+			for _, content := range contents {
+				part, _ := w.CreateFormFile(name, name+".txt")
+				part.Write([]byte(content))
+			}
+		}
+		w.Close()
+		req := httptest.NewRequest(http.MethodPost, "/uploads", body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	t.Run("should bind multipart forms", func(t *testing.T) {
+		req := multipartRequest(
+			map[string][]string{"title": {"holiday"}, "tag": {"a", "b"}, "count": {"3"}, "at": {"2023-01-02T03:04:05Z"}},
+			map[string][]string{"avatar": {"png"}, "attachment": {"1", "2"}, "document": {"doc"}},
+		)
+		var upload formTestUpload
+		if err := BindForm(req, &upload, DefaultMaxFormMemory); err != nil {
+			t.Fatal(err)
+		}
+		if upload.Title != "holiday" || strings.Join(upload.Tags, ",") != "a,b" || upload.Count != 3 || upload.At.Year() != 2023 {
+			t.Errorf("got %+v", upload)
+		}
+		if upload.Avatar == nil || upload.Avatar.Filename != "avatar.txt" || len(upload.Attachments) != 2 || upload.Document.FileHeader == nil {
+			t.Errorf("got files %+v", upload)
+		}
+	})
+
+	t.Run("should bind url encoded forms", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(url.Values{"user": {"jane"}, "remember": {"true"}}.Encode()))
+		req.Header.Set("Content-Type", MediaTypeURLEncodedForm)
+		var login formTestLogin
+		if err := BindForm(req, &login, DefaultMaxFormMemory); err != nil {
+			t.Fatal(err)
+		}
+		if login.Username != "jane" || !login.Remember {
+			t.Errorf("got %+v", login)
+		}
+	})
+
+	errorTests := []struct {
+		name string
+		req  *http.Request
+		dst  interface{}
+	}{
+		{name: "should reject invalid values", req: multipartRequest(map[string][]string{"count": {"three"}}, nil), dst: &formTestUpload{}},
+		{name: "should reject values for files", req: multipartRequest(map[string][]string{"avatar": {"png"}}, nil), dst: &formTestUpload{}},
+		{name: "should reject other media types", req: httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")), dst: &formTestUpload{}},
+		{name: "should reject non-pointer destinations", req: multipartRequest(nil, nil), dst: formTestUpload{}},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := BindForm(tt.req, tt.dst, DefaultMaxFormMemory); !errors.Is(err, ErrFormBindingFailed) {
+				t.Errorf("got %v, want %v", err, ErrFormBindingFailed)
+			}
+		})
+	}
+}
@@ -5,6 +5,9 @@ go 1.18
 require (
 	github.com/getkin/kin-openapi v0.115.0
 	github.com/matoous/go-nanoid/v2 v2.0.0
+	github.com/swaggo/files/v2 v2.0.2
+	github.com/ugorji/go/codec v1.2.9
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -17,5 +20,4 @@ require (
 	github.com/perimeterx/marshmallow v1.1.4 // indirect
 	github.com/stretchr/testify v1.8.2 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
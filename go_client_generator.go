@@ -0,0 +1,703 @@
+package specs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrGoClientGenerationFailed = errors.New("go client generation failed")
+)
+
+type GoClientGeneratorOption func(*goClientGeneratorOptions)
+
+type goClientGeneratorOptions struct {
+	packageName string
+}
+
+// GoClientPackage sets the package name of the generated client. Defaults to "client".
+func GoClientPackage(name string) GoClientGeneratorOption {
+	return func(o *goClientGeneratorOptions) {
+		o.packageName = name
+	}
+}
+
+// goImports tracks the packages referenced by the generated code and assigns each a unique alias.
+type goImports struct {
+	aliases map[string]string
+	taken   map[string]string
+}
+
+func newGoImports(stdlib ...string) *goImports {
+	imports := &goImports{
+		aliases: map[string]string{},
+		taken:   map[string]string{},
+	}
+	for _, pkgPath := range stdlib {
+		imports.Alias(pkgPath)
+	}
+	return imports
+}
+
+func (i *goImports) Alias(pkgPath string) string {
+	if alias, ok := i.aliases[pkgPath]; ok {
+		return alias
+	}
+	base := goIdentifier(path.Base(pkgPath), false)
+	alias := base
+	for n := 2; ; n++ {
+		if _, ok := i.taken[alias]; !ok {
+			break
+		}
+		alias = base + strconv.Itoa(n)
+	}
+	i.aliases[pkgPath] = alias
+	i.taken[alias] = pkgPath
+	return alias
+}
+
+func (i *goImports) Write(w io.Writer) {
+	pkgPaths := make([]string, 0, len(i.aliases))
+	for pkgPath := range i.aliases {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+
+	fmt.Fprintln(w, "import (")
+	for _, pkgPath := range pkgPaths {
+		if alias := i.aliases[pkgPath]; alias != path.Base(pkgPath) {
+			fmt.Fprintf(w, "\t%s %q\n", alias, pkgPath)
+		} else {
+			fmt.Fprintf(w, "\t%q\n", pkgPath)
+		}
+	}
+	fmt.Fprintln(w, ")")
+}
+
+// goTypeExpr returns the go expression referencing the type t, registering the required imports.
+func goTypeExpr(t reflect.Type, imports *goImports) (string, error) {
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			return t.Name(), nil
+		}
+		if t.PkgPath() == "main" {
+			return "", fmt.Errorf("type %s is declared in package main: %w", t, ErrGoClientGenerationFailed)
+		}
+		// the type arguments of generic types are only known by their qualified names
+		name, err := goTypeNameExpr(t.Name(), imports)
+		if err != nil {
+			return "", fmt.Errorf("generic type %s cannot be referenced: %v: %w", t, err, ErrGoClientGenerationFailed)
+		}
+		return imports.Alias(t.PkgPath()) + "." + name, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := goTypeExpr(t.Elem(), imports)
+		return "*" + elem, err
+	case reflect.Slice:
+		elem, err := goTypeExpr(t.Elem(), imports)
+		return "[]" + elem, err
+	case reflect.Map:
+		key, err := goTypeExpr(t.Key(), imports)
+		if err != nil {
+			return "", err
+		}
+		elem, err := goTypeExpr(t.Elem(), imports)
+		return "map[" + key + "]" + elem, err
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}", nil
+		}
+	}
+	return "", fmt.Errorf("anonymous type %s cannot be referenced: %w", t, ErrGoClientGenerationFailed)
+}
+
+// goTypeNameExpr returns the go expression of a qualified type name as reported by reflect, e.g.
+// []github.com/jakoblorz/specs.Page[example.com/api.User] becomes []specs.Page[api.User].
+func goTypeNameExpr(name string, imports *goImports) (string, error) {
+	switch {
+	case strings.HasPrefix(name, "*"):
+		elem, err := goTypeNameExpr(name[1:], imports)
+		return "*" + elem, err
+	case strings.HasPrefix(name, "map["):
+		key, value := splitMapTypeName(name)
+		keyExpr, err := goTypeNameExpr(key, imports)
+		if err != nil {
+			return "", err
+		}
+		valueExpr, err := goTypeNameExpr(value, imports)
+		return "map[" + keyExpr + "]" + valueExpr, err
+	case strings.HasPrefix(name, "["):
+		end := strings.IndexByte(name, ']')
+		elem, err := goTypeNameExpr(name[end+1:], imports)
+		return name[:end+1] + elem, err
+	}
+
+	base, args := name, ""
+	if open := strings.IndexByte(name, '['); open != -1 && strings.HasSuffix(name, "]") {
+		base, args = name[:open], name[open+1:len(name)-1]
+	}
+	if strings.ContainsAny(base, " {}()") {
+		return "", fmt.Errorf("type %s cannot be referenced", base)
+	}
+	if dot := strings.LastIndexByte(base, '.'); dot != -1 {
+		if base[:dot] == "main" {
+			return "", fmt.Errorf("type %s is declared in package main", base)
+		}
+		base = imports.Alias(base[:dot]) + "." + base[dot+1:]
+	}
+	if args == "" {
+		return base, nil
+	}
+
+	var exprs []string
+	for _, arg := range splitTypeArgs(args) {
+		expr, err := goTypeNameExpr(arg, imports)
+		if err != nil {
+			return "", err
+		}
+		exprs = append(exprs, expr)
+	}
+	return base + "[" + strings.Join(exprs, ", ") + "]", nil
+}
+
+// goIdentifier converts s into a go identifier by joining all alphanumeric parts in camel case.
+func goIdentifier(s string, exported bool) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for i, part := range parts {
+		first, size := utf8.DecodeRuneInString(part)
+		if i == 0 && !exported {
+			b.WriteRune(unicode.ToLower(first))
+		} else {
+			b.WriteRune(unicode.ToUpper(first))
+		}
+		b.WriteString(part[size:])
+	}
+
+	identifier := b.String()
+	if identifier == "" {
+		return "_"
+	}
+	if first, _ := utf8.DecodeRuneInString(identifier); unicode.IsDigit(first) {
+		identifier = "Op" + identifier
+	}
+	return identifier
+}
+
+// goFieldAccessor returns the selector expression of the field in the struct v.
+func goFieldAccessor(v string, t reflect.Type, field Field) string {
+	t = removeIndirect(t)
+	selector := v
+	for i := range field.Index {
+		selector += "." + t.FieldByIndex(field.Index[:i+1]).Name
+	}
+	return selector
+}
+
+// successResponseStatus returns the status of the primary success response of an endpoint.
+func successResponseStatus[T interface{}](endpoint *Endpoint[T]) (int, bool) {
+	if _, ok := endpoint.Response[endpoint.Status]; ok && endpoint.Status != 0 {
+		return endpoint.Status, true
+	}
+	statuses := sortedResponseStatuses(endpoint.Response)
+	for _, status := range statuses {
+		if status >= 200 && status < 300 {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+func sortedResponseStatuses(responses map[int]Response) []int {
+	statuses := make([]int, 0, len(responses))
+	for status := range responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	return statuses
+}
+
+// sortedEndpoints returns the endpoints ordered by path and method.
+func (r *registry[T]) sortedEndpoints() []*Endpoint[T] {
+	endpoints := make([]*Endpoint[T], 0, len(r.routes))
+	for _, endpoint := range r.routes {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		if endpoints[i].Method != endpoints[j].Method {
+			return endpoints[i].Method < endpoints[j].Method
+		}
+		return endpoints[i].OperationID < endpoints[j].OperationID
+	})
+	return endpoints
+}
+
+// GenerateGoClient writes the source of a go client with one method per operation ID, which must be
+// stable (see MethodPathOperationIDGenerator). The request and response types declared on the endpoints
+// are referenced directly and are therefore required to be named types of an importable package.
+// Bodies are encoded as JSON, XML or forms, other media types fail with ErrGoClientGenerationFailed.
+func (r *registry[T]) GenerateGoClient(w io.Writer, opts ...GoClientGeneratorOption) error {
+	options := &goClientGeneratorOptions{
+		packageName: "client",
+	}
+	for _, applyOption := range opts {
+		applyOption(options)
+	}
+
+	imports := newGoImports("bytes", "context", "encoding/json", "fmt", "io", "mime/multipart", "net/http", "net/url", "reflect", "sort", "strings")
+	body := new(bytes.Buffer)
+	body.WriteString(goClientRuntime)
+
+	var endpoints []*Endpoint[T]
+	for _, endpoint := range r.sortedEndpoints() {
+		if !isChannel(endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if err := r.requireStableOperationIDs(endpoints); err != nil {
+		return fmt.Errorf("%v: %w", err, ErrGoClientGenerationFailed)
+	}
+
+	methodNames := map[string]string{}
+	for _, endpoint := range endpoints {
+		methodName := goIdentifier(endpoint.OperationID, true)
+		if other, ok := methodNames[methodName]; ok {
+			return fmt.Errorf("operations %s and %s map to the same method %s: %w", other, endpoint.OperationID, methodName, ErrGoClientGenerationFailed)
+		}
+		methodNames[methodName] = endpoint.OperationID
+
+		if err := writeGoClientMethod(body, imports, methodName, endpoint); err != nil {
+			return fmt.Errorf("failed to generate %s %s: %w", endpoint.Method, endpoint.Path, err)
+		}
+	}
+
+	src := new(bytes.Buffer)
+	fmt.Fprintf(src, "// Code generated by github.com/jakoblorz/specs. DO NOT EDIT.\n\npackage %s\n\n", options.packageName)
+	imports.Write(src)
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format generated client: %v: %w", err, ErrGoClientGenerationFailed)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+func writeGoClientMethod[T interface{}](w io.Writer, imports *goImports, methodName string, endpoint *Endpoint[T]) error {
+	var (
+		args    = []string{"ctx context.Context"}
+		result  = ""
+		primary Response
+	)
+
+	successStatus, hasSuccess := successResponseStatus(endpoint)
+	if hasSuccess {
+		primary = endpoint.Response[successStatus]
+	}
+	if hasSuccess && primary.Value != nil {
+		expr, err := goTypeExpr(reflect.TypeOf(primary.Value), imports)
+		if err != nil {
+			return err
+		}
+		result = expr
+	}
+
+	if endpoint.Parameters != nil {
+		expr, err := goTypeExpr(reflect.TypeOf(endpoint.Parameters), imports)
+		if err != nil {
+			return err
+		}
+		args = append(args, "params "+expr)
+	}
+	if endpoint.Query != nil {
+		expr, err := goTypeExpr(reflect.TypeOf(endpoint.Query), imports)
+		if err != nil {
+			return err
+		}
+		args = append(args, "query "+expr)
+	}
+	var payload *Body
+	if len(endpoint.Payload) > 0 && endpoint.Method != http.MethodGet {
+		payload = &endpoint.Payload[0]
+		expr, err := goTypeExpr(reflect.TypeOf(payload.Value), imports)
+		if err != nil {
+			return err
+		}
+		args = append(args, "payload "+expr)
+	}
+
+	returns, zeroReturn := "error", "return "
+	if result != "" {
+		returns, zeroReturn = "(*"+result+", error)", "return nil, "
+	}
+
+	// typed errors for every declared status that is not a success
+	type errorType struct {
+		name   string
+		status int
+		body   string
+	}
+	var errorTypes []errorType
+	for _, status := range sortedResponseStatuses(endpoint.Response) {
+		if status >= 200 && status < 300 {
+			continue
+		}
+		response := endpoint.Response[status]
+		e := errorType{
+			name:   methodName + goIdentifier(http.StatusText(status), true) + "Error",
+			status: status,
+		}
+		if response.Value != nil {
+			expr, err := goTypeExpr(reflect.TypeOf(response.Value), imports)
+			if err != nil {
+				return err
+			}
+			e.body = expr
+		}
+		errorTypes = append(errorTypes, e)
+	}
+
+	for _, e := range errorTypes {
+		fmt.Fprintf(w, "\n// %s is returned by %s when the server responds with status %d.\n", e.name, methodName, e.status)
+		fmt.Fprintf(w, "type %s struct {\n", e.name)
+		if e.body != "" {
+			fmt.Fprintf(w, "\tBody %s\n", e.body)
+		}
+		fmt.Fprintf(w, "}\n\n")
+		fmt.Fprintf(w, "func (e *%s) StatusCode() int { return %d }\n\n", e.name, e.status)
+		fmt.Fprintf(w, "func (e *%s) Error() string { return \"%s: status %d\" }\n", e.name, methodName, e.status)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "// %s calls %s %s.\n", methodName, endpoint.Method, endpoint.Path)
+	if endpoint.Title != "" {
+		fmt.Fprintf(w, "//\n// %s\n", endpoint.Title)
+	}
+	if endpoint.Deprecated {
+		fmt.Fprintf(w, "//\n// Deprecated: the operation is marked as deprecated.\n")
+	}
+	fmt.Fprintf(w, "func (c *Client) %s(%s) %s {\n", methodName, strings.Join(args, ", "), returns)
+
+	// path
+	template := parsePathTemplate(endpoint.Path)
+	pathExpr := make([]string, 0, len(template.Segments))
+	for _, segment := range template.Segments {
+		if !segment.IsParam() {
+			pathExpr = append(pathExpr, strconv.Quote("/"+segment.Literal))
+			continue
+		}
+		if endpoint.Parameters == nil {
+			return fmt.Errorf("path parameter %s is not declared: %w", segment.Param, ErrGoClientGenerationFailed)
+		}
+		var accessor string
+		for _, field := range GetTypeInfo(reflect.TypeOf(endpoint.Parameters)).Fields {
+			if field.Name == segment.Param {
+				accessor = goFieldAccessor("params", reflect.TypeOf(endpoint.Parameters), field)
+			}
+		}
+		if accessor == "" {
+			return fmt.Errorf("path parameter %s has no matching field: %w", segment.Param, ErrGoClientGenerationFailed)
+		}
+		pathExpr = append(pathExpr, fmt.Sprintf("\"/\" + url.PathEscape(fmt.Sprint(%s))", accessor))
+	}
+	if len(pathExpr) == 0 {
+		pathExpr = append(pathExpr, strconv.Quote("/"))
+	}
+	fmt.Fprintf(w, "\tpath := %s\n", strings.Join(pathExpr, " + "))
+
+	// query
+	fmt.Fprintf(w, "\tvalues := url.Values{}\n")
+	if endpoint.Query != nil {
+		queryType := reflect.TypeOf(endpoint.Query)
+		for _, field := range GetTypeInfo(queryType).Fields {
+			// filterable fields are sent as conditions of the filters
+			if field.fieldInfo_Query != nil && len(field.Query_Filterable) > 0 {
+				continue
+			}
+			fmt.Fprintf(w, "\taddQueryValue(values, %q, %s, %t)\n", field.Name, goFieldAccessor("query", queryType, field), field.JSONOmitEmpty())
+		}
+	}
+
+	// body
+	fmt.Fprintf(w, "\tvar body io.Reader\n")
+	if payload != nil {
+		if err := writeGoClientBody(w, imports, payload, zeroReturn); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "\treq, err := c.newRequest(ctx, %q, path, values, body)\n\tif err != nil {\n\t\t%serr\n\t}\n", endpoint.Method, zeroReturn)
+	if payload != nil && payload.MediaType == MediaTypeMultipartForm {
+		fmt.Fprintf(w, "\treq.Header.Set(\"Content-Type\", mw.FormDataContentType())\n")
+	} else if payload != nil {
+		fmt.Fprintf(w, "\treq.Header.Set(\"Content-Type\", %q)\n", payload.MediaType)
+	}
+	if hasSuccess && primary.MediaType != "" {
+		fmt.Fprintf(w, "\treq.Header.Set(\"Accept\", %q)\n", primary.MediaType)
+	}
+
+	fmt.Fprintf(w, "\tres, err := c.doer.Do(req)\n\tif err != nil {\n\t\t%serr\n\t}\n\tdefer res.Body.Close()\n\n", zeroReturn)
+	fmt.Fprintf(w, "\tswitch res.StatusCode {\n")
+	if hasSuccess {
+		fmt.Fprintf(w, "\tcase %d:\n", successStatus)
+		if result != "" {
+			codec, err := goClientCodec(primary.MediaType, imports)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\tout := new(%s)\n\t\tif err := %s.NewDecoder(res.Body).Decode(out); err != nil {\n\t\t\treturn nil, err\n\t\t}\n\t\treturn out, nil\n", result, codec)
+		} else {
+			fmt.Fprintf(w, "\t\treturn nil\n")
+		}
+	}
+	for _, e := range errorTypes {
+		fmt.Fprintf(w, "\tcase %d:\n\t\te := new(%s)\n", e.status, e.name)
+		if e.body != "" {
+			codec, err := goClientCodec(endpoint.Response[e.status].MediaType, imports)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "\t\tif err := %s.NewDecoder(res.Body).Decode(&e.Body); err != nil {\n\t\t\t%serr\n\t\t}\n", codec, zeroReturn)
+		}
+		fmt.Fprintf(w, "\t\t%se\n", zeroReturn)
+	}
+	fmt.Fprintf(w, "\t}\n\t%snewError(res)\n}\n", zeroReturn)
+	return nil
+}
+
+// goClientCodec returns the package encoding and decoding bodies of the media type in the generated
+// client, which supports JSON and XML besides forms.
+func goClientCodec(mediaType string, imports *goImports) (string, error) {
+	normalized, err := normalizeMediaType(mediaType)
+	switch {
+	case err == nil && (normalized == MediaTypeJSON || strings.HasSuffix(normalized, "+json")):
+		return imports.Alias("encoding/json"), nil
+	case isXMLMediaType(mediaType):
+		return imports.Alias("encoding/xml"), nil
+	}
+	return "", fmt.Errorf("bodies of media type %s cannot be encoded: %w", mediaType, ErrGoClientGenerationFailed)
+}
+
+// writeGoClientBody writes the statements encoding the payload into body according to its media type.
+func writeGoClientBody(w io.Writer, imports *goImports, payload *Body, zeroReturn string) error {
+	if !isFormMediaType(payload.MediaType) {
+		codec, err := goClientCodec(payload.MediaType, imports)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\tb, err := %s.Marshal(payload)\n\tif err != nil {\n\t\t%serr\n\t}\n\tbody = bytes.NewReader(b)\n", codec, zeroReturn)
+		return nil
+	}
+
+	payloadType := reflect.TypeOf(payload.Value)
+	if removeIndirect(payloadType).Kind() != reflect.Struct {
+		return fmt.Errorf("form payload %s is not a struct: %w", payloadType, ErrGoClientGenerationFailed)
+	}
+
+	fmt.Fprintf(w, "\tform := url.Values{}\n")
+	var files []string
+	for _, field := range GetTypeInfo(payloadType).Fields {
+		accessor := goFieldAccessor("payload", payloadType, field)
+		if !isFileType(field.Type) {
+			fmt.Fprintf(w, "\taddQueryValue(form, %q, %s, %t)\n", field.FormName(), accessor, field.JSONOmitEmpty())
+			continue
+		}
+
+		switch field.Type {
+		case reflect.PtrTo(fileHeaderType):
+			files = append(files, fmt.Sprintf("\tfiles[%q] = append(files[%q], %s)\n", field.FormName(), field.FormName(), accessor))
+		case reflect.SliceOf(reflect.PtrTo(fileHeaderType)):
+			files = append(files, fmt.Sprintf("\tfiles[%q] = append(files[%q], %s...)\n", field.FormName(), field.FormName(), accessor))
+		case fileType:
+			files = append(files, fmt.Sprintf("\tfiles[%q] = append(files[%q], %s.FileHeader)\n", field.FormName(), field.FormName(), accessor))
+		case reflect.SliceOf(fileType):
+			files = append(files, fmt.Sprintf("\tfor _, f := range %s {\n\t\tfiles[%q] = append(files[%q], f.FileHeader)\n\t}\n", accessor, field.FormName(), field.FormName()))
+		default:
+			return fmt.Errorf("file field %s of type %s cannot be encoded: %w", field.Name, field.Type, ErrGoClientGenerationFailed)
+		}
+	}
+
+	if payload.MediaType == MediaTypeURLEncodedForm {
+		fmt.Fprintf(w, "\tbody = strings.NewReader(form.Encode())\n")
+		return nil
+	}
+	fmt.Fprintf(w, "\tfiles := map[string][]*multipart.FileHeader{}\n")
+	for _, file := range files {
+		fmt.Fprint(w, file)
+	}
+	fmt.Fprintf(w, "\tb := new(bytes.Buffer)\n\tmw := multipart.NewWriter(b)\n")
+	fmt.Fprintf(w, "\tif err := writeMultipartForm(mw, form, files); err != nil {\n\t\t%serr\n\t}\n\tbody = b\n", zeroReturn)
+	return nil
+}
+
+const goClientRuntime = `
+// Doer executes http requests. *http.Client implements Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer, e.g. to add authentication or tracing.
+type Middleware func(next Doer) Doer
+
+type Option func(*Client)
+
+// WithHTTPClient sets the client used to execute requests. Defaults to http.DefaultClient.
+func WithHTTPClient(doer Doer) Option {
+	return func(c *Client) {
+		c.doer = doer
+	}
+}
+
+// WithMiddleware wraps the http client with the middlewares, the first one being the outermost.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+type Client struct {
+	baseURL     string
+	doer        Doer
+	middlewares []Middleware
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		doer:    http.DefaultClient,
+	}
+	for _, applyOption := range opts {
+		applyOption(c)
+	}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		c.doer = c.middlewares[i](c.doer)
+	}
+	return c
+}
+
+// Error is returned when the server responds with a status code that was not declared.
+type Error struct {
+	Code int
+	Body []byte
+}
+
+func (e *Error) StatusCode() int { return e.Code }
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.Code, e.Body)
+}
+
+func newError(res *http.Response) error {
+	body, _ := io.ReadAll(res.Body)
+	return &Error{Code: res.StatusCode, Body: body}
+}
+
+func (c *Client) newRequest(ctx context.Context, method string, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return http.NewRequestWithContext(ctx, method, u, body)
+}
+
+// addQueryValue adds v to the query unless it is nil. Zero values are only omitted if the field is
+// tagged omitempty, optional values without omitempty are declared as pointers.
+func addQueryValue(query url.Values, name string, v interface{}, omitEmpty bool) {
+	if encoder, ok := v.(interface{ EncodeQuery(string, url.Values) }); ok {
+		encoder.EncodeQuery(name, query)
+		return
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || omitEmpty && rv.IsZero() {
+		return
+	}
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			query.Add(name, fmt.Sprint(rv.Index(i).Interface()))
+		}
+		return
+	}
+	query.Set(name, fmt.Sprint(rv.Interface()))
+}
+
+// writeMultipartForm writes the values and files of a multipart payload and closes the writer.
+func writeMultipartForm(mw *multipart.Writer, form url.Values, files map[string][]*multipart.FileHeader) error {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range form[name] {
+			if err := mw.WriteField(name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	names = names[:0]
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, fh := range files[name] {
+			if fh == nil {
+				continue
+			}
+			if err := writeMultipartFile(mw, name, fh); err != nil {
+				return err
+			}
+		}
+	}
+	return mw.Close()
+}
+
+func writeMultipartFile(mw *multipart.Writer, name string, fh *multipart.FileHeader) error {
+	f, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	part, err := mw.CreateFormFile(name, fh.Filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, f)
+	return err
+}
+`
@@ -0,0 +1,396 @@
+package specs
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+type goClientTestParams struct {
+	ID string `json:"id"`
+}
+
+type goClientTestQuery struct {
+	Offset *int     `json:"offset"`
+	Active bool     `json:"active"`
+	Cursor string   `json:"cursor,omitempty"`
+	Sort   Sort     `json:"sort" sortable:"name"`
+	Filter Filters  `json:"filter"`
+	Name   string   `json:"name" filterable:"eq"`
+	Tags   []string `json:"tags"`
+}
+
+type goClientTestUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name" validate:"required"`
+}
+
+type goClientTestError struct {
+	Message string `json:"message"`
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s, run go test -update to accept it:\n%s", golden, got)
+	}
+}
+
+func TestGenerateGoClient(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", "").Title("List users").Query(goClientTestQuery{}).Response(200, []goClientTestUser{}, "Users found")
+	r.POST("/users", "").Payload(goClientTestUser{}).Response(201, goClientTestUser{}, "User created")
+	r.GET("/users/{id}", "").Parameters(goClientTestParams{}).
+		Response(200, goClientTestUser{}, "User found").
+		Response(404, goClientTestError{}, "User not found").
+		Deprecated()
+	r.DELETE("/users/{id}", "").Parameters(goClientTestParams{}).Response(204, nil, "User deleted")
+	r.PUT("/users/{id}", "").Parameters(goClientTestParams{}).Payload(goClientTestUser{}, MediaTypeXML).
+		Response(200, goClientTestUser{}, "User updated", MediaTypeXML).
+		Response(404, goClientTestError{}, "User not found")
+
+	b := new(bytes.Buffer)
+	if err := r.GenerateGoClient(b, GoClientPackage("users")); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "go_client.golden", b.Bytes())
+}
+
+func TestGenerateGoClientFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *registry[string])
+		opts     []RegistryOption
+	}{
+		{
+			name: "should reject random operation IDs",
+			register: func(r *registry[string]) {
+				r.GET("/users", "").Response(200, goClientTestUser{}, "")
+			},
+		},
+		{
+			name: "should reject anonymous types",
+			register: func(r *registry[string]) {
+				r.GET("/users", "").Response(200, struct{ ID string }{}, "")
+			},
+			opts: []RegistryOption{OperationIDGenerator(MethodPathOperationIDGenerator)},
+		},
+		{
+			name: "should reject payloads of media types without encoding",
+			register: func(r *registry[string]) {
+				r.POST("/users", "").Payload(goClientTestUser{}, MediaTypeCBOR)
+			},
+			opts: []RegistryOption{OperationIDGenerator(MethodPathOperationIDGenerator)},
+		},
+		{
+			name: "should reject responses of media types without encoding",
+			register: func(r *registry[string]) {
+				r.GET("/users", "").Response(200, "", "", MediaTypeText)
+			},
+			opts: []RegistryOption{OperationIDGenerator(MethodPathOperationIDGenerator)},
+		},
+		{
+			name: "should reject operations mapping to the same method",
+			register: func(r *registry[string]) {
+				r.GET("/users", "").OperationID("get-users")
+				r.GET("/users/", "").OperationID("getUsers")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry[string](tt.opts...)
+			tt.register(r)
+			if err := r.GenerateGoClient(new(bytes.Buffer)); !errors.Is(err, ErrGoClientGenerationFailed) {
+				t.Errorf("got %v, want %v", err, ErrGoClientGenerationFailed)
+			}
+		})
+	}
+
+	t.Run("should accept explicit operation IDs", func(t *testing.T) {
+		r := NewRegistry[string]()
+		r.GET("/users", "").OperationID("listUsers").Response(200, goClientTestUser{}, "")
+		if err := r.GenerateGoClient(new(bytes.Buffer)); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestGoIdentifier(t *testing.T) {
+	tests := []struct {
+		s        string
+		exported bool
+		want     string
+	}{
+		{s: "get /users/{id}", exported: true, want: "GetUsersId"},
+		{s: "get-users", want: "getUsers"},
+		{s: "élan vital", exported: true, want: "ÉlanVital"},
+		{s: "Über größe", want: "überGröße"},
+		{s: "2fa enable", exported: true, want: "Op2faEnable"},
+		{s: "٣d", exported: true, want: "Op٣d"},
+		{s: "--", want: "_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := goIdentifier(tt.s, tt.exported); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGoClientRoundTrip generates a client in a temporary module and runs it against an httptest
+// server, as the generated code can only reference types of importable packages.
+func TestGoClientRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles a temporary module")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile("go.sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                "module roundtrip\n\ngo 1.18\n\nrequire github.com/jakoblorz/specs v0.0.0\n\nreplace github.com/jakoblorz/specs => " + root + "\n",
+		"go.sum":                string(sum),
+		"api/api.go":            goClientRoundTripAPI,
+		"gen/main.go":           goClientRoundTripGen,
+		"client/client_test.go": goClientRoundTripTest,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(args ...string) {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run("run", "./gen", "client/client.go")
+	run("test", "./client")
+}
+
+const goClientRoundTripAPI = `package api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jakoblorz/specs"
+)
+
+type UserParams struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type ListUsersQuery struct {
+	Offset *int       ` + "`json:\"offset\"`" + `
+	Active bool       ` + "`json:\"active\"`" + `
+	Name   string     ` + "`json:\"name\"`" + `
+	Cursor string     ` + "`json:\"cursor,omitempty\"`" + `
+	Sort   specs.Sort ` + "`json:\"sort\" sortable:\"name\"`" + `
+}
+
+type ListUsersResponse struct {
+	Query string ` + "`json:\"query\"`" + `
+}
+
+type User struct {
+	ID   string ` + "`json:\"id\"`" + `
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type NotFound struct {
+	Message string ` + "`json:\"message\"`" + `
+}
+
+var (
+	router = specs.NewRegistry[http.HandlerFunc](specs.OperationIDGenerator(specs.MethodPathOperationIDGenerator))
+)
+
+func init() {
+	router.GET("/users", nil).Query(ListUsersQuery{}).Response(200, ListUsersResponse{}, "Users found")
+	router.POST("/users", nil).Payload(User{}).Response(201, User{}, "User created")
+	router.GET("/users/{id}", nil).Parameters(UserParams{}).
+		Response(200, User{}, "User found").
+		Response(404, NotFound{}, "User not found")
+	router.PUT("/users/{id}", nil).Parameters(UserParams{}).Payload(User{}, specs.MediaTypeXML).
+		Response(200, User{}, "User updated", specs.MediaTypeXML)
+}
+
+func GenerateGoClient(w io.Writer) error {
+	return router.GenerateGoClient(w)
+}
+
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/users":
+			json.NewEncoder(w).Encode(ListUsersResponse{Query: r.URL.RawQuery})
+		case r.Method == http.MethodPost && r.URL.Path == "/users":
+			var user User
+			if err := json.NewDecoder(r.Body).Decode(&user); err != nil || r.Header.Get("Content-Type") != "application/json" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			user.ID = "1"
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(user)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/users/"):
+			var user User
+			if err := xml.NewDecoder(r.Body).Decode(&user); err != nil || r.Header.Get("Content-Type") != "application/xml" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			user.ID = strings.TrimPrefix(r.URL.Path, "/users/")
+			w.Header().Set("Content-Type", "application/xml")
+			xml.NewEncoder(w).Encode(user)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/users/"):
+			id := strings.TrimPrefix(r.URL.Path, "/users/")
+			if id == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(NotFound{Message: "no user " + id})
+				return
+			}
+			json.NewEncoder(w).Encode(User{ID: id, Name: "Jane"})
+		default:
+			w.WriteHeader(http.StatusTeapot)
+			io.WriteString(w, "unexpected request")
+		}
+	})
+}
+`
+
+const goClientRoundTripGen = `package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"roundtrip/api"
+)
+
+func main() {
+	b := new(bytes.Buffer)
+	if err := api.GenerateGoClient(b); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(os.Args[1], b.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+`
+
+const goClientRoundTripTest = `package client
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jakoblorz/specs"
+	"roundtrip/api"
+)
+
+func TestRoundTrip(t *testing.T) {
+	s := httptest.NewServer(api.Handler())
+	defer s.Close()
+	c := New(s.URL)
+	ctx := context.Background()
+
+	t.Run("should send zero values unless omitted", func(t *testing.T) {
+		offset := 0
+		got, err := c.GetUsers(ctx, api.ListUsersQuery{Offset: &offset, Sort: specs.Sort{{Key: "name", Desc: true}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "active=false&name=&offset=0&sort=-name"; got.Query != want {
+			t.Errorf("got query %s, want %s", got.Query, want)
+		}
+	})
+	t.Run("should skip nil pointers", func(t *testing.T) {
+		got, err := c.GetUsers(ctx, api.ListUsersQuery{Active: true, Name: "jane", Cursor: "abc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "active=true&cursor=abc&name=jane"; got.Query != want {
+			t.Errorf("got query %s, want %s", got.Query, want)
+		}
+	})
+	t.Run("should encode payloads", func(t *testing.T) {
+		got, err := c.PostUsers(ctx, api.User{Name: "Jane"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != "1" || got.Name != "Jane" {
+			t.Errorf("got %+v", got)
+		}
+	})
+	t.Run("should encode and decode xml bodies", func(t *testing.T) {
+		got, err := c.PutUsersById(ctx, api.UserParams{ID: "2"}, api.User{Name: "Jane"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != "2" || got.Name != "Jane" {
+			t.Errorf("got %+v", got)
+		}
+	})
+	t.Run("should escape path parameters", func(t *testing.T) {
+		got, err := c.GetUsersById(ctx, api.UserParams{ID: "a b/c"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != "a b/c" {
+			t.Errorf("got %+v", got)
+		}
+	})
+	t.Run("should return typed errors of declared statuses", func(t *testing.T) {
+		_, err := c.GetUsersById(ctx, api.UserParams{ID: "missing"})
+		var notFound *GetUsersByIdNotFoundError
+		if !errors.As(err, &notFound) || notFound.Body.Message != "no user missing" {
+			t.Errorf("got %v", err)
+		}
+	})
+}
+`
@@ -0,0 +1,459 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrGraphQLGenerationFailed = errors.New("graphql generation failed")
+)
+
+var (
+	graphQLNameRegex = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+)
+
+const (
+	graphQLQuery         = "Query"
+	graphQLMutation      = "Mutation"
+	graphQLInputArgument = "input"
+)
+
+// graphQLArgument is an argument of a Query or Mutation field and the request location it is sent in.
+type graphQLArgument struct {
+	name string
+	in   string
+	key  string
+}
+
+// graphQLOperation is the Query or Mutation field of an endpoint.
+type graphQLOperation[T interface{}] struct {
+	typeName  string
+	fieldName string
+	arguments []graphQLArgument
+	endpoint  *Endpoint[T]
+	response  *Response
+}
+
+// graphQLOperations returns the operations of the endpoints matching all filters. GET endpoints become
+// Query fields, all others Mutation fields. Channels and streamed responses are not supported by the
+// generated schema and skipped. Fields are named after the operation IDs, which must be stable.
+func graphQLOperations[T interface{}](r *registry[T], filters []EndpointFilter[T]) ([]graphQLOperation[T], error) {
+	var (
+		operations []graphQLOperation[T]
+		endpoints  []*Endpoint[T]
+	)
+	fieldNames := map[string]string{}
+	for _, endpoint := range r.filteredEndpoints(filters) {
+		response := successResponse(endpoint)
+		if isChannel(endpoint) || (response != nil && len(response.Events) > 0) {
+			continue
+		}
+		endpoints = append(endpoints, endpoint)
+
+		operation := graphQLOperation[T]{
+			typeName:  graphQLMutation,
+			fieldName: goIdentifier(endpoint.OperationID, false),
+			endpoint:  endpoint,
+			response:  response,
+		}
+		if endpoint.Method == http.MethodGet {
+			operation.typeName = graphQLQuery
+		}
+		key := operation.typeName + "." + operation.fieldName
+		if other, ok := fieldNames[key]; ok {
+			return nil, fmt.Errorf("operations %s and %s map to the same field %s: %w", other, endpoint.OperationID, key, ErrGraphQLGenerationFailed)
+		}
+		fieldNames[key] = endpoint.OperationID
+
+		arguments := map[string]struct{}{}
+		for _, location := range []struct {
+			in    string
+			value interface{}
+		}{
+			{"path", endpoint.Parameters},
+			{"query", endpoint.Query},
+		} {
+			if location.value == nil {
+				continue
+			}
+			if t := removeIndirect(reflect.TypeOf(location.value)); t.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("%s arguments of %s must be a struct, got %v: %w", location.in, key, t, ErrGraphQLGenerationFailed)
+			}
+			for _, field := range GetTypeInfo(reflect.TypeOf(location.value)).Fields {
+				argument := graphQLArgument{name: graphQLName(field.Name), in: location.in, key: field.Name}
+				if _, ok := arguments[argument.name]; ok {
+					return nil, fmt.Errorf("argument %s of %s is declared more than once: %w", argument.name, key, ErrGraphQLGenerationFailed)
+				}
+				arguments[argument.name] = struct{}{}
+				operation.arguments = append(operation.arguments, argument)
+			}
+		}
+		if endpoint.Method != http.MethodGet && firstPayload(endpoint.Payload) != nil {
+			if _, ok := arguments[graphQLInputArgument]; ok {
+				return nil, fmt.Errorf("argument %s of %s is declared more than once: %w", graphQLInputArgument, key, ErrGraphQLGenerationFailed)
+			}
+			operation.arguments = append(operation.arguments, graphQLArgument{name: graphQLInputArgument, in: "body"})
+		}
+		operations = append(operations, operation)
+	}
+	if err := r.requireStableOperationIDs(endpoints); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrGraphQLGenerationFailed)
+	}
+	return operations, nil
+}
+
+// graphQLName converts a json name into a valid graphql name.
+func graphQLName(name string) string {
+	if graphQLNameRegex.MatchString(name) {
+		return name
+	}
+	if name = goIdentifier(name, false); name == "" {
+		return "_"
+	}
+	if !graphQLNameRegex.MatchString(name) {
+		return "_" + name
+	}
+	return name
+}
+
+// graphQLTypes collects the object, input and enum types declared for go types.
+type graphQLTypes struct {
+	schemas         openapi3.Schemas
+	schemaGenerator *SchemaRefGenerator
+
+	objects     map[reflect.Type]string
+	inputs      map[reflect.Type]string
+	taken       map[string]struct{}
+	definitions map[string]string
+	scalars     map[string]struct{}
+}
+
+// GenerateGraphQL writes the graphql schema (SDL) of the endpoints matching all filters. GET operations
+// become fields of Query and all other operations fields of Mutation, named by their operation ID,
+// which must be stable (see MethodPathOperationIDGenerator). The properties of Parameters and Query
+// become arguments, the payload becomes the input argument and the success response the type of the
+// field. Structs are declared as object types in responses and as input types in arguments. Integers
+// exceeding 32 bits are declared as the Int64 and UInt64 scalars, as Int is a 32 bit integer.
+func (r *registry[T]) GenerateGraphQL(w io.Writer, filters ...EndpointFilter[T]) error {
+	operations, err := graphQLOperations(r, filters)
+	if err != nil {
+		return err
+	}
+
+	types := &graphQLTypes{
+		schemas:         make(openapi3.Schemas),
+		schemaGenerator: NewSchemaRefGenerator(WithTypeInfoCache(NewTypeInfoCache())),
+		objects:         map[reflect.Type]string{},
+		inputs:          map[reflect.Type]string{},
+		taken:           map[string]struct{}{graphQLQuery: {}, graphQLMutation: {}},
+		definitions:     map[string]string{},
+		scalars:         map[string]struct{}{},
+	}
+
+	fields := map[string]*strings.Builder{}
+	for _, operation := range operations {
+		field, err := graphQLField(types, operation)
+		if err != nil {
+			return fmt.Errorf("failed to generate %s %s: %w", operation.endpoint.Method, operation.endpoint.Path, err)
+		}
+		if fields[operation.typeName] == nil {
+			fields[operation.typeName] = new(strings.Builder)
+		}
+		fields[operation.typeName].WriteString(field)
+	}
+	if fields[graphQLQuery] == nil {
+		return fmt.Errorf("a schema requires at least one GET operation: %w", ErrGraphQLGenerationFailed)
+	}
+
+	b := new(strings.Builder)
+	b.WriteString("# Code generated by github.com/jakoblorz/specs. DO NOT EDIT.\n")
+	for _, scalar := range sortedKeys(types.scalars) {
+		fmt.Fprintf(b, "\nscalar %s\n", scalar)
+	}
+	for _, typeName := range []string{graphQLQuery, graphQLMutation} {
+		if fields[typeName] != nil {
+			fmt.Fprintf(b, "\ntype %s {\n%s}\n", typeName, fields[typeName].String())
+		}
+	}
+	for _, name := range sortedKeys(types.definitions) {
+		b.WriteString("\n" + types.definitions[name])
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// graphQLField returns the Query or Mutation field declaration of the operation.
+func graphQLField[T interface{}](types *graphQLTypes, operation graphQLOperation[T]) (string, error) {
+	endpoint, fieldName := operation.endpoint, operation.fieldName
+
+	b := new(strings.Builder)
+	if text := strings.TrimSpace(strings.Join([]string{endpoint.Title, endpoint.Description}, "\n\n")); text != "" {
+		fmt.Fprintf(b, "  %s\n", graphQLString(text))
+	}
+
+	var arguments []string
+	for _, location := range []struct {
+		value    interface{}
+		required bool
+	}{
+		{endpoint.Parameters, true},
+		{endpoint.Query, false},
+	} {
+		if location.value == nil {
+			continue
+		}
+		t := removeIndirect(reflect.TypeOf(location.value))
+		schema, err := types.schema(t)
+		if err != nil {
+			return "", err
+		}
+		for _, fieldInfo := range GetTypeInfo(t).Fields {
+			property := types.property(schema, fieldInfo.Name)
+			typeExpr, err := types.typeExpr(fieldInfo.Type, property, goIdentifier(fieldName, true)+goIdentifier(fieldInfo.Name, true), true, false)
+			if err != nil {
+				return "", err
+			}
+			// path parameters are always sent, query parameters only if they are required
+			typeExpr = strings.TrimSuffix(typeExpr, "!")
+			if location.required || isRequired(schema, fieldInfo.Name) {
+				typeExpr = nonNull(typeExpr)
+			}
+			arguments = append(arguments, graphQLName(fieldInfo.Name)+": "+typeExpr)
+		}
+	}
+	if payload := firstPayload(endpoint.Payload); endpoint.Method != http.MethodGet && payload != nil {
+		typeExpr, err := types.typeExpr(reflect.TypeOf(payload), nil, goIdentifier(fieldName, true)+"Input", true, true)
+		if err != nil {
+			return "", err
+		}
+		arguments = append(arguments, graphQLInputArgument+": "+nonNull(typeExpr))
+	}
+
+	returns := "Boolean"
+	if response := operation.response; response != nil && response.Value != nil {
+		typeExpr, err := types.typeExpr(reflect.TypeOf(response.Value), nil, goIdentifier(fieldName, true)+"Result", false, false)
+		if err != nil {
+			return "", err
+		}
+		returns = typeExpr
+	}
+
+	fmt.Fprintf(b, "  %s", fieldName)
+	if len(arguments) > 0 {
+		fmt.Fprintf(b, "(%s)", strings.Join(arguments, ", "))
+	}
+	fmt.Fprintf(b, ": %s", returns)
+	if endpoint.Deprecated {
+		b.WriteString(" @deprecated")
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+func nonNull(typeExpr string) string {
+	if strings.HasSuffix(typeExpr, "!") {
+		return typeExpr
+	}
+	return typeExpr + "!"
+}
+
+func isRequired(schema *openapi3.Schema, name string) bool {
+	if schema == nil {
+		return false
+	}
+	for _, required := range schema.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// schema returns the schema generated for t, used to derive enums and required properties.
+func (types *graphQLTypes) schema(t reflect.Type) (*openapi3.Schema, error) {
+	ref, err := types.schemaGenerator.GenerateSchemaRef(reflect.New(t).Elem().Interface(), types.schemas)
+	if err != nil || ref == nil {
+		return nil, err
+	}
+	if ref.Value == nil {
+		if component := types.schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; component != nil {
+			return component.Value, nil
+		}
+	}
+	return ref.Value, nil
+}
+
+func (types *graphQLTypes) property(schema *openapi3.Schema, name string) *openapi3.SchemaRef {
+	if schema == nil {
+		return nil
+	}
+	return schema.Properties[name]
+}
+
+func (types *graphQLTypes) scalar(name string) string {
+	types.scalars[name] = struct{}{}
+	types.taken[name] = struct{}{}
+	return name
+}
+
+func (types *graphQLTypes) allocName(base string) string {
+	name := base
+	for n := 2; ; n++ {
+		if _, ok := types.taken[name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(n)
+	}
+	types.taken[name] = struct{}{}
+	return name
+}
+
+// typeExpr returns the graphql type of t. Pointers are nullable, as are slices and maps unless they are
+// required because nil values are encoded as null. Everything else is non-null. Structs are declared as
+// input types if input is set and as object types otherwise.
+func (types *graphQLTypes) typeExpr(t reflect.Type, property *openapi3.SchemaRef, scope string, input bool, required bool) (string, error) {
+	nullable := t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface ||
+		(!required && (t.Kind() == reflect.Slice || t.Kind() == reflect.Map))
+	expr, err := types.namedTypeExpr(removeIndirect(t), property, scope, input)
+	if err != nil || nullable {
+		return expr, err
+	}
+	return nonNull(expr), nil
+}
+
+func (types *graphQLTypes) namedTypeExpr(t reflect.Type, property *openapi3.SchemaRef, scope string, input bool) (string, error) {
+	switch t {
+	case timeType:
+		return types.scalar("DateTime"), nil
+	case rawMessageType:
+		return types.scalar("JSON"), nil
+	case fileHeaderType, fileType:
+		return types.scalar("Upload"), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "Boolean", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return "Int", nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return types.scalar("Int64"), nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return types.scalar("UInt64"), nil
+	case reflect.Float32, reflect.Float64:
+		return "Float", nil
+	case reflect.String:
+		if property != nil && property.Value != nil && len(property.Value.Enum) > 0 {
+			if enum, ok := types.enum(scope, property.Value.Enum); ok {
+				return enum, nil
+			}
+		}
+		return "String", nil
+	case reflect.Interface, reflect.Map:
+		return types.scalar("JSON"), nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "String", nil
+		}
+		var items *openapi3.SchemaRef
+		if property != nil && property.Value != nil {
+			items = property.Value.Items
+		}
+		elem, err := types.typeExpr(t.Elem(), items, scope, input, false)
+		if err != nil {
+			return "", err
+		}
+		return "[" + elem + "]", nil
+	case reflect.Struct:
+		return types.object(t, scope, input)
+	}
+	return "", fmt.Errorf("type %v is not supported: %w", t, ErrGraphQLGenerationFailed)
+}
+
+// enum declares the enum of the values, it fails if a value is not a valid graphql name.
+func (types *graphQLTypes) enum(name string, values []interface{}) (string, bool) {
+	b := new(strings.Builder)
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok || !graphQLNameRegex.MatchString(s) || s == "true" || s == "false" || s == "null" {
+			return "", false
+		}
+		fmt.Fprintf(b, "  %s\n", s)
+	}
+	// input and object types share the enums of their fields
+	if types.definitions[name] == graphQLEnum(name, b.String()) {
+		return name, true
+	}
+	name = types.allocName(name)
+	types.definitions[name] = graphQLEnum(name, b.String())
+	return name, true
+}
+
+func graphQLEnum(name string, values string) string {
+	return fmt.Sprintf("enum %s {\n%s}\n", name, values)
+}
+
+func (types *graphQLTypes) object(t reflect.Type, scope string, input bool) (string, error) {
+	names, keyword, suffix := types.objects, "type", ""
+	if input {
+		names, keyword, suffix = types.inputs, "input", "Input"
+	}
+	if name, ok := names[t]; ok {
+		return name, nil
+	}
+
+	fields := GetTypeInfo(t).Fields
+	if len(fields) == 0 {
+		return types.scalar("JSON"), nil
+	}
+
+	base := goIdentifier(schemaTypeName(t), true)
+	if t.Name() == "" {
+		base = strings.TrimSuffix(scope, suffix)
+	}
+	name := types.allocName(base + suffix)
+	names[t] = name // reserve to stop recursion
+
+	schema, err := types.schema(t)
+	if err != nil {
+		return "", err
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "%s %s {\n", keyword, name)
+	for _, fieldInfo := range fields {
+		property := types.property(schema, fieldInfo.Name)
+		if schema != nil && schema.Properties != nil && property == nil {
+			continue
+		}
+		typeExpr, err := types.typeExpr(fieldInfo.Type, property, base+goIdentifier(fieldInfo.Name, true), input, isRequired(schema, fieldInfo.Name))
+		if err != nil {
+			return "", fmt.Errorf("field %s of %s: %w", fieldInfo.Name, name, err)
+		}
+		// omitted fields may be missing even if they are not pointers
+		if fieldInfo.JSONOmitEmpty() && !isRequired(schema, fieldInfo.Name) {
+			typeExpr = strings.TrimSuffix(typeExpr, "!")
+		}
+		fmt.Fprintf(b, "  %s: %s\n", graphQLName(fieldInfo.Name), typeExpr)
+	}
+	b.WriteString("}\n")
+	types.definitions[name] = b.String()
+	return name, nil
+}
+
+// graphQLString quotes s as a graphql string, which shares the escape sequences of json.
+func graphQLString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,209 @@
+package specs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type graphQLTestParams struct {
+	ID string `json:"id"`
+}
+
+type graphQLTestQuery struct {
+	Limit int    `json:"limit"`
+	Role  string `json:"role" validate:"required,oneof=admin member"`
+}
+
+type graphQLTestUser struct {
+	ID        string             `json:"id"`
+	FullName  string             `json:"full-name"`
+	Role      string             `json:"role" validate:"oneof=admin member"`
+	Nickname  *string            `json:"nickname"`
+	Tags      []string           `json:"tags,omitempty"`
+	Friends   []*graphQLTestUser `json:"friends"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Meta      map[string]string  `json:"meta"`
+	Emails    []string           `json:"emails" validate:"required"`
+	Logins    int32              `json:"logins"`
+	Views     uint64             `json:"views"`
+}
+
+func TestGenerateGraphQL(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", "").Title("List users").Query(graphQLTestQuery{}).Response(200, []graphQLTestUser{}, "Users found")
+	r.GET("/users/{id}", "").Parameters(graphQLTestParams{}).Response(200, graphQLTestUser{}, "User found").Deprecated()
+	r.PUT("/users/{id}", "").Parameters(graphQLTestParams{}).Payload(graphQLTestUser{}).Response(204, nil, "User updated")
+	r.GET("/exports", "").Stream(200, Event("done", graphQLTestParams{}))
+	r.Channel(ProtocolWebSocket, "/rooms", "").Inbound("join", graphQLTestParams{})
+
+	b := new(bytes.Buffer)
+	if err := r.GenerateGraphQL(b); err != nil {
+		t.Fatal(err)
+	}
+	sdl := b.String()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "should declare scalars", want: "\nscalar DateTime\n\nscalar Int64\n\nscalar JSON\n\nscalar UInt64\n"},
+		{name: "should declare GET operations as queries", want: "type Query {\n  \"List users\"\n  getUsers(limit: Int64, role: GetUsersRole!): [GraphQLTestUser!]\n  getUsersById(id: String!): GraphQLTestUser! @deprecated\n}\n"},
+		{name: "should declare other operations as mutations", want: "type Mutation {\n  putUsersById(id: String!, input: GraphQLTestUserInput!): Boolean\n}\n"},
+		{name: "should declare object types", want: "type GraphQLTestUser {\n  createdAt: DateTime!\n  emails: [String!]!\n  friends: [GraphQLTestUser]\n  fullName: String!\n  id: String!\n  logins: Int!\n  meta: JSON\n  nickname: String\n  role: GraphQLTestUserRole!\n  tags: [String!]\n  views: UInt64!\n}\n"},
+		{name: "should declare input types", want: "input GraphQLTestUserInput {\n"},
+		{name: "should declare enums", want: "enum GraphQLTestUserRole {\n  admin\n  member\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(sdl, tt.want) {
+				t.Errorf("got\n%s\nwant it to contain\n%s", sdl, tt.want)
+			}
+		})
+	}
+
+	t.Run("should declare all referenced types", func(t *testing.T) {
+		declared := map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+		for _, match := range regexp.MustCompile(`(?m)^(?:type|input|enum|scalar) (\w+)`).FindAllStringSubmatch(sdl, -1) {
+			declared[match[1]] = true
+		}
+		for _, match := range regexp.MustCompile(`:\s*\[*(\w+)`).FindAllStringSubmatch(sdl, -1) {
+			if !declared[match[1]] {
+				t.Errorf("type %s is not declared in\n%s", match[1], sdl)
+			}
+		}
+	})
+	t.Run("should skip channels and streams", func(t *testing.T) {
+		if strings.Contains(sdl, "Rooms") || strings.Contains(sdl, "Exports") {
+			t.Errorf("got\n%s", sdl)
+		}
+	})
+}
+
+func TestGenerateGraphQLFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *registry[string])
+	}{
+		{
+			name: "should reject random operation IDs",
+			register: func(r *registry[string]) {
+				r.GET("/users", "").Response(200, []graphQLTestUser{}, "")
+			},
+		},
+		{
+			name: "should require a query",
+			register: func(r *registry[string]) {
+				r.POST("/users", "").OperationID("createUser").Response(201, graphQLTestUser{}, "")
+			},
+		},
+		{
+			name: "should reject duplicate arguments",
+			register: func(r *registry[string]) {
+				r.GET("/users/{id}", "").OperationID("getUser").Parameters(graphQLTestParams{}).Query(graphQLTestParams{}).Response(200, graphQLTestUser{}, "")
+			},
+		},
+		{
+			name: "should reject unsupported types",
+			register: func(r *registry[string]) {
+				r.GET("/users", "").OperationID("getUsers").Response(200, struct {
+					C complex64 `json:"c"`
+				}{}, "")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry[string]()
+			tt.register(r)
+			if err := r.GenerateGraphQL(new(bytes.Buffer)); !errors.Is(err, ErrGraphQLGenerationFailed) {
+				t.Errorf("got %v, want %v", err, ErrGraphQLGenerationFailed)
+			}
+		})
+	}
+}
+
+func TestGraphQLResolvers(t *testing.T) {
+	r := NewRegistry[http.Handler](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"id": req.URL.Query().Get("role"), "full-name": req.URL.Query().Get("limit")}})
+	})).Query(graphQLTestQuery{}).Response(200, []graphQLTestUser{}, "Users found")
+	r.GET("/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/users/1" {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "1", "full-name": "Jane", "friends": []interface{}{map[string]interface{}{"full-name": "John"}}})
+	})).Parameters(graphQLTestParams{}).Response(200, graphQLTestUser{}, "User found")
+	r.PUT("/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var user map[string]interface{}
+		json.NewDecoder(req.Body).Decode(&user)
+		if user["full-name"] != "Jane" || req.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected body", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})).Parameters(graphQLTestParams{}).Payload(graphQLTestUser{}).Response(204, nil, "User updated")
+	r.GET("/exports", nil).Stream(200, Event("done", graphQLTestParams{}))
+
+	resolvers, err := NewGraphQLResolvers(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolvers["Query"]) != 2 || len(resolvers["Mutation"]) != 1 {
+		t.Fatalf("got %v", resolvers)
+	}
+
+	tests := []struct {
+		name     string
+		resolver GraphQLResolver
+		args     map[string]interface{}
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "should send query arguments",
+			resolver: resolvers["Query"]["getUsers"],
+			args:     map[string]interface{}{"role": "admin", "limit": 10},
+			want:     `[{"fullName":"10","id":"admin"}]`,
+		},
+		{
+			name:     "should send path arguments and rename keys",
+			resolver: resolvers["Query"]["getUsersById"],
+			args:     map[string]interface{}{"id": "1"},
+			want:     `{"friends":[{"fullName":"John"}],"fullName":"Jane","id":"1"}`,
+		},
+		{
+			name:     "should send the input as body",
+			resolver: resolvers["Mutation"]["putUsersById"],
+			args:     map[string]interface{}{"id": "1", "input": map[string]interface{}{"fullName": "Jane"}},
+			want:     `true`,
+		},
+		{
+			name:     "should return errors",
+			resolver: resolvers["Query"]["getUsersById"],
+			args:     map[string]interface{}{"id": "2"},
+			wantErr:  ErrGraphQLResolveFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resolver(context.Background(), tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if b, _ := json.Marshal(got); string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
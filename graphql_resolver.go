@@ -0,0 +1,149 @@
+package specs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+)
+
+var (
+	ErrGraphQLResolveFailed = errors.New("graphql resolve failed")
+)
+
+// GraphQLResolver resolves a Query or Mutation field from its arguments, see NewGraphQLResolvers.
+type GraphQLResolver func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+
+// NewGraphQLResolvers returns a resolver for each field of the schema written by GenerateGraphQL, by
+// type (Query or Mutation) and field name. A resolver builds the request of the endpoint from the
+// arguments, serves it with the registered handler in-process and returns the decoded json response.
+// Responses with a status other than 2xx are returned as errors.
+func NewGraphQLResolvers(r *registry[http.Handler], filters ...EndpointFilter[http.Handler]) (map[string]map[string]GraphQLResolver, error) {
+	operations, err := graphQLOperations(r, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := map[string]map[string]GraphQLResolver{}
+	for _, operation := range operations {
+		if resolvers[operation.typeName] == nil {
+			resolvers[operation.typeName] = map[string]GraphQLResolver{}
+		}
+		resolvers[operation.typeName][operation.fieldName] = graphQLResolver(operation)
+	}
+	return resolvers, nil
+}
+
+func graphQLResolver(operation graphQLOperation[http.Handler]) GraphQLResolver {
+	endpoint := operation.endpoint
+	path := parsePathTemplate(endpoint.Path)
+	var payloadType, resultType reflect.Type
+	if payload := firstPayload(endpoint.Payload); payload != nil {
+		payloadType = reflect.TypeOf(payload)
+	}
+	if operation.response != nil && operation.response.Value != nil {
+		resultType = reflect.TypeOf(operation.response.Value)
+	}
+
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		params, query := map[string]string{}, url.Values{}
+		var body []byte
+		for _, argument := range operation.arguments {
+			value, ok := args[argument.name]
+			if !ok || value == nil {
+				continue
+			}
+			switch argument.in {
+			case "path":
+				params[argument.key] = fmt.Sprint(value)
+			case "query":
+				if values, ok := value.([]interface{}); ok {
+					for _, v := range values {
+						query.Add(argument.key, fmt.Sprint(v))
+					}
+				} else {
+					query.Set(argument.key, fmt.Sprint(value))
+				}
+			case "body":
+				var err error
+				if body, err = json.Marshal(renameGraphQLKeys(value, payloadType, false)); err != nil {
+					return nil, fmt.Errorf("failed to encode %s: %w", graphQLInputArgument, err)
+				}
+			}
+		}
+
+		target := path.Expand(params)
+		if len(query) > 0 {
+			target += "?" + query.Encode()
+		}
+		req, err := http.NewRequestWithContext(ctx, endpoint.Method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		rec := httptest.NewRecorder()
+		endpoint.Handler.ServeHTTP(rec, req)
+		if rec.Code < 200 || rec.Code >= 300 {
+			return nil, fmt.Errorf("%s %s responded with %d %s: %w", endpoint.Method, target, rec.Code, bytes.TrimSpace(rec.Body.Bytes()), ErrGraphQLResolveFailed)
+		}
+		if resultType == nil {
+			return true, nil
+		}
+
+		var result interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			return nil, fmt.Errorf("failed to decode the response of %s %s: %v: %w", endpoint.Method, target, err, ErrGraphQLResolveFailed)
+		}
+		return renameGraphQLKeys(result, resultType, true), nil
+	}
+}
+
+// renameGraphQLKeys renames the keys of the json value v of type t from json to graphql names or back,
+// see graphQLName. Values of maps and interfaces are declared as JSON scalars and left unchanged.
+func renameGraphQLKeys(v interface{}, t reflect.Type, toGraphQL bool) interface{} {
+	if t == nil {
+		return v
+	}
+	t = removeIndirect(t)
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct || t == timeType {
+			return v
+		}
+		renamed := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			renamed[k] = v
+		}
+		for _, field := range GetTypeInfo(t).Fields {
+			from, to := field.Name, graphQLName(field.Name)
+			if !toGraphQL {
+				from, to = to, from
+			}
+			if v, ok := value[from]; ok {
+				delete(renamed, from)
+				renamed[to] = renameGraphQLKeys(v, field.Type, toGraphQL)
+			}
+		}
+		return renamed
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return v
+		}
+		renamed := make([]interface{}, len(value))
+		for i, v := range value {
+			renamed[i] = renameGraphQLKeys(v, t.Elem(), toGraphQL)
+		}
+		return renamed
+	}
+	return v
+}
@@ -0,0 +1,228 @@
+package specs
+
+import (
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type GroupOption func(*groupOptions)
+
+type groupOptions struct {
+	name       string
+	tags       []string
+	deprecated bool
+	visibility string
+	security   openapi3.SecurityRequirements
+	responses  map[int]Response
+}
+
+// GroupName names the group. Named groups are emitted as x-tagGroups containing the tags of their endpoints.
+func GroupName(name string) GroupOption {
+	return func(o *groupOptions) {
+		o.name = name
+	}
+}
+
+// GroupTags adds the tags to every endpoint of the group.
+func GroupTags(tags ...string) GroupOption {
+	return func(o *groupOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// GroupDeprecated marks every endpoint of the group as deprecated.
+func GroupDeprecated() GroupOption {
+	return func(o *groupOptions) {
+		o.deprecated = true
+	}
+}
+
+// GroupVisibility sets the visibility (e.g. VisibilityInternal) of every endpoint of the group.
+func GroupVisibility(visibility string) GroupOption {
+	return func(o *groupOptions) {
+		o.visibility = visibility
+	}
+}
+
+// GroupSecurity sets the security requirements of every endpoint of the group, replacing the ones of
+// enclosing groups. Endpoints can override them using Builder.Security.
+func GroupSecurity(requirements ...openapi3.SecurityRequirement) GroupOption {
+	return func(o *groupOptions) {
+		o.security = append(openapi3.SecurityRequirements{}, requirements...)
+	}
+}
+
+// GroupResponse declares a default response (e.g. a shared error response) of every endpoint of the group.
+// Endpoints can override it by declaring a response with the same status.
+func GroupResponse(status int, data interface{}, description string, mediaTypes ...string) GroupOption {
+	return func(o *groupOptions) {
+		if o.responses == nil {
+			o.responses = map[int]Response{}
+		}
+		mediaTypes = safeMediaTypes(mediaTypes)
+		o.responses[status] = Response{
+			Description: description,
+			MediaType:   mediaTypes[0],
+			MediaTypes:  mediaTypes,
+			Value:       data,
+		}
+	}
+}
+
+type group[T interface{}] struct {
+	groupOptions
+
+	parent     *group[T]
+	prefix     string
+	middleware []T
+}
+
+// chain returns the groups from the outermost to the innermost one.
+func (g *group[T]) chain() []*group[T] {
+	var groups []*group[T]
+	for current := g; current != nil; current = current.parent {
+		groups = append([]*group[T]{current}, groups...)
+	}
+	return groups
+}
+
+// apply applies the inherited properties of all enclosing groups to the endpoint.
+func (g *group[T]) apply(e *Endpoint[T]) (inheritedResponses map[int]struct{}) {
+	inheritedResponses = map[int]struct{}{}
+
+	var prefix string
+	for _, current := range g.chain() {
+		prefix = joinPath(prefix, current.prefix)
+
+		if current.name != "" {
+			e.Groups = append(e.Groups, current.name)
+		}
+		e.Deprecated = e.Deprecated || current.deprecated
+		if current.visibility != "" {
+			e.Visibility = current.visibility
+		}
+		if current.security != nil {
+			e.Security = current.security
+		}
+		for status, response := range current.responses {
+			if e.Response == nil {
+				e.Response = map[int]Response{}
+			}
+			e.Response[status] = response
+			inheritedResponses[status] = struct{}{}
+		}
+		e.Middleware = append(e.Middleware, current.middleware...)
+	}
+	e.Path = joinPath(prefix, e.Path)
+	e.Tags = g.mergeTags(e.Tags)
+	return
+}
+
+// mergeTags returns the tags of all enclosing groups followed by the tags of the endpoint.
+func (g *group[T]) mergeTags(tags []string) []string {
+	var inherited []string
+	for _, current := range g.chain() {
+		inherited = appendUnique(inherited, current.tags...)
+	}
+	if len(inherited) == 0 {
+		return tags
+	}
+	return appendUnique(inherited, tags...)
+}
+
+func joinPath(prefix string, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if path == "" {
+		return prefix
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return prefix + path
+}
+
+func appendUnique(values []string, additional ...string) []string {
+	for _, value := range additional {
+		exists := false
+		for _, v := range values {
+			if v == value {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Group returns a sub-registry whose endpoints inherit the path prefix, tags, deprecation, visibility, security,
+// default responses and middleware of the group. Groups can be nested.
+func (r *registry[T]) Group(prefix string, opts ...GroupOption) *registry[T] {
+	g := &group[T]{
+		parent: r.group,
+		prefix: prefix,
+	}
+	for _, applyOption := range opts {
+		applyOption(&g.groupOptions)
+	}
+
+	sub := *r
+	sub.group = g
+	return &sub
+}
+
+// Use adds framework middleware to all endpoints registered afterwards on this registry or its groups.
+func (r *registry[T]) Use(middleware ...T) *registry[T] {
+	r.group.middleware = append(r.group.middleware, middleware...)
+	return r
+}
+
+// TagDescription documents a tag. The description is emitted in the tags section by Annotate.
+func (r *registry[T]) TagDescription(tag string, description string) *registry[T] {
+	r.meta.tagDescriptions[tag] = description
+	return r
+}
+
+// annotateTags emits the tags of the endpoints along with their descriptions and the x-tagGroups of named groups.
+func annotateTags[T interface{}](t *openapi3.T, endpoints []*Endpoint[T], tagDescriptions map[string]string) {
+	var (
+		tags      []string
+		groups    []string
+		groupTags = map[string][]string{}
+	)
+	for _, endpoint := range endpoints {
+		tags = appendUnique(tags, endpoint.Tags...)
+		for _, name := range endpoint.Groups {
+			groups = appendUnique(groups, name)
+			groupTags[name] = appendUnique(groupTags[name], endpoint.Tags...)
+		}
+	}
+
+	for _, tag := range tags {
+		if t.Tags.Get(tag) != nil {
+			continue
+		}
+		t.Tags = append(t.Tags, &openapi3.Tag{
+			Name:        tag,
+			Description: tagDescriptions[tag],
+		})
+	}
+
+	if len(groups) == 0 {
+		return
+	}
+	tagGroups := make([]map[string]interface{}, 0, len(groups))
+	for _, name := range groups {
+		tagGroups = append(tagGroups, map[string]interface{}{
+			"name": name,
+			"tags": groupTags[name],
+		})
+	}
+	if t.Extensions == nil {
+		t.Extensions = map[string]interface{}{}
+	}
+	t.Extensions["x-tagGroups"] = tagGroups
+}
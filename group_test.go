@@ -0,0 +1,86 @@
+package specs
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestRegistry_Group(t *testing.T) {
+	type errorResponse struct {
+		Message string `json:"message"`
+	}
+
+	r := NewRegistry[string]()
+	r.Use("logger")
+
+	api := r.Group("/api", GroupTags("api"), GroupResponse(500, errorResponse{}, "Internal error"))
+	users := api.Group("/users/", GroupName("Users"), GroupTags("users"), GroupSecurity(openapi3.SecurityRequirement{"bearer": {}}))
+	users.Use("auth")
+
+	list := users.GET("", "list").Build()
+	get := users.GET("/{id}", "get").
+		Tags("details").
+		Response(500, nil, "Overridden").
+		Build()
+	admin := api.Group("/admin", GroupDeprecated()).DELETE("/cache", "purge").Build()
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "should join prefixes", got: list.Path, want: "/api/users"},
+		{name: "should join nested prefixes", got: get.Path, want: "/api/users/{id}"},
+		{name: "should inherit and append tags", got: get.Tags, want: []string{"api", "users", "details"}},
+		{name: "should inherit middleware in order", got: get.Middleware, want: []string{"logger", "auth"}},
+		{name: "should inherit security", got: list.Security, want: openapi3.SecurityRequirements{{"bearer": {}}}},
+		{name: "should inherit default responses", got: list.Response[500].Description, want: "Internal error"},
+		{name: "should allow overriding default responses", got: get.Response[500].Description, want: "Overridden"},
+		{name: "should inherit deprecation", got: admin.Deprecated, want: true},
+		{name: "should not leak middleware into sibling groups", got: admin.Middleware, want: []string{"logger"}},
+		{name: "should record named groups", got: get.Groups, want: []string{"Users"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.TagDescription("users", "Manage users")
+	r.Annotate(doc)
+
+	if tag := doc.Tags.Get("users"); tag == nil || tag.Description != "Manage users" {
+		t.Errorf("tag users = %v, want description", tag)
+	}
+	tagGroups, _ := doc.Extensions["x-tagGroups"].([]map[string]interface{})
+	if len(tagGroups) != 1 || tagGroups[0]["name"] != "Users" || !reflect.DeepEqual(tagGroups[0]["tags"], []string{"api", "users", "details"}) {
+		t.Errorf("x-tagGroups = %v", doc.Extensions["x-tagGroups"])
+	}
+	if operation := doc.Paths.Find("/api/users").GetOperation(http.MethodGet); operation.Security == nil {
+		t.Errorf("security of %s not annotated", list.Path)
+	}
+}
+
+func TestBuilder_Tags(t *testing.T) {
+	r := NewRegistry[string]()
+	users := r.Group("/users", GroupTags("users"))
+
+	t.Run("should replace the tags of ungrouped endpoints", func(t *testing.T) {
+		got := r.GET("/health", "").Tags("details").Tags("health").Build().Tags
+		if want := []string{"health"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+	t.Run("should keep the tags of groups", func(t *testing.T) {
+		got := users.GET("/{id}", "").Tags("details").Tags("media").Build().Tags
+		if want := []string{"users", "media"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jakoblorz/specs"
+)
+
+const (
+	OpenRPCVersion = "1.2.6"
+)
+
+// OpenRPC is an OpenRPC document in its generic json representation.
+type OpenRPC map[string]interface{}
+
+type openRPCDocument struct {
+	OpenRPC    string             `json:"openrpc"`
+	Info       *openapi3.Info     `json:"info"`
+	Methods    []*openRPCMethod   `json:"methods"`
+	Components *openRPCComponents `json:"components,omitempty"`
+}
+
+type openRPCComponents struct {
+	Schemas openapi3.Schemas `json:"schemas,omitempty"`
+}
+
+type openRPCMethod struct {
+	Name           string               `json:"name"`
+	Summary        string               `json:"summary,omitempty"`
+	Description    string               `json:"description,omitempty"`
+	Tags           []map[string]string  `json:"tags,omitempty"`
+	Deprecated     bool                 `json:"deprecated,omitempty"`
+	ParamStructure string               `json:"paramStructure,omitempty"`
+	Params         []*openRPCDescriptor `json:"params"`
+	Result         *openRPCDescriptor   `json:"result,omitempty"`
+}
+
+type openRPCDescriptor struct {
+	Name     string              `json:"name"`
+	Required bool                `json:"required,omitempty"`
+	Schema   *openapi3.SchemaRef `json:"schema"`
+}
+
+// OpenRPC returns the OpenRPC document of the methods matching all filters. The properties of struct
+// params are documented as params by name.
+func (s *Server) OpenRPC(info *openapi3.Info, filters ...specs.EndpointFilter[Handler]) (OpenRPC, error) {
+	schemas := make(openapi3.Schemas)
+	schemaGenerator := specs.NewSchemaRefGenerator()
+
+	registry := s.registry.Eject()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	doc := &openRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    info,
+		Methods: []*openRPCMethod{},
+	}
+	for _, name := range names {
+		endpoint := registry[name]
+		if !matchesFilters(endpoint, filters) {
+			continue
+		}
+
+		method := &openRPCMethod{
+			Name:        name,
+			Summary:     endpoint.Title,
+			Description: endpoint.Description,
+			Deprecated:  endpoint.Deprecated,
+			Params:      []*openRPCDescriptor{},
+		}
+		for _, tag := range endpoint.Tags {
+			method.Tags = append(method.Tags, map[string]string{"name": tag})
+		}
+
+		if len(endpoint.Payload) > 0 && endpoint.Payload[0].Value != nil {
+			params := endpoint.Payload[0].Value
+			paramsRef, err := schemaGenerator.GenerateSchemaRef(params, schemas)
+			if err != nil {
+				return nil, fmt.Errorf("method %s: %w", name, err)
+			}
+			if t := reflect.TypeOf(params); t.Kind() == reflect.Struct || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct) {
+				paramsSchema := paramsRef.Value
+				if paramsSchema == nil {
+					paramsSchema = schemas[strings.TrimPrefix(paramsRef.Ref, "#/components/schemas/")].Value
+				}
+				required := map[string]bool{}
+				for _, property := range paramsSchema.Required {
+					required[property] = true
+				}
+				properties := make([]string, 0, len(paramsSchema.Properties))
+				for property := range paramsSchema.Properties {
+					properties = append(properties, property)
+				}
+				sort.Strings(properties)
+				for _, property := range properties {
+					method.Params = append(method.Params, &openRPCDescriptor{
+						Name:     property,
+						Required: required[property],
+						Schema:   paramsSchema.Properties[property],
+					})
+				}
+				method.ParamStructure = "by-name"
+			} else {
+				method.Params = append(method.Params, &openRPCDescriptor{
+					Name:     "params",
+					Required: true,
+					Schema:   paramsRef,
+				})
+			}
+		}
+
+		if response, ok := endpoint.Response[http.StatusOK]; ok && response.Value != nil {
+			resultRef, err := schemaGenerator.GenerateSchemaRef(response.Value, schemas)
+			if err != nil {
+				return nil, fmt.Errorf("method %s: %w", name, err)
+			}
+			method.Result = &openRPCDescriptor{
+				Name:   "result",
+				Schema: resultRef,
+			}
+		}
+		doc.Methods = append(doc.Methods, method)
+	}
+	if len(schemas) > 0 {
+		doc.Components = &openRPCComponents{Schemas: schemas}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal openrpc document: %w", err)
+	}
+	openRPC := OpenRPC{}
+	if err := json.Unmarshal(b, &openRPC); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal openrpc document: %w", err)
+	}
+	return openRPC, nil
+}
+
+func matchesFilters(e *specs.Endpoint[Handler], filters []specs.EndpointFilter[Handler]) bool {
+	for _, filter := range filters {
+		if !filter(e) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,287 @@
+// Package jsonrpc serves JSON-RPC 2.0 methods registered on a specs registry.
+//
+// Methods are registered with typed params and results using Method, which returns the builder of
+// the method to document it. The server dispatches single and batch requests over HTTP (see
+// ServeHTTP) and newline-delimited streams such as stdio (see ServeStream), and describes its methods
+// as an OpenRPC document (see OpenRPC).
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/jakoblorz/specs"
+)
+
+var (
+	ErrMethodAnnotationFailed = errors.New("method annotation failed")
+)
+
+const (
+	Version  = "2.0"
+	Protocol = "jsonrpc"
+)
+
+// Handler is the untyped handler of a method, see Method.
+type Handler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+type methodRegistry interface {
+	Build(method string, path string, handler Handler) specs.Builder[Handler]
+	Eject() specs.Registry[Handler]
+}
+
+type Option func(*options)
+
+type options struct {
+	validate specs.StructValidator
+}
+
+// Validator validates decoded params using validate in addition to their schema, e.g. to check the
+// validate tags of the params.
+func Validator(validate specs.StructValidator) Option {
+	return func(o *options) {
+		o.validate = validate
+	}
+}
+
+type Server struct {
+	registry methodRegistry
+	schemas  *specs.SchemaValidator
+	validate specs.StructValidator
+}
+
+func NewServer(opts ...Option) *Server {
+	o := &options{}
+	for _, applyOption := range opts {
+		applyOption(o)
+	}
+
+	return &Server{
+		// the operation ID of a method is its name, which is registered as the path
+		registry: specs.NewRegistry[Handler](specs.OperationIDGenerator(func(method string, path string) string {
+			return strings.TrimPrefix(path, "/")
+		})),
+		schemas:  specs.NewSchemaValidator(),
+		validate: o.validate,
+	}
+}
+
+// Registry returns the registered methods by name.
+func (s *Server) Registry() specs.Registry[Handler] {
+	return s.registry.Eject()
+}
+
+// Method registers the method name, whose params are validated against the schema of P and decoded
+// into P before handler is called. Params which do not match the schema, cannot be decoded or do not
+// pass the Validator are answered with CodeInvalidParams.
+func Method[P interface{}, R interface{}](s *Server, name string, handler func(ctx context.Context, params P) (R, error)) specs.Builder[Handler] {
+	if name == "" || strings.HasPrefix(name, "rpc.") {
+		panic(fmt.Errorf("invalid method name %q: %w", name, ErrMethodAnnotationFailed))
+	}
+	if _, ok := s.registry.Eject()[name]; ok {
+		panic(fmt.Errorf("method %s already defined: %w", name, ErrMethodAnnotationFailed))
+	}
+
+	var (
+		params P
+		result R
+	)
+	t := reflect.TypeOf((*P)(nil)).Elem()
+	isStruct := t.Kind() == reflect.Struct || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct)
+	return s.registry.Build(http.MethodPost, name, func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		// omitted params of structs are decoded as an empty object, hence pointers are allocated and
+		// required properties are reported as missing
+		if len(raw) == 0 && isStruct {
+			raw = json.RawMessage("{}")
+		}
+		value := reflect.New(t)
+		if len(raw) > 0 {
+			if err := s.schemas.ValidateJSON(t, raw); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			}
+			if err := json.Unmarshal(raw, value.Interface()); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			}
+		}
+		if elem := value.Elem(); s.validate != nil && (elem.Kind() == reflect.Struct || (elem.Kind() == reflect.Ptr && !elem.IsNil() && elem.Elem().Kind() == reflect.Struct)) {
+			if err := s.validate.Struct(elem.Interface()); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			}
+		}
+		return handler(ctx, value.Elem().Interface().(P))
+	}).
+		Protocol(Protocol).
+		Payload(params).
+		Response(http.StatusOK, result, "Result")
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Handle answers the single or batch request. The response is nil if the request only consists of
+// notifications.
+func (s *Server) Handle(ctx context.Context, body []byte) []byte {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return marshalResponse(errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error", Data: err.Error()}))
+		}
+		if len(batch) == 0 {
+			return marshalResponse(errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid Request", Data: "empty batch"}))
+		}
+		var responses []*response
+		for _, raw := range batch {
+			if res := s.handleRequest(ctx, raw); res != nil {
+				responses = append(responses, res)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return marshalResponse(responses)
+	}
+
+	if res := s.handleRequest(ctx, body); res != nil {
+		return marshalResponse(res)
+	}
+	return nil
+}
+
+func (s *Server) handleRequest(ctx context.Context, raw json.RawMessage) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error", Data: err.Error()})
+		}
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid Request", Data: err.Error()})
+	}
+	if req.JSONRPC != Version || req.Method == "" || !validID(req.ID) {
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid Request"})
+	}
+	isNotification := req.ID == nil
+
+	endpoint, ok := s.registry.Eject()[req.Method]
+	if !ok {
+		if isNotification {
+			return nil
+		}
+		return errorResponse(req.ID, &Error{Code: CodeMethodNotFound, Message: "Method not found", Data: req.Method})
+	}
+
+	result, err := endpoint.Handler(ctx, req.Params)
+	if isNotification {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: "Internal error", Data: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	return &response{JSONRPC: Version, Result: result, ID: req.ID}
+}
+
+// validID reports whether the id is absent, a string, a number or null.
+func validID(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	switch id[0] {
+	case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+	return false
+}
+
+func errorResponse(id json.RawMessage, err *Error) *response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: Version, Error: err, ID: id}
+}
+
+func marshalResponse(v interface{}) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(errorResponse(nil, &Error{Code: CodeInternalError, Message: "Internal error", Data: err.Error()}))
+	}
+	return b
+}
+
+// ServeHTTP answers JSON-RPC requests sent as the body of POST requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := s.Handle(req.Context(), body)
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// ServeStream answers the requests read from r, e.g. os.Stdin, and writes each response followed by a
+// newline to w until r is exhausted or ctx is done.
+func (s *Server) ServeStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	decoder := json.NewDecoder(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			return nil
+		}
+		var res []byte
+		if err != nil {
+			// the stream cannot be resynchronized after a syntax error
+			res = marshalResponse(errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error", Data: err.Error()}))
+		} else {
+			res = s.Handle(ctx, raw)
+		}
+		if res != nil {
+			if _, err := w.Write(append(res, '\n')); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type testAddParams struct {
+	A int `json:"a" validate:"required"`
+	B int `json:"b"`
+}
+
+type testSum struct {
+	Sum int `json:"sum"`
+}
+
+func newTestServer() *Server {
+	s := NewServer()
+	Method(s, "add", func(ctx context.Context, params testAddParams) (testSum, error) {
+		return testSum{Sum: params.A + params.B}, nil
+	}).Title("Add two numbers").Tags("math")
+	Method(s, "echo", func(ctx context.Context, params []string) ([]string, error) {
+		return params, nil
+	})
+	Method(s, "fail", func(ctx context.Context, params struct{}) (interface{}, error) {
+		return nil, &Error{Code: -32000, Message: "Failed"}
+	})
+	Method(s, "panic", func(ctx context.Context, params struct{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	return s
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "should answer requests",
+			body: `{"jsonrpc":"2.0","method":"add","params":{"a":1,"b":2},"id":1}`,
+			want: `{"jsonrpc":"2.0","result":{"sum":3},"id":1}`,
+		},
+		{
+			name: "should answer requests with params by position",
+			body: `{"jsonrpc":"2.0","method":"echo","params":["a","b"],"id":"x"}`,
+			want: `{"jsonrpc":"2.0","result":["a","b"],"id":"x"}`,
+		},
+		{
+			name: "should not answer notifications",
+			body: `{"jsonrpc":"2.0","method":"add","params":{"a":1}}`,
+			want: ``,
+		},
+		{
+			name: "should answer batches",
+			body: `[{"jsonrpc":"2.0","method":"add","params":{"a":1},"id":1},{"jsonrpc":"2.0","method":"add","params":{"a":1}},{"jsonrpc":"2.0","method":"missing","id":2}]`,
+			want: `[{"jsonrpc":"2.0","result":{"sum":1},"id":1},{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found","data":"missing"},"id":2}]`,
+		},
+		{
+			name: "should not answer batches of notifications",
+			body: `[{"jsonrpc":"2.0","method":"add","params":{"a":1}}]`,
+			want: ``,
+		},
+		{
+			name: "should reject malformed json",
+			body: `{"jsonrpc":"2.0","method"`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error","data":"unexpected end of JSON input"},"id":null}`,
+		},
+		{
+			name: "should reject empty batches",
+			body: `[]`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request","data":"empty batch"},"id":null}`,
+		},
+		{
+			name: "should reject invalid requests",
+			body: `[1]`,
+			want: `[{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request","data":"json: cannot unmarshal number into Go value of type jsonrpc.request"},"id":null}]`,
+		},
+		{
+			name: "should reject requests of other versions",
+			body: `{"jsonrpc":"1.0","method":"add","id":1}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`,
+		},
+		{
+			name: "should reject params of the wrong type",
+			body: `{"jsonrpc":"2.0","method":"add","params":{"a":"1"},"id":1}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"/a: value must be an integer"},"id":1}`,
+		},
+		{
+			name: "should reject invalid params",
+			body: `{"jsonrpc":"2.0","method":"add","params":{"b":1},"id":1}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"/a: property \"a\" is missing"},"id":1}`,
+		},
+		{
+			name: "should answer with errors of handlers",
+			body: `{"jsonrpc":"2.0","method":"fail","id":1}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32000,"message":"Failed"},"id":1}`,
+		},
+		{
+			name: "should answer with internal errors",
+			body: `{"jsonrpc":"2.0","method":"panic","id":1}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32603,"message":"Internal error","data":"boom"},"id":1}`,
+		},
+	}
+	s := newTestServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(s.Handle(context.Background(), []byte(tt.body))); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+type testValidatorFunc func(s interface{}) error
+
+func (f testValidatorFunc) Struct(s interface{}) error {
+	return f(s)
+}
+
+func TestValidator(t *testing.T) {
+	s := NewServer(Validator(testValidatorFunc(func(s interface{}) error {
+		if s.(testAddParams).A < 0 {
+			return errors.New("a must not be negative")
+		}
+		return nil
+	})))
+	Method(s, "add", func(ctx context.Context, params testAddParams) (testSum, error) {
+		return testSum{Sum: params.A + params.B}, nil
+	})
+
+	got := string(s.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"add","params":{"a":-1},"id":1}`)))
+	if want := `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"a must not be negative"},"id":1}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestOmittedParams(t *testing.T) {
+	s := NewServer()
+	var got *testAddParams
+	Method(s, "add", func(ctx context.Context, params *testAddParams) (testSum, error) {
+		got = params
+		return testSum{Sum: params.A + params.B}, nil
+	})
+	Method(s, "sum", func(ctx context.Context, params testAddParams) (testSum, error) {
+		return testSum{Sum: params.A + params.B}, nil
+	})
+	Method(s, "ping", func(ctx context.Context, params *struct{}) (string, error) {
+		if params == nil {
+			return "", errors.New("params not allocated")
+		}
+		return "pong", nil
+	})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "should reject omitted params of pointers with required properties",
+			body: `{"jsonrpc":"2.0","method":"add","id":1}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"/a: property \"a\" is missing"},"id":1}`,
+		},
+		{
+			name: "should reject omitted params with required properties",
+			body: `{"jsonrpc":"2.0","method":"sum","id":1}`,
+			want: `{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"/a: property \"a\" is missing"},"id":1}`,
+		},
+		{
+			name: "should allocate omitted params of pointers",
+			body: `{"jsonrpc":"2.0","method":"ping","id":1}`,
+			want: `{"jsonrpc":"2.0","result":"pong","id":1}`,
+		},
+		{
+			name: "should decode params of pointers",
+			body: `{"jsonrpc":"2.0","method":"add","params":{"a":1,"b":2},"id":1}`,
+			want: `{"jsonrpc":"2.0","result":{"sum":3},"id":1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(s.Handle(context.Background(), []byte(tt.body))); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if got == nil || got.A != 1 {
+		t.Errorf("got params %v", got)
+	}
+}
+
+func TestMethodRejectsInvalidNames(t *testing.T) {
+	for _, name := range []string{"", "rpc.discover", "add"} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrMethodAnnotationFailed) {
+					t.Errorf("got %v, want %v", err, ErrMethodAnnotationFailed)
+				}
+			}()
+			Method(newTestServer(), name, func(ctx context.Context, params struct{}) (struct{}, error) {
+				return struct{}{}, nil
+			})
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	server := httptest.NewServer(newTestServer())
+	defer server.Close()
+
+	res, err := http.Post(server.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"add","params":{"a":1,"b":2},"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body response
+	json.NewDecoder(res.Body).Decode(&body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "application/json" || string(body.ID) != "1" {
+		t.Errorf("got status %d, content type %s, body %+v", res.StatusCode, res.Header.Get("Content-Type"), body)
+	}
+
+	res, err = http.Post(server.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"add","params":{"a":1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("got status %d, want %d", res.StatusCode, http.StatusNoContent)
+	}
+
+	res, err = http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("got status %d, want %d", res.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServeStream(t *testing.T) {
+	in := strings.NewReader(`{"jsonrpc":"2.0","method":"add","params":{"a":1},"id":1}
+{"jsonrpc":"2.0","method":"add","params":{"a":2}}
+[{"jsonrpc":"2.0","method":"add","params":{"a":3},"id":2}]
+`)
+	out := new(bytes.Buffer)
+	if err := newTestServer().ServeStream(context.Background(), in, out); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"jsonrpc":"2.0","result":{"sum":1},"id":1}
+[{"jsonrpc":"2.0","result":{"sum":3},"id":2}]
+`
+	if out.String() != want {
+		t.Errorf("got %s, want %s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := newTestServer().ServeStream(context.Background(), strings.NewReader(`{"jsonrpc"`), out); err == nil || !strings.Contains(out.String(), `"code":-32700`) {
+		t.Errorf("got %v, %s", err, out.String())
+	}
+}
+
+func TestOpenRPC(t *testing.T) {
+	doc, err := newTestServer().OpenRPC(&openapi3.Info{Title: "test", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := json.Marshal(doc)
+	var got struct {
+		OpenRPC string `json:"openrpc"`
+		Methods []struct {
+			Name           string `json:"name"`
+			Summary        string `json:"summary"`
+			ParamStructure string `json:"paramStructure"`
+			Params         []struct {
+				Name     string                 `json:"name"`
+				Required bool                   `json:"required"`
+				Schema   map[string]interface{} `json:"schema"`
+			} `json:"params"`
+			Result struct {
+				Schema map[string]interface{} `json:"schema"`
+			} `json:"result"`
+		} `json:"methods"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.OpenRPC != OpenRPCVersion || len(got.Methods) != 4 {
+		t.Fatalf("got %s", b)
+	}
+	add, echo := got.Methods[0], got.Methods[1]
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "should sort methods by name", got: add.Name + "," + echo.Name, want: "add,echo"},
+		{name: "should document the title", got: add.Summary, want: "Add two numbers"},
+		{name: "should document struct params by name", got: add.ParamStructure, want: "by-name"},
+		{name: "should document required params", got: add.Params[0].Name + "," + add.Params[1].Name, want: "a,b"},
+		{name: "should document required params", got: add.Params[0].Required && !add.Params[1].Required, want: true},
+		{name: "should document the result", got: add.Result.Schema["properties"] != nil, want: true},
+		{name: "should document other params as a single param", got: len(echo.Params) == 1 && echo.Params[0].Schema["type"] == "array", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	preferCodeRegex = regexp.MustCompile(`(?:^|[;,\s])code=(\d{3})`)
+)
+
+type MockServerOption func(*mockServerOptions)
+
+type mockServerOptions struct {
+	seed int64
+}
+
+// MockSeed sets the seed used to synthesize response data. Responses for the
+// same operation are stable across requests for a given seed.
+func MockSeed(seed int64) MockServerOption {
+	return func(o *mockServerOptions) {
+		o.seed = seed
+	}
+}
+
+type mockRoute struct {
+	method    string
+	template  pathTemplate
+	status    int
+	operation *openapi3.Operation
+	responses map[int]Response
+}
+
+type mockServer struct {
+	options mockServerOptions
+	routes  []mockRoute
+	codecs  *Codecs
+}
+
+// NewMockServer returns an http.Handler that serves every endpoint of the registry. Requests
+// are validated against the declared types and answered with a synthesized response of the
+// declared response schema, encoded by the codecs of the registry (DefaultCodecs unless set using
+// WithCodecs). A "Prefer: code=404" header selects a different declared status.
+func NewMockServer[T interface{}](r *registry[T], opts ...MockServerOption) http.Handler {
+	options := &mockServerOptions{
+		seed: 1,
+	}
+	for _, applyOption := range opts {
+		applyOption(options)
+	}
+
+	t := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:   "Mock Server",
+			Version: "0.0.0",
+		},
+	}
+	r.Annotate(t)
+	if err := openapi3.NewLoader().ResolveRefsIn(t, nil); err != nil {
+		panic(fmt.Errorf("failed to resolve mock server references: %w", err))
+	}
+
+	s := &mockServer{options: *options, codecs: r.options.Codecs}
+	if s.codecs == nil {
+		s.codecs = DefaultCodecs
+	}
+	for _, endpoint := range r.routes {
+		pathItem := t.Paths.Find(endpoint.Path)
+		if pathItem == nil {
+			continue
+		}
+		operation := pathItem.GetOperation(endpoint.Method)
+		if operation == nil {
+			continue
+		}
+		s.routes = append(s.routes, mockRoute{
+			method:    endpoint.Method,
+			template:  parsePathTemplate(endpoint.Path),
+			status:    endpoint.Status,
+			operation: operation,
+			responses: endpoint.Response,
+		})
+	}
+
+	// static segments take precedence over parameters
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		return morePathSpecific(s.routes[i].template, s.routes[j].template)
+	})
+	return s
+}
+
+func (s *mockServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var (
+		pathMatched bool
+		route       *mockRoute
+		params      map[string]string
+	)
+	for i := range s.routes {
+		p, ok := s.routes[i].template.Match(req.URL.Path)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if s.routes[i].method == req.Method {
+			route, params = &s.routes[i], p
+			break
+		}
+	}
+	if route == nil {
+		if pathMatched {
+			writeMockError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method))
+			return
+		}
+		writeMockError(w, http.StatusNotFound, fmt.Errorf("no endpoint matches %s %s", req.Method, req.URL.Path))
+		return
+	}
+
+	if status, err := validateMockRequest(route.operation, req, params); err != nil {
+		writeMockError(w, status, err)
+		return
+	}
+
+	status, err := s.selectStatus(route, req)
+	if err != nil {
+		writeMockError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response := route.operation.Responses[strconv.Itoa(status)]
+	if response == nil || response.Value == nil || len(response.Value.Content) == 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	mediaTypes := make([]string, 0, len(response.Value.Content))
+	for mediaType := range response.Value.Content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	mediaType := mediaTypes[0]
+	content := response.Value.Content[mediaType]
+
+	var body interface{}
+	switch {
+	case content.Example != nil:
+		body = content.Example
+	case len(content.Examples) > 0:
+		names := make([]string, 0, len(content.Examples))
+		for name := range content.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if example := content.Examples[names[0]]; example != nil && example.Value != nil {
+			body = example.Value.Value
+		}
+	default:
+		body = NewSchemaFaker(s.options.seed).Fake(content.Schema)
+	}
+
+	codec, ok := s.codecs.Lookup(mediaType)
+	if !ok {
+		writeMockError(w, http.StatusNotAcceptable, fmt.Errorf("no codec for %s", mediaType))
+		return
+	}
+	b := new(bytes.Buffer)
+	if err := encodeMockBody(b, codec, body, route.responses[status].Value); err != nil {
+		writeMockError(w, http.StatusNotAcceptable, fmt.Errorf("failed to encode %s: %v", mediaType, err))
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	w.Write(b.Bytes())
+}
+
+// encodeMockBody encodes the synthesized body using the codec. Codecs other than JSON encode Go
+// values only (e.g. XML), hence the body is converted into the declared type first.
+func encodeMockBody(b *bytes.Buffer, codec Codec, body interface{}, declared interface{}) error {
+	if _, ok := codec.(JSONCodec); ok || declared == nil {
+		return codec.Encode(b, body)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	v := reflect.New(reflect.TypeOf(declared))
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return err
+	}
+	return codec.Encode(b, v.Elem().Interface())
+}
+
+func (s *mockServer) selectStatus(route *mockRoute, req *http.Request) (int, error) {
+	if match := preferCodeRegex.FindStringSubmatch(req.Header.Get("Prefer")); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		if _, ok := route.operation.Responses[match[1]]; !ok {
+			return 0, fmt.Errorf("status code %d is not declared for %s %s", status, route.method, route.template.Raw)
+		}
+		return status, nil
+	}
+
+	if route.status != 0 {
+		if _, ok := route.operation.Responses[strconv.Itoa(route.status)]; ok {
+			return route.status, nil
+		}
+	}
+
+	statuses := make([]int, 0, len(route.operation.Responses))
+	for code := range route.operation.Responses {
+		if status, err := strconv.Atoi(code); err == nil {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) == 0 {
+		return http.StatusNoContent, nil
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		if status >= 200 && status < 300 {
+			return status, nil
+		}
+	}
+	return statuses[0], nil
+}
+
+func validateMockRequest(operation *openapi3.Operation, req *http.Request, params map[string]string) (int, error) {
+	query := req.URL.Query()
+	for _, parameterRef := range operation.Parameters {
+		parameter := parameterRef.Value
+		if parameter == nil || parameter.Schema == nil || parameter.Schema.Value == nil {
+			continue
+		}
+
+		var raw []string
+		switch parameter.In {
+		case openapi3.ParameterInPath:
+			if v, ok := params[parameter.Name]; ok {
+				raw = []string{v}
+			}
+		case openapi3.ParameterInQuery:
+			raw = query[parameter.Name]
+		case openapi3.ParameterInHeader:
+			raw = req.Header.Values(parameter.Name)
+		}
+
+		if len(raw) == 0 {
+			if parameter.Required {
+				return http.StatusBadRequest, fmt.Errorf("%s parameter %s is required", parameter.In, parameter.Name)
+			}
+			continue
+		}
+
+		value, err := coerceParameterValue(parameter.Schema.Value, raw)
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("%s parameter %s: %w", parameter.In, parameter.Name, err)
+		}
+		if err := parameter.Schema.Value.VisitJSON(value); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("%s parameter %s: %w", parameter.In, parameter.Name, err)
+		}
+	}
+
+	if operation.RequestBody == nil || operation.RequestBody.Value == nil || len(operation.RequestBody.Value.Content) == 0 {
+		return 0, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return http.StatusUnsupportedMediaType, fmt.Errorf("invalid content type: %w", err)
+	}
+	content := operation.RequestBody.Value.Content.Get(mediaType)
+	if content == nil {
+		return http.StatusUnsupportedMediaType, fmt.Errorf("unsupported media type %s", mediaType)
+	}
+	if content.Schema == nil || content.Schema.Value == nil || !strings.Contains(mediaType, "json") {
+		return 0, nil
+	}
+
+	var body interface{}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err)
+	}
+	if err := content.Schema.Value.VisitJSON(body); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err)
+	}
+	return 0, nil
+}
+
+// coerceParameterValue converts the raw string values of a path, query or header parameter
+// into the json representation described by the schema.
+func coerceParameterValue(schema *openapi3.Schema, raw []string) (interface{}, error) {
+	if schema.Type == "array" {
+		if len(raw) == 1 {
+			raw = strings.Split(raw[0], ",")
+		}
+		values := make([]interface{}, 0, len(raw))
+		for _, r := range raw {
+			var itemSchema *openapi3.Schema
+			if schema.Items != nil {
+				itemSchema = schema.Items.Value
+			}
+			if itemSchema == nil {
+				values = append(values, r)
+				continue
+			}
+			value, err := coerceParameterValue(itemSchema, []string{r})
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	value := raw[0]
+	switch schema.Type {
+	case "integer", "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a %s", value, schema.Type)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+func writeMockError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": err.Error(),
+	})
+}
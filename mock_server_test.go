@@ -0,0 +1,115 @@
+package specs
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockTestUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email"`
+}
+
+type mockTestParameters struct {
+	ID int `json:"id" validate:"required"`
+}
+
+type mockTestError struct {
+	Message string `json:"message"`
+}
+
+func newMockTestServer() http.Handler {
+	r := NewRegistry[http.HandlerFunc]()
+	r.GET("/users/{id}", nil).
+		Parameters(mockTestParameters{}).
+		Response(200, mockTestUser{}, "User found").
+		Response(404, mockTestError{}, "User not found")
+	r.POST("/users", nil).
+		Payload(mockTestUser{}).
+		Response(201, mockTestUser{}, "User created")
+	return NewMockServer(r, MockSeed(42))
+}
+
+func TestMockServer(t *testing.T) {
+	s := newMockTestServer()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		header     http.Header
+		wantStatus int
+	}{
+		{name: "should answer declared success status", method: http.MethodGet, path: "/users/1", wantStatus: 200},
+		{name: "should select status from prefer header", method: http.MethodGet, path: "/users/1", header: http.Header{"Prefer": {"code=404"}}, wantStatus: 404},
+		{name: "should reject undeclared prefer status", method: http.MethodGet, path: "/users/1", header: http.Header{"Prefer": {"code=500"}}, wantStatus: 400},
+		{name: "should reject invalid path parameter", method: http.MethodGet, path: "/users/abc", wantStatus: 400},
+		{name: "should reject missing required field", method: http.MethodPost, path: "/users", body: `{"id":"1"}`, header: http.Header{"Content-Type": {"application/json"}}, wantStatus: 400},
+		{name: "should reject unsupported media type", method: http.MethodPost, path: "/users", body: `{"name":"a"}`, header: http.Header{"Content-Type": {"text/plain"}}, wantStatus: 415},
+		{name: "should accept valid payload", method: http.MethodPost, path: "/users", body: `{"name":"a"}`, header: http.Header{"Content-Type": {"application/json"}}, wantStatus: 201},
+		{name: "should reject unknown method", method: http.MethodDelete, path: "/users/1", wantStatus: 405},
+		{name: "should reject unknown path", method: http.MethodGet, path: "/groups", wantStatus: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMockServer_Deterministic(t *testing.T) {
+	get := func() map[string]interface{} {
+		rec := httptest.NewRecorder()
+		newMockTestServer().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		body := map[string]interface{}{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	a, b := get(), get()
+	if a["name"] == nil || a["name"] != b["name"] || a["email"] != b["email"] {
+		t.Errorf("responses differ: %v != %v", a, b)
+	}
+}
+
+func TestMockServer_MediaTypes(t *testing.T) {
+	r := NewRegistry[http.HandlerFunc]()
+	r.GET("/users/{id}", nil).
+		Parameters(mockTestParameters{}).
+		Response(200, mockTestUser{}, "User found", MediaTypeXML)
+	r.GET("/reports", nil).
+		Response(200, mockTestUser{}, "Report", "text/csv")
+	s := NewMockServer(r, MockSeed(42))
+
+	t.Run("should encode bodies in the declared media type", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		var user mockTestUser
+		if err := xml.Unmarshal(rec.Body.Bytes(), &user); err != nil || user.Name == "" || rec.Header().Get("Content-Type") != MediaTypeXML {
+			t.Errorf("got %s %q, %v", rec.Header().Get("Content-Type"), rec.Body.String(), err)
+		}
+	})
+	t.Run("should reject media types without codec", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reports", nil))
+		if rec.Code != http.StatusNotAcceptable {
+			t.Errorf("status = %d, want %d (%s)", rec.Code, http.StatusNotAcceptable, rec.Body.String())
+		}
+	})
+}
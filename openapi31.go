@@ -0,0 +1,160 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	OpenAPI31Version       = "3.1.0"
+	OpenAPI31SchemaDialect = "https://spec.openapis.org/oas/3.1/dialect/base"
+)
+
+// OpenAPI31 is an OpenAPI 3.1 document in its generic json representation. kin-openapi only models
+// OpenAPI 3.0, which is why the 3.1 document is derived from the annotated openapi3.T.
+type OpenAPI31 map[string]interface{}
+
+// Annotate31 annotates t like Annotate and returns the equivalent OpenAPI 3.1 document.
+func (r *registry[T]) Annotate31(t *openapi3.T, filters ...EndpointFilter[T]) (OpenAPI31, error) {
+	r.Annotate(t, filters...)
+	return ConvertToOpenAPI31(t)
+}
+
+// ConvertToOpenAPI31 converts an OpenAPI 3.0 document into an OpenAPI 3.1 document. Schemas are
+// rewritten to JSON Schema 2020-12: nullable becomes a "null" type, exclusiveMinimum/exclusiveMaximum
+// become numeric, example becomes examples and single-valued enums become const.
+func ConvertToOpenAPI31(t *openapi3.T) (OpenAPI31, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal openapi 3.0 document: %w", err)
+	}
+
+	doc := OpenAPI31{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal openapi 3.0 document: %w", err)
+	}
+
+	doc["openapi"] = OpenAPI31Version
+	doc["jsonSchemaDialect"] = OpenAPI31SchemaDialect
+	convertOpenAPI31Node(doc)
+
+	if webhooks, ok := doc["x-webhooks"]; ok {
+		doc["webhooks"] = webhooks
+		delete(doc, "x-webhooks")
+	}
+	return doc, nil
+}
+
+// convertOpenAPI31Node walks the non-schema parts of the document and converts every schema it encounters.
+func convertOpenAPI31Node(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch key {
+			case "schema":
+				if schema, ok := value.(map[string]interface{}); ok {
+					convertOpenAPI31Schema(schema)
+				}
+			case "schemas":
+				if schemas, ok := value.(map[string]interface{}); ok {
+					for _, schema := range schemas {
+						if schema, ok := schema.(map[string]interface{}); ok {
+							convertOpenAPI31Schema(schema)
+						}
+					}
+				}
+			default:
+				convertOpenAPI31Node(value)
+			}
+		}
+	case OpenAPI31:
+		convertOpenAPI31Node(map[string]interface{}(v))
+	case []interface{}:
+		for _, value := range v {
+			convertOpenAPI31Node(value)
+		}
+	}
+}
+
+func convertOpenAPI31Schema(schema map[string]interface{}) {
+	if _, isRef := schema["$ref"]; isRef {
+		return
+	}
+
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		switch t := schema["type"].(type) {
+		case string:
+			schema["type"] = []interface{}{t, "null"}
+		case nil:
+			schema["anyOf"] = append(toInterfaceSlice(schema["anyOf"]), map[string]interface{}{"type": "null"})
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			schema["enum"] = append(enum, nil)
+		}
+	}
+	delete(schema, "nullable")
+
+	for exclusiveKey, boundKey := range map[string]string{"exclusiveMinimum": "minimum", "exclusiveMaximum": "maximum"} {
+		exclusive, isBool := schema[exclusiveKey].(bool)
+		if !isBool {
+			continue
+		}
+		delete(schema, exclusiveKey)
+		if bound, hasBound := schema[boundKey]; exclusive && hasBound {
+			schema[exclusiveKey] = bound
+			delete(schema, boundKey)
+		}
+	}
+
+	if example, ok := schema["example"]; ok {
+		schema["examples"] = []interface{}{example}
+		delete(schema, "example")
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) == 1 {
+		schema["const"] = enum[0]
+		delete(schema, "enum")
+	}
+
+	if schema["type"] == "string" {
+		switch schema["format"] {
+		case "binary":
+			schema["contentMediaType"] = "application/octet-stream"
+			delete(schema, "format")
+		case "byte":
+			schema["contentEncoding"] = "base64"
+			delete(schema, "format")
+		}
+	}
+
+	for _, key := range []string{"items", "not", "additionalProperties"} {
+		if child, ok := schema[key].(map[string]interface{}); ok {
+			convertOpenAPI31Schema(child)
+		}
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf", "prefixItems"} {
+		for _, child := range toInterfaceSlice(schema[key]) {
+			if child, ok := child.(map[string]interface{}); ok {
+				convertOpenAPI31Schema(child)
+			}
+		}
+	}
+	for _, key := range []string{"properties", "$defs"} {
+		if children, ok := schema[key].(map[string]interface{}); ok {
+			for _, child := range children {
+				if child, ok := child.(map[string]interface{}); ok {
+					convertOpenAPI31Schema(child)
+				}
+			}
+		}
+	}
+}
+
+func toInterfaceSlice(v interface{}) []interface{} {
+	if s, ok := v.([]interface{}); ok {
+		return s
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package specs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestConvertToOpenAPI31(t *testing.T) {
+	min, max := float64(0), float64(10)
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "test", Version: "1.0.0"},
+		Paths:   openapi3.Paths{},
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{
+				"Test": openapi3.NewSchemaRef("", &openapi3.Schema{
+					Type: "object",
+					Properties: openapi3.Schemas{
+						"nullable":  openapi3.NewSchemaRef("", &openapi3.Schema{Type: "string", Nullable: true}),
+						"exclusive": openapi3.NewSchemaRef("", &openapi3.Schema{Type: "integer", Min: &min, ExclusiveMin: true, Max: &max}),
+						"example":   openapi3.NewSchemaRef("", &openapi3.Schema{Type: "string", Example: "abc"}),
+						"const":     openapi3.NewSchemaRef("", &openapi3.Schema{Type: "string", Enum: []interface{}{"a"}}),
+						"items": openapi3.NewSchemaRef("", &openapi3.Schema{
+							Type:  "array",
+							Items: openapi3.NewSchemaRef("", &openapi3.Schema{Type: "string", Format: "byte"}),
+						}),
+					},
+				}),
+			},
+		},
+	}
+
+	got, err := ConvertToOpenAPI31(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["openapi"] != OpenAPI31Version {
+		t.Errorf("openapi = %v, want %v", got["openapi"], OpenAPI31Version)
+	}
+
+	properties := got["components"].(map[string]interface{})["schemas"].(map[string]interface{})["Test"].(map[string]interface{})["properties"].(map[string]interface{})
+	tests := []struct {
+		name     string
+		property string
+		want     map[string]interface{}
+	}{
+		{
+			name:     "should convert nullable into type array",
+			property: "nullable",
+			want:     map[string]interface{}{"type": []interface{}{"string", "null"}},
+		},
+		{
+			name:     "should convert exclusiveMinimum into a number",
+			property: "exclusive",
+			want:     map[string]interface{}{"type": "integer", "exclusiveMinimum": float64(0), "maximum": float64(10)},
+		},
+		{
+			name:     "should convert example into examples",
+			property: "example",
+			want:     map[string]interface{}{"type": "string", "examples": []interface{}{"abc"}},
+		},
+		{
+			name:     "should convert single-valued enum into const",
+			property: "const",
+			want:     map[string]interface{}{"type": "string", "const": "a"},
+		},
+		{
+			name:     "should convert nested schemas",
+			property: "items",
+			want:     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string", "contentEncoding": "base64"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := properties[tt.property]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.property, got, tt.want)
+			}
+		})
+	}
+}
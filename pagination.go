@@ -0,0 +1,302 @@
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrPaginationAnnotationFailed = errors.New("pagination annotation failed")
+	ErrPaginationInvalid          = errors.New("pagination invalid")
+)
+
+type PaginationStyle string
+
+const (
+	// OffsetPagination selects items using the offset and limit query parameters.
+	OffsetPagination PaginationStyle = "offset"
+	// PagePagination selects items using the page (starting at 1) and size query parameters.
+	PagePagination PaginationStyle = "page"
+	// CursorPagination selects items using the opaque cursor of the previous page and limit.
+	CursorPagination PaginationStyle = "cursor"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+
+	TotalCountHeader = "X-Total-Count"
+)
+
+// OffsetParams are the query parameters of OffsetPagination. Queries declaring other parameters of
+// a paginated endpoint must embed them. The limit is bounded by MaxPageLimit, which is documented
+// by Paginated and enforced by ParsePageRequest.
+type OffsetParams struct {
+	Offset int `json:"offset,omitempty" form:"offset" validate:"omitempty,min=0"`
+	Limit  int `json:"limit,omitempty" form:"limit" validate:"omitempty,min=1"`
+}
+
+// PageParams are the query parameters of PagePagination, the size is bounded by MaxPageLimit.
+type PageParams struct {
+	Page int `json:"page,omitempty" form:"page" validate:"omitempty,min=1"`
+	Size int `json:"size,omitempty" form:"size" validate:"omitempty,min=1"`
+}
+
+// CursorParams are the query parameters of CursorPagination, the limit is bounded by MaxPageLimit.
+type CursorParams struct {
+	Cursor string `json:"cursor,omitempty" form:"cursor"`
+	Limit  int    `json:"limit,omitempty" form:"limit" validate:"omitempty,min=1"`
+}
+
+// Page is the response envelope of paginated endpoints. Total is omitted by cursor pagination and
+// NextCursor by the other styles.
+type Page[T interface{}] struct {
+	Items      []T    `json:"items" validate:"required"`
+	Total      *int   `json:"total,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// NewPage returns the page of items out of total items, nil items are encoded as an empty list.
+func NewPage[T interface{}](items []T, total int) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, Total: &total}
+}
+
+// NewCursorPage returns the page of items followed by the page of nextCursor, nil items are
+// encoded as an empty list.
+func NewCursorPage[T interface{}](items []T, nextCursor string) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, NextCursor: nextCursor}
+}
+
+// Pagination declares the style and item type of a paginated endpoint, see Paginate.
+type Pagination struct {
+	Style PaginationStyle
+	Page  interface{}
+}
+
+// Paginate declares pages of Item in the style, used with Builder.Paginated.
+func Paginate[Item interface{}](style PaginationStyle) Pagination {
+	return Pagination{Style: style, Page: Page[Item]{}}
+}
+
+func paginationParams(style PaginationStyle) interface{} {
+	switch style {
+	case OffsetPagination:
+		return OffsetParams{}
+	case PagePagination:
+		return PageParams{}
+	case CursorPagination:
+		return CursorParams{}
+	}
+	return nil
+}
+
+// Paginated declares the query parameters of the pagination style and the 200 response of pages. A
+// query declared before must embed the parameters of the style (e.g. OffsetParams).
+func (b *builder[T]) Paginated(pagination Pagination, description string) Builder[T] {
+	params := paginationParams(pagination.Style)
+	if params == nil {
+		b.panic(fmt.Errorf("unknown pagination style %q: %w", pagination.Style, ErrPaginationAnnotationFailed))
+	}
+	if b.e.Pagination != "" {
+		b.panic(fmt.Errorf("pagination already defined: %w", ErrPaginationAnnotationFailed))
+	}
+
+	if b.e.Query == nil {
+		b.Query(params)
+	} else {
+		names := map[string]struct{}{}
+		for _, field := range GetTypeInfo(reflect.TypeOf(b.e.Query)).Fields {
+			names[field.Name] = struct{}{}
+		}
+		for _, field := range GetTypeInfo(reflect.TypeOf(params)).Fields {
+			if _, ok := names[field.Name]; !ok {
+				b.panic(fmt.Errorf("query %T does not declare %s, embed %T: %w", b.e.Query, field.Name, params, ErrPaginationAnnotationFailed))
+			}
+		}
+	}
+	b.e.Pagination = pagination.Style
+	return b.Response(http.StatusOK, pagination.Page, description)
+}
+
+// annotatePagination declares the page of the operation as component, bounds the limit by
+// MaxPageLimit and documents the Link and X-Total-Count headers.
+func annotatePagination(operation *openapi3.Operation, style PaginationStyle, page interface{}, schemas openapi3.Schemas) {
+	limit := "limit"
+	if style == PagePagination {
+		limit = "size"
+	}
+	for _, param := range operation.Parameters {
+		if param.Value != nil && param.Value.In == "query" && param.Value.Name == limit && param.Value.Schema != nil && param.Value.Schema.Value != nil {
+			param.Value.Schema.Value.WithMax(MaxPageLimit)
+		}
+	}
+
+	response := operation.Responses.Get(http.StatusOK)
+	if response == nil || response.Value == nil {
+		return
+	}
+	for contentType, mediaType := range response.Value.Content {
+		if mediaType.Schema == nil || mediaType.Schema.Value == nil {
+			continue
+		}
+		name := schemaTypeName(reflect.TypeOf(page))
+		if isXMLMediaType(contentType) {
+			name += "XML"
+		}
+		schemas[name] = &openapi3.SchemaRef{Value: mediaType.Schema.Value}
+		mediaType.Schema = openapi3.NewSchemaRef("#/components/schemas/"+name, mediaType.Schema.Value)
+	}
+
+	response.Value.Headers = openapi3.Headers{
+		"Link": &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Description: "Links to the first, previous, next and last page (RFC 8288)",
+			Schema:      openapi3.NewStringSchema().NewRef(),
+		}}},
+	}
+	if style != CursorPagination {
+		response.Value.Headers[TotalCountHeader] = &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Description: "Total number of items",
+			Schema:      openapi3.NewIntegerSchema().WithMin(0).NewRef(),
+		}}}
+	}
+}
+
+// PageRequest is the page requested by the query parameters of a paginated endpoint, see
+// ParsePageRequest. Offset and Limit are set for all styles except CursorPagination.
+type PageRequest struct {
+	Style  PaginationStyle
+	Offset int
+	Limit  int
+	Page   int
+	Cursor string
+}
+
+// ParsePageRequest parses the query parameters of the pagination style. Missing parameters default to
+// the first page of DefaultPageLimit items, invalid or out of bounds parameters fail with
+// ErrPaginationInvalid.
+func ParsePageRequest(style PaginationStyle, query url.Values) (PageRequest, error) {
+	p := PageRequest{Style: style, Limit: DefaultPageLimit}
+
+	var err error
+	switch style {
+	case OffsetPagination:
+		if p.Offset, err = parsePageParam(query, "offset", 0, 0, -1); err != nil {
+			return p, err
+		}
+		if p.Limit, err = parsePageParam(query, "limit", DefaultPageLimit, 1, MaxPageLimit); err != nil {
+			return p, err
+		}
+		p.Page = p.Offset/p.Limit + 1
+	case PagePagination:
+		if p.Limit, err = parsePageParam(query, "size", DefaultPageLimit, 1, MaxPageLimit); err != nil {
+			return p, err
+		}
+		if p.Page, err = parsePageParam(query, "page", 1, 1, math.MaxInt/p.Limit+1); err != nil {
+			return p, err
+		}
+		p.Offset = (p.Page - 1) * p.Limit
+	case CursorPagination:
+		p.Cursor = query.Get("cursor")
+		if p.Limit, err = parsePageParam(query, "limit", DefaultPageLimit, 1, MaxPageLimit); err != nil {
+			return p, err
+		}
+	default:
+		return p, fmt.Errorf("unknown pagination style %q: %w", style, ErrPaginationInvalid)
+	}
+	return p, nil
+}
+
+// parsePageParam parses the integer parameter within min and max, a negative max is unbounded.
+func parsePageParam(query url.Values, name string, defaultValue int, min int, max int) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", name, ErrPaginationInvalid)
+	}
+	if value < min || (max >= 0 && value > max) {
+		return 0, fmt.Errorf("%s must be within [%d, %d]: %w", name, min, max, ErrPaginationInvalid)
+	}
+	return value, nil
+}
+
+// SetHeaders sets the Link header of the page requested from u and, unless using CursorPagination, the
+// X-Total-Count header. The next page of CursorPagination is linked if nextCursor is set.
+func (p PageRequest) SetHeaders(h http.Header, u *url.URL, total int, nextCursor string) {
+	var links []string
+	link := func(rel string, params map[string]string) {
+		target := *u
+		query := target.Query()
+		for name, value := range params {
+			if value == "" {
+				query.Del(name)
+			} else {
+				query.Set(name, value)
+			}
+		}
+		target.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", target.String(), rel))
+	}
+
+	switch p.Style {
+	case OffsetPagination:
+		last := 0
+		if total > 0 {
+			last = (total - 1) / p.Limit * p.Limit
+		}
+		limit := strconv.Itoa(p.Limit)
+		link("first", map[string]string{"offset": "0", "limit": limit})
+		if p.Offset > 0 {
+			prev := p.Offset - p.Limit
+			if prev < 0 {
+				prev = 0
+			}
+			link("prev", map[string]string{"offset": strconv.Itoa(prev), "limit": limit})
+		}
+		if p.Offset+p.Limit < total {
+			link("next", map[string]string{"offset": strconv.Itoa(p.Offset + p.Limit), "limit": limit})
+		}
+		link("last", map[string]string{"offset": strconv.Itoa(last), "limit": limit})
+	case PagePagination:
+		last := 1
+		if total > 0 {
+			last = (total + p.Limit - 1) / p.Limit
+		}
+		size := strconv.Itoa(p.Limit)
+		link("first", map[string]string{"page": "1", "size": size})
+		if p.Page > 1 {
+			link("prev", map[string]string{"page": strconv.Itoa(p.Page - 1), "size": size})
+		}
+		if p.Page < last {
+			link("next", map[string]string{"page": strconv.Itoa(p.Page + 1), "size": size})
+		}
+		link("last", map[string]string{"page": strconv.Itoa(last), "size": size})
+	case CursorPagination:
+		limit := strconv.Itoa(p.Limit)
+		link("first", map[string]string{"cursor": "", "limit": limit})
+		if nextCursor != "" {
+			link("next", map[string]string{"cursor": nextCursor, "limit": limit})
+		}
+	}
+
+	h.Set("Link", strings.Join(links, ", "))
+	if p.Style != CursorPagination {
+		h.Set(TotalCountHeader, strconv.Itoa(total))
+	}
+}
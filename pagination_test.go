@@ -0,0 +1,244 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type paginationTestUser struct {
+	ID string `json:"id"`
+}
+
+type paginationTestQuery struct {
+	OffsetParams
+	Role string `json:"role"`
+}
+
+func TestAnnotatePaginated(t *testing.T) {
+	r := NewRegistry[string]()
+	r.GET("/offset", "").Paginated(Paginate[paginationTestUser](OffsetPagination), "Users found")
+	r.GET("/page", "").Paginated(Paginate[paginationTestUser](PagePagination), "Users found")
+	r.GET("/cursor", "").Paginated(Paginate[*paginationTestUser](CursorPagination), "Users found")
+	r.GET("/query", "").Query(paginationTestQuery{}).Paginated(Paginate[paginationTestUser](OffsetPagination), "Users found")
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc)
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path       string
+		params     []string
+		bounded    string
+		totalCount bool
+	}{
+		{path: "/offset", params: []string{"limit", "offset"}, bounded: "limit", totalCount: true},
+		{path: "/page", params: []string{"page", "size"}, bounded: "size", totalCount: true},
+		{path: "/cursor", params: []string{"cursor", "limit"}, bounded: "limit"},
+		{path: "/query", params: []string{"limit", "offset", "role"}, bounded: "limit", totalCount: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			operation := doc.Paths.Find(tt.path).Get
+			var params []string
+			for _, param := range operation.Parameters {
+				params = append(params, param.Value.Name)
+				if param.Value.Name == tt.bounded {
+					schema := param.Value.Schema.Value
+					if schema.Min == nil || *schema.Min != 1 || schema.Max == nil || *schema.Max != MaxPageLimit {
+						t.Errorf("got bounds %v, %v", schema.Min, schema.Max)
+					}
+				}
+			}
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("got params %v, want %v", params, tt.params)
+			}
+
+			response := operation.Responses.Get(200).Value
+			if ref := response.Content.Get("application/json").Schema.Ref; ref != "#/components/schemas/PaginationTestUserPage" {
+				t.Errorf("got schema %s", ref)
+			}
+			if response.Headers["Link"] == nil || (response.Headers[TotalCountHeader] != nil) != tt.totalCount {
+				t.Errorf("got headers %v", response.Headers)
+			}
+		})
+	}
+
+	page := doc.Components.Schemas["PaginationTestUserPage"]
+	if page == nil || page.Value.Properties["items"].Value.Items.Value.Properties["id"] == nil || page.Value.Properties["nextCursor"] == nil {
+		t.Errorf("got components %v", doc.Components.Schemas)
+	}
+}
+
+func TestPaginatedRejectsInvalidDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *registry[string])
+	}{
+		{
+			name: "should reject unknown styles",
+			register: func(r *registry[string]) {
+				r.GET("/", "").Paginated(Paginate[paginationTestUser]("keyset"), "")
+			},
+		},
+		{
+			name: "should reject queries without the params of the style",
+			register: func(r *registry[string]) {
+				r.GET("/", "").Query(paginationTestQuery{}).Paginated(Paginate[paginationTestUser](CursorPagination), "")
+			},
+		},
+		{
+			name: "should reject paginating twice",
+			register: func(r *registry[string]) {
+				r.GET("/", "").Paginated(Paginate[paginationTestUser](OffsetPagination), "").Paginated(Paginate[paginationTestUser](OffsetPagination), "")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrPaginationAnnotationFailed) {
+					t.Errorf("got %v, want %v", err, ErrPaginationAnnotationFailed)
+				}
+			}()
+			tt.register(NewRegistry[string]())
+		})
+	}
+}
+
+func TestParsePageRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		style   PaginationStyle
+		query   string
+		want    PageRequest
+		wantErr error
+	}{
+		{name: "should default offsets", style: OffsetPagination, query: "", want: PageRequest{Style: OffsetPagination, Offset: 0, Limit: DefaultPageLimit, Page: 1}},
+		{name: "should parse offsets", style: OffsetPagination, query: "offset=20&limit=10", want: PageRequest{Style: OffsetPagination, Offset: 20, Limit: 10, Page: 3}},
+		{name: "should parse pages", style: PagePagination, query: "page=3&size=10", want: PageRequest{Style: PagePagination, Offset: 20, Limit: 10, Page: 3}},
+		{name: "should parse cursors", style: CursorPagination, query: "cursor=abc", want: PageRequest{Style: CursorPagination, Limit: DefaultPageLimit, Cursor: "abc"}},
+		{name: "should reject negative offsets", style: OffsetPagination, query: "offset=-1", wantErr: ErrPaginationInvalid},
+		{name: "should reject limits above the maximum", style: CursorPagination, query: "limit=101", wantErr: ErrPaginationInvalid},
+		{name: "should reject pages below 1", style: PagePagination, query: "page=0", wantErr: ErrPaginationInvalid},
+		{name: "should reject pages overflowing the offset", style: PagePagination, query: "page=100000000000000000&size=100", wantErr: ErrPaginationInvalid},
+		{name: "should reject malformed numbers", style: PagePagination, query: "size=ten", wantErr: ErrPaginationInvalid},
+		{name: "should reject unknown styles", style: "keyset", wantErr: ErrPaginationInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			got, err := ParsePageRequest(tt.style, query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name string
+		page Page[string]
+		want string
+	}{
+		{name: "should encode a total of zero", page: NewPage[string](nil, 0), want: `{"items":[],"total":0}`},
+		{name: "should omit the total of cursor pages", page: NewCursorPage([]string{"a"}, "b"), want: `{"items":["a"],"nextCursor":"b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageRequestSetHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		style      PaginationStyle
+		query      string
+		total      int
+		nextCursor string
+		wantLink   string
+		wantTotal  string
+	}{
+		{
+			name:      "should link offset pages",
+			style:     OffsetPagination,
+			query:     "offset=10&limit=10&role=admin",
+			total:     35,
+			wantLink:  `</users?limit=10&offset=0&role=admin>; rel="first", </users?limit=10&offset=0&role=admin>; rel="prev", </users?limit=10&offset=20&role=admin>; rel="next", </users?limit=10&offset=30&role=admin>; rel="last"`,
+			wantTotal: "35",
+		},
+		{
+			name:      "should link numbered pages",
+			style:     PagePagination,
+			query:     "page=4&size=10",
+			total:     35,
+			wantLink:  `</users?page=1&size=10>; rel="first", </users?page=3&size=10>; rel="prev", </users?page=4&size=10>; rel="last"`,
+			wantTotal: "35",
+		},
+		{
+			name:       "should link the next cursor",
+			style:      CursorPagination,
+			query:      "cursor=a",
+			nextCursor: "b",
+			wantLink:   `</users?limit=20>; rel="first", </users?cursor=b&limit=20>; rel="next"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &url.URL{Path: "/users", RawQuery: tt.query}
+			p, err := ParsePageRequest(tt.style, u.Query())
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := http.Header{}
+			p.SetHeaders(h, u, tt.total, tt.nextCursor)
+			if got := h.Get("Link"); got != tt.wantLink {
+				t.Errorf("got Link %s, want %s", got, tt.wantLink)
+			}
+			if got := h.Get(TotalCountHeader); got != tt.wantTotal {
+				t.Errorf("got %s %s, want %s", TotalCountHeader, got, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestSchemaTypeName(t *testing.T) {
+	tests := []struct {
+		name string
+		t    reflect.Type
+		want string
+	}{
+		{name: "should keep names of other types", t: reflect.TypeOf(paginationTestUser{}), want: "paginationTestUser"},
+		{name: "should name generic types by their arguments", t: reflect.TypeOf(Page[paginationTestUser]{}), want: "PaginationTestUserPage"},
+		{name: "should name pointer arguments by their element", t: reflect.TypeOf(Page[*paginationTestUser]{}), want: "PaginationTestUserPage"},
+		{name: "should name nested generic types", t: reflect.TypeOf(Page[Page[string]]{}), want: "StringPagePage"},
+		{name: "should name slice and map arguments", t: reflect.TypeOf(Page[map[string][]int]{}), want: "StringIntListMapPage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaTypeName(tt.t); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package specs
+
+import (
+	"net/url"
+	"strings"
+)
+
+type pathSegment struct {
+	Literal string
+	Param   string
+}
+
+func (s pathSegment) IsParam() bool {
+	return s.Param != ""
+}
+
+// pathTemplate is a parsed openapi path template (e.g. /api/users/{id}).
+type pathTemplate struct {
+	Raw      string
+	Segments []pathSegment
+}
+
+func parsePathTemplate(path string) pathTemplate {
+	template := pathTemplate{Raw: path}
+	for _, part := range splitPath(path) {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			template.Segments = append(template.Segments, pathSegment{Param: part[1 : len(part)-1]})
+		} else {
+			template.Segments = append(template.Segments, pathSegment{Literal: part})
+		}
+	}
+	return template
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// Match matches a request path against the template and returns the path parameters.
+func (p pathTemplate) Match(path string) (map[string]string, bool) {
+	parts := splitPath(path)
+	if len(parts) != len(p.Segments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range p.Segments {
+		if segment.IsParam() {
+			if parts[i] == "" {
+				return nil, false
+			}
+			params[segment.Param] = parts[i]
+			continue
+		}
+		if segment.Literal != parts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// Expand replaces the parameters of the template with the escaped values.
+func (p pathTemplate) Expand(params map[string]string) string {
+	var b strings.Builder
+	for _, segment := range p.Segments {
+		b.WriteString("/")
+		if segment.IsParam() {
+			b.WriteString(url.PathEscape(params[segment.Param]))
+		} else {
+			b.WriteString(segment.Literal)
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// Normalized returns the template with all parameter names erased, so that
+// templates which only differ in their parameter names compare equal.
+func (p pathTemplate) Normalized() string {
+	var b strings.Builder
+	for _, segment := range p.Segments {
+		b.WriteString("/")
+		if segment.IsParam() {
+			b.WriteString("{}")
+		} else {
+			b.WriteString(segment.Literal)
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// Specificity orders templates so that static segments are preferred over
+// parameters at the same position.
+func (p pathTemplate) Specificity() []bool {
+	specificity := make([]bool, len(p.Segments))
+	for i, segment := range p.Segments {
+		specificity[i] = !segment.IsParam()
+	}
+	return specificity
+}
+
+func morePathSpecific(a, b pathTemplate) bool {
+	as, bs := a.Specificity(), b.Specificity()
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i]
+		}
+	}
+	return len(as) > len(bs)
+}
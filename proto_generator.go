@@ -0,0 +1,730 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrProtoGenerationFailed = errors.New("proto generation failed")
+)
+
+var (
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+const (
+	protoMaxFieldNumber      = 1<<29 - 1
+	protoReservedRangeStart  = 19000
+	protoReservedRangeEnd    = 19999
+	protoEmptyMessage        = "google.protobuf.Empty"
+	protoDefaultPackageName  = "api"
+	protoDefaultServiceName  = "Default"
+	protoEnumUnspecifiedName = "UNSPECIFIED"
+)
+
+// ProtoLock keeps the field numbers of messages and the values of enums stable across runs. Numbers of
+// removed fields stay locked and are emitted as reserved.
+type ProtoLock struct {
+	Messages map[string]map[string]int `json:"messages"`
+	Enums    map[string]map[string]int `json:"enums"`
+}
+
+func NewProtoLock() *ProtoLock {
+	return &ProtoLock{
+		Messages: map[string]map[string]int{},
+		Enums:    map[string]map[string]int{},
+	}
+}
+
+// ReadProtoLock reads the lockfile at path, a missing file yields an empty lock.
+func ReadProtoLock(path string) (*ProtoLock, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewProtoLock(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lock := NewProtoLock()
+	if err := json.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("failed to read proto lock %s: %w", path, err)
+	}
+	if lock.Messages == nil {
+		lock.Messages = map[string]map[string]int{}
+	}
+	if lock.Enums == nil {
+		lock.Enums = map[string]map[string]int{}
+	}
+	return lock, nil
+}
+
+// WriteFile writes the lock to path.
+func (l *ProtoLock) WriteFile(path string) error {
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+type ProtoGeneratorOption func(*protoGeneratorOptions)
+
+type protoGeneratorOptions struct {
+	packageName string
+	goPackage   string
+	lock        *ProtoLock
+}
+
+// ProtoPackage sets the proto package, defaults to api.
+func ProtoPackage(name string) ProtoGeneratorOption {
+	return func(o *protoGeneratorOptions) {
+		o.packageName = name
+	}
+}
+
+// ProtoGoPackage sets the go_package option.
+func ProtoGoPackage(goPackage string) ProtoGeneratorOption {
+	return func(o *protoGeneratorOptions) {
+		o.goPackage = goPackage
+	}
+}
+
+// ProtoLockFile numbers the fields without proto tag using the lock, which is updated with the
+// numbers assigned to new fields. Write it back to keep the numbers stable across runs.
+func ProtoLockFile(lock *ProtoLock) ProtoGeneratorOption {
+	return func(o *protoGeneratorOptions) {
+		o.lock = lock
+	}
+}
+
+// protoField is a field of a message before it has been numbered.
+type protoField struct {
+	name     string
+	jsonName string
+	typeExpr string
+	repeated bool
+	optional bool
+	number   int
+}
+
+type protoGenerator struct {
+	options         protoGeneratorOptions
+	schemas         openapi3.Schemas
+	schemaGenerator *SchemaRefGenerator
+
+	names    map[reflect.Type]string
+	taken    map[string]struct{}
+	messages map[string]string
+	enums    map[string]string
+	imports  map[string]struct{}
+}
+
+// GenerateProto writes a proto3 file declaring a message for every type used by the endpoints and
+// a service per tag with an rpc per operation. Fields are numbered by their proto tag, e.g.
+// `proto:"3"`, or by the lock (see ProtoLockFile). String fields restricted by oneof become enums.
+func (r *registry[T]) GenerateProto(w io.Writer, opts ...ProtoGeneratorOption) error {
+	options := &protoGeneratorOptions{
+		packageName: protoDefaultPackageName,
+	}
+	for _, applyOption := range opts {
+		applyOption(options)
+	}
+	if options.lock == nil {
+		options.lock = NewProtoLock()
+	}
+
+	g := &protoGenerator{
+		options:         *options,
+		schemas:         make(openapi3.Schemas),
+		schemaGenerator: NewSchemaRefGenerator(WithTypeInfoCache(NewTypeInfoCache())),
+		names:           map[reflect.Type]string{},
+		taken:           map[string]struct{}{},
+		messages:        map[string]string{},
+		enums:           map[string]string{},
+		imports:         map[string]struct{}{},
+	}
+
+	var endpoints []*Endpoint[T]
+	for _, endpoint := range r.sortedEndpoints() {
+		if !isChannel(endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if err := r.requireStableOperationIDs(endpoints); err != nil {
+		return fmt.Errorf("%v: %w", err, ErrProtoGenerationFailed)
+	}
+
+	services := map[string][]string{}
+	for _, endpoint := range endpoints {
+		service := protoDefaultServiceName
+		if len(endpoint.Tags) > 0 {
+			service = goIdentifier(endpoint.Tags[0], true)
+		}
+		rpc, err := g.rpc(endpoint.OperationID, endpoint.Parameters, endpoint.Query, firstPayload(endpoint.Payload), successResponse(endpoint))
+		if err != nil {
+			return fmt.Errorf("failed to generate %s %s: %w", endpoint.Method, endpoint.Path, err)
+		}
+		services[service] = append(services[service], rpc)
+	}
+
+	b := new(strings.Builder)
+	b.WriteString("// Code generated by github.com/jakoblorz/specs. DO NOT EDIT.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(b, "package %s;\n", g.options.packageName)
+	if len(g.imports) > 0 {
+		b.WriteString("\n")
+		for _, path := range sortedKeys(g.imports) {
+			fmt.Fprintf(b, "import %q;\n", path)
+		}
+	}
+	if g.options.goPackage != "" {
+		fmt.Fprintf(b, "\noption go_package = %q;\n", g.options.goPackage)
+	}
+	for _, service := range sortedKeys(services) {
+		fmt.Fprintf(b, "\nservice %sService {\n", service)
+		for _, rpc := range services[service] {
+			b.WriteString(rpc)
+		}
+		b.WriteString("}\n")
+	}
+	for _, name := range sortedKeys(g.messages) {
+		b.WriteString("\n" + g.messages[name])
+	}
+	for _, name := range sortedKeys(g.enums) {
+		b.WriteString("\n" + g.enums[name])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func successResponse[T interface{}](endpoint *Endpoint[T]) *Response {
+	status, ok := successResponseStatus(endpoint)
+	if !ok {
+		return nil
+	}
+	response := endpoint.Response[status]
+	return &response
+}
+
+func (g *protoGenerator) rpc(operationID string, parameters interface{}, query interface{}, payload interface{}, response *Response) (string, error) {
+	name := goIdentifier(operationID, true)
+
+	// the request is the only input if it is a message, otherwise the inputs are wrapped
+	var inputs []protoField
+	var single reflect.Type
+	for _, input := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"params", parameters},
+		{"query", query},
+		{"body", payload},
+	} {
+		if input.value == nil {
+			continue
+		}
+		t := reflect.TypeOf(input.value)
+		field, err := g.field(nil, t, name+"Request", input.name, input.name, nil)
+		if err != nil {
+			return "", err
+		}
+		inputs = append(inputs, field)
+		single = t
+	}
+	var request string
+	switch {
+	case len(inputs) == 0:
+		request = g.empty()
+	case len(inputs) == 1 && isProtoMessage(single):
+		request = inputs[0].typeExpr
+	default:
+		request = g.allocName(name+"Request", nil)
+		if err := g.emitMessage(request, inputs); err != nil {
+			return "", err
+		}
+	}
+
+	var returns string
+	switch {
+	case response == nil || (response.Value == nil && len(response.Events) == 0):
+		returns = g.empty()
+	case len(response.Events) > 0:
+		event, err := g.eventMessage(name+"Event", response.Events)
+		if err != nil {
+			return "", err
+		}
+		returns = "stream " + event
+	case isProtoMessage(reflect.TypeOf(response.Value)):
+		message, err := g.message(nil, reflect.TypeOf(response.Value), name+"Response")
+		if err != nil {
+			return "", err
+		}
+		returns = message
+	default:
+		field, err := g.field(nil, reflect.TypeOf(response.Value), name+"Response", "data", "data", nil)
+		if err != nil {
+			return "", err
+		}
+		returns = g.allocName(name+"Response", nil)
+		if err := g.emitMessage(returns, []protoField{field}); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("  rpc %s(%s) returns (%s);\n", name, request, returns), nil
+}
+
+func firstPayload(payload []Body) interface{} {
+	if len(payload) == 0 {
+		return nil
+	}
+	return payload[0].Value
+}
+
+func (g *protoGenerator) empty() string {
+	g.imports["google/protobuf/empty.proto"] = struct{}{}
+	return protoEmptyMessage
+}
+
+// isProtoMessage reports whether t is declared as a message of its own.
+func isProtoMessage(t reflect.Type) bool {
+	t = removeIndirect(t)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	switch t {
+	case timeType, fileHeaderType, fileType:
+		return false
+	}
+	return true
+}
+
+func (g *protoGenerator) allocName(base string, t reflect.Type) string {
+	name := base
+	for n := 2; ; n++ {
+		if _, ok := g.taken[name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(n)
+	}
+	g.taken[name] = struct{}{}
+	if t != nil {
+		g.names[t] = name
+	}
+	return name
+}
+
+// message declares the message of the struct t and returns its name. Anonymous structs are named
+// by the enclosing message and field.
+func (g *protoGenerator) message(parents []*TypeInfo, t reflect.Type, anonymousName string) (string, error) {
+	t = removeIndirect(t)
+	typeInfo := g.schemaGenerator.options.typeInfoCache.GetTypeInfo(t)
+	parents, err := appendParent(parents, typeInfo)
+	if errors.Is(err, ErrCycleDetected) {
+		// the message is being declared by an enclosing call
+		return g.names[t], nil
+	}
+	if name, ok := g.names[t]; ok {
+		return name, nil
+	}
+
+	base := goIdentifier(schemaTypeName(t), true)
+	if t.Name() == "" {
+		base = anonymousName
+	}
+	name := g.allocName(base, t)
+
+	schema, err := g.schema(t)
+	if err != nil {
+		return "", err
+	}
+
+	fields := make([]protoField, 0, len(typeInfo.Fields))
+	for _, fieldInfo := range typeInfo.Fields {
+		var property *openapi3.SchemaRef
+		if schema != nil {
+			property = schema.Properties[fieldInfo.Name]
+		}
+		if schema != nil && schema.Properties != nil && property == nil {
+			// excluded from the schema
+			continue
+		}
+		field, err := g.field(parents, fieldInfo.Type, name, protoFieldName(fieldInfo.Name), fieldInfo.Name, property)
+		if err != nil {
+			return "", fmt.Errorf("field %s of %s: %w", fieldInfo.Name, name, err)
+		}
+		if fieldInfo.fieldInfo_Proto != nil {
+			if fieldInfo.Proto_Number == 0 {
+				return "", fmt.Errorf("field %s of %s has an invalid proto tag: %w", fieldInfo.Name, name, ErrProtoGenerationFailed)
+			}
+			field.number = fieldInfo.Proto_Number
+		}
+		fields = append(fields, field)
+	}
+
+	if err := g.emitMessage(name, fields); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// schema returns the schema generated for t, used to derive enums.
+func (g *protoGenerator) schema(t reflect.Type) (*openapi3.Schema, error) {
+	ref, err := g.schemaGenerator.GenerateSchemaRef(reflect.New(t).Elem().Interface(), g.schemas)
+	if err != nil || ref == nil {
+		return nil, err
+	}
+	if ref.Value == nil {
+		if component := g.schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; component != nil {
+			return component.Value, nil
+		}
+	}
+	return ref.Value, nil
+}
+
+// field returns the field of type t named name in the message scope.
+func (g *protoGenerator) field(parents []*TypeInfo, t reflect.Type, scope string, name string, jsonName string, property *openapi3.SchemaRef) (protoField, error) {
+	field := protoField{name: name, jsonName: jsonName}
+	if t.Kind() == reflect.Ptr && !isProtoMessage(t) {
+		field.optional = true
+	}
+	t = removeIndirect(t)
+
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		field.repeated, field.optional = true, false
+		t = removeIndirect(t.Elem())
+		if property != nil && property.Value != nil {
+			property = property.Value.Items
+		}
+		if (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8) || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			return field, fmt.Errorf("repeated field %s cannot contain %v: %w", name, t, ErrProtoGenerationFailed)
+		}
+	}
+
+	typeExpr, err := g.typeExpr(parents, t, scope+goIdentifier(jsonName, true), property)
+	if err != nil {
+		return field, err
+	}
+	field.typeExpr = typeExpr
+	return field, nil
+}
+
+func (g *protoGenerator) typeExpr(parents []*TypeInfo, t reflect.Type, scope string, property *openapi3.SchemaRef) (string, error) {
+	t = removeIndirect(t)
+
+	switch t {
+	case timeType:
+		g.imports["google/protobuf/timestamp.proto"] = struct{}{}
+		return "google.protobuf.Timestamp", nil
+	case rawMessageType, interfaceType:
+		g.imports["google/protobuf/struct.proto"] = struct{}{}
+		return "google.protobuf.Value", nil
+	case fileHeaderType, fileType:
+		return "bytes", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.Int, reflect.Int64:
+		return "int64", nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return "int32", nil
+	case reflect.Uint, reflect.Uint64:
+		return "uint64", nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "uint32", nil
+	case reflect.Float32:
+		return "float", nil
+	case reflect.Float64:
+		return "double", nil
+	case reflect.String:
+		if property != nil && property.Value != nil && len(property.Value.Enum) > 0 {
+			return g.enum(scope, property.Value.Enum)
+		}
+		return "string", nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", nil
+		}
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return "", fmt.Errorf("map key %v is not supported: %w", t.Key(), ErrProtoGenerationFailed)
+		}
+		elem := removeIndirect(t.Elem())
+		if (elem.Kind() == reflect.Slice && elem.Elem().Kind() != reflect.Uint8) || elem.Kind() == reflect.Array || elem.Kind() == reflect.Map {
+			return "", fmt.Errorf("map value %v is not supported: %w", t.Elem(), ErrProtoGenerationFailed)
+		}
+		key, err := g.typeExpr(parents, t.Key(), scope+"Key", nil)
+		if err != nil {
+			return "", err
+		}
+		var additionalProperties *openapi3.SchemaRef
+		if property != nil && property.Value != nil {
+			additionalProperties = property.Value.AdditionalProperties.Schema
+		}
+		value, err := g.typeExpr(parents, elem, scope+"Value", additionalProperties)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("map<%s, %s>", key, value), nil
+	case reflect.Struct:
+		return g.message(parents, t, scope)
+	}
+	return "", fmt.Errorf("type %v is not supported: %w", t, ErrProtoGenerationFailed)
+}
+
+// enum declares the enum name of the string values, prefixing every value with the name of the enum
+// as values share the scope of their package.
+func (g *protoGenerator) enum(name string, values []interface{}) (string, error) {
+	name = g.allocName(name, nil)
+	prefix := protoConstantName(name)
+
+	locked := g.options.lock.Enums[name]
+	if locked == nil {
+		locked = map[string]int{}
+		g.options.lock.Enums[name] = locked
+	}
+	next := 1
+	for _, number := range locked {
+		if number >= next {
+			next = number + 1
+		}
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "enum %s {\n", name)
+	fmt.Fprintf(b, "  %s_%s = 0;\n", prefix, protoEnumUnspecifiedName)
+	seen := map[string]struct{}{protoEnumUnspecifiedName: {}}
+	for _, value := range values {
+		constant := protoConstantName(fmt.Sprint(value))
+		if _, ok := seen[constant]; ok || constant == "" {
+			return "", fmt.Errorf("enum %s cannot declare value %q: %w", name, value, ErrProtoGenerationFailed)
+		}
+		seen[constant] = struct{}{}
+
+		number, ok := locked[constant]
+		if !ok {
+			number = next
+			locked[constant] = number
+			next++
+		}
+		fmt.Fprintf(b, "  %s_%s = %d;\n", prefix, constant, number)
+	}
+	b.WriteString("}\n")
+	g.enums[name] = b.String()
+	return name, nil
+}
+
+// eventMessage declares a message with a oneof of the events of a stream.
+func (g *protoGenerator) eventMessage(name string, events []StreamEvent) (string, error) {
+	name = g.allocName(name, nil)
+	fields := make([]protoField, 0, len(events))
+	for _, event := range events {
+		field, err := g.field(nil, reflect.TypeOf(event.Value), name, protoFieldName(event.Name), event.Name, nil)
+		if err != nil {
+			return "", err
+		}
+		if field.repeated {
+			return "", fmt.Errorf("event %s cannot be repeated: %w", event.Name, ErrProtoGenerationFailed)
+		}
+		field.optional = false
+		fields = append(fields, field)
+	}
+	if err := g.numberFields(name, fields); err != nil {
+		return "", err
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "message %s {\n  oneof event {\n", name)
+	for _, field := range fields {
+		fmt.Fprintf(b, "    %s;\n", field.declaration())
+	}
+	b.WriteString("  }\n")
+	g.writeReserved(b, name, fields)
+	b.WriteString("}\n")
+	g.messages[name] = b.String()
+	return name, nil
+}
+
+func (g *protoGenerator) emitMessage(name string, fields []protoField) error {
+	if err := g.numberFields(name, fields); err != nil {
+		return err
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].number < fields[j].number
+	})
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "message %s {\n", name)
+	for _, field := range fields {
+		fmt.Fprintf(b, "  %s;\n", field.declaration())
+	}
+	g.writeReserved(b, name, fields)
+	b.WriteString("}\n")
+	g.messages[name] = b.String()
+	return nil
+}
+
+// numberFields numbers the fields without number using the lock and records all numbers in the lock.
+func (g *protoGenerator) numberFields(message string, fields []protoField) error {
+	locked := g.options.lock.Messages[message]
+	if locked == nil {
+		locked = map[string]int{}
+		g.options.lock.Messages[message] = locked
+	}
+
+	used := map[int]string{}
+	for name, number := range locked {
+		used[number] = name
+	}
+	for i, field := range fields {
+		if field.number == 0 {
+			continue
+		}
+		if field.number > protoMaxFieldNumber || (field.number >= protoReservedRangeStart && field.number <= protoReservedRangeEnd) {
+			return fmt.Errorf("field %s of %s has the invalid number %d: %w", field.name, message, field.number, ErrProtoGenerationFailed)
+		}
+		if other, ok := used[field.number]; ok && other != field.name {
+			return fmt.Errorf("fields %s and %s of %s share the number %d: %w", other, field.name, message, field.number, ErrProtoGenerationFailed)
+		}
+		used[field.number] = field.name
+		locked[field.name] = fields[i].number
+	}
+
+	next := 1
+	for number := range used {
+		if number >= next {
+			next = number + 1
+		}
+	}
+	for i, field := range fields {
+		if field.number != 0 {
+			continue
+		}
+		if number, ok := locked[field.name]; ok {
+			fields[i].number = number
+			continue
+		}
+		if next >= protoReservedRangeStart && next <= protoReservedRangeEnd {
+			next = protoReservedRangeEnd + 1
+		}
+		fields[i].number = next
+		locked[field.name] = next
+		next++
+	}
+	return nil
+}
+
+// writeReserved reserves the locked numbers and names of removed fields.
+func (g *protoGenerator) writeReserved(b *strings.Builder, message string, fields []protoField) {
+	present := map[string]struct{}{}
+	for _, field := range fields {
+		present[field.name] = struct{}{}
+	}
+	var numbers []int
+	var names []string
+	for name, number := range g.options.lock.Messages[message] {
+		if _, ok := present[name]; !ok {
+			numbers = append(numbers, number)
+			names = append(names, strconv.Quote(name))
+		}
+	}
+	if len(numbers) == 0 {
+		return
+	}
+	sort.Ints(numbers)
+	sort.Strings(names)
+
+	reserved := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		reserved = append(reserved, strconv.Itoa(number))
+	}
+	fmt.Fprintf(b, "  reserved %s;\n", strings.Join(reserved, ", "))
+	fmt.Fprintf(b, "  reserved %s;\n", strings.Join(names, ", "))
+}
+
+func (f protoField) declaration() string {
+	label := ""
+	switch {
+	case f.repeated:
+		label = "repeated "
+	case f.optional:
+		label = "optional "
+	}
+	declaration := fmt.Sprintf("%s%s %s = %d", label, f.typeExpr, f.name, f.number)
+	if f.jsonName != protoJSONName(f.name) {
+		declaration += fmt.Sprintf(" [json_name = %q]", f.jsonName)
+	}
+	return declaration
+}
+
+// protoFieldName converts a json name into a snake case field name, e.g. displayName becomes display_name.
+func protoFieldName(name string) string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		case unicode.IsUpper(r) && len(word) > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	fieldName := strings.Join(words, "_")
+	if fieldName == "" || unicode.IsDigit(rune(fieldName[0])) {
+		fieldName = "field_" + fieldName
+	}
+	return fieldName
+}
+
+// protoJSONName is the json name protoc derives from a field name.
+func protoJSONName(name string) string {
+	b := new(strings.Builder)
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// protoConstantName converts a name into upper snake case, e.g. UserRole becomes USER_ROLE.
+func protoConstantName(name string) string {
+	return strings.ToUpper(strings.TrimPrefix(protoFieldName(name), "field_"))
+}
@@ -0,0 +1,167 @@
+package specs
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type protoTestAddress struct {
+	City string `json:"city"`
+}
+
+type protoTestUser struct {
+	ID          string            `json:"id" proto:"1"`
+	DisplayName string            `json:"displayName"`
+	Role        string            `json:"role" validate:"oneof=admin member"`
+	Tags        []string          `json:"tags"`
+	Scores      map[string]int    `json:"scores"`
+	Friends     []*protoTestUser  `json:"friends"`
+	Age         *int              `json:"age"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Address     *protoTestAddress `json:"address"`
+	Settings    struct {
+		Theme string `json:"theme"`
+	} `json:"settings"`
+}
+
+type protoTestParams struct {
+	ID string `json:"id"`
+}
+
+type protoTestProgress struct {
+	Percent int `json:"percent"`
+}
+
+func TestGenerateProto(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", "").Tags("users").Response(200, []protoTestUser{}, "Users found")
+	r.GET("/users/{id}", "").Tags("users").Parameters(protoTestParams{}).Response(200, protoTestUser{}, "User found")
+	r.PUT("/users/{id}", "").Tags("users").Parameters(protoTestParams{}).Payload(protoTestUser{}).Response(204, nil, "User updated")
+	r.GET("/exports", "").Tags("exports").Stream(200, Event("progress", protoTestProgress{}), Event("done", protoTestAddress{}))
+	r.Channel(ProtocolWebSocket, "/rooms", "").Inbound("join", protoTestParams{})
+
+	b := new(bytes.Buffer)
+	if err := r.GenerateProto(b, ProtoPackage("users.v1"), ProtoGoPackage("example.com/users/v1")); err != nil {
+		t.Fatal(err)
+	}
+	proto := b.String()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "should declare the package", want: "package users.v1;\n"},
+		{name: "should declare the go package", want: "option go_package = \"example.com/users/v1\";\n"},
+		{name: "should import well known types", want: "import \"google/protobuf/timestamp.proto\";\n"},
+		{name: "should declare a service per tag", want: "service UsersService {\n  rpc GetUsers(google.protobuf.Empty) returns (GetUsersResponse);\n  rpc GetUsersById(ProtoTestParams) returns (ProtoTestUser);\n  rpc PutUsersById(PutUsersByIdRequest) returns (google.protobuf.Empty);\n}\n"},
+		{name: "should declare streams", want: "rpc GetExports(google.protobuf.Empty) returns (stream GetExportsEvent);"},
+		{name: "should declare events as oneof", want: "message GetExportsEvent {\n  oneof event {\n    ProtoTestProgress progress = 1;\n    ProtoTestAddress done = 2;\n  }\n}\n"},
+		{name: "should wrap responses which are not messages", want: "message GetUsersResponse {\n  repeated ProtoTestUser data = 1;\n}\n"},
+		{name: "should wrap multiple inputs", want: "message PutUsersByIdRequest {\n  ProtoTestParams params = 1;\n  ProtoTestUser body = 2;\n}\n"},
+		{name: "should number fields by the proto tag", want: "  string id = 1;\n"},
+		{name: "should name fields in snake case", want: "  string display_name = 5;\n"},
+		{name: "should keep json names which differ", want: "  google.protobuf.Timestamp created_at = 4 [json_name = \"created_at\"];\n"},
+		{name: "should declare optional scalars", want: "  optional int64 age = 3;\n"},
+		{name: "should declare repeated fields", want: "  repeated ProtoTestUser friends = 6;\n"},
+		{name: "should declare map fields", want: "  map<string, int64> scores = 8;\n"},
+		{name: "should declare enums from oneof", want: "enum ProtoTestUserRole {\n  PROTO_TEST_USER_ROLE_UNSPECIFIED = 0;\n  PROTO_TEST_USER_ROLE_ADMIN = 1;\n  PROTO_TEST_USER_ROLE_MEMBER = 2;\n}\n"},
+		{name: "should name anonymous structs by their field", want: "  ProtoTestUserSettings settings = 9;\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(proto, tt.want) {
+				t.Errorf("got\n%s\nwant it to contain\n%s", proto, tt.want)
+			}
+		})
+	}
+	if strings.Contains(proto, "Rooms") {
+		t.Errorf("channels must not be declared as rpcs:\n%s", proto)
+	}
+}
+
+func TestGenerateProtoLock(t *testing.T) {
+	// both versions of the type are named protoTestLockedUser
+	before := func() interface{} {
+		type protoTestLockedUser struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		}
+		return protoTestLockedUser{}
+	}()
+	after := func() interface{} {
+		type protoTestLockedUser struct {
+			Age  int    `json:"age"`
+			Name string `json:"name"`
+		}
+		return protoTestLockedUser{}
+	}()
+
+	path := filepath.Join(t.TempDir(), "proto.lock")
+	generate := func(v interface{}) string {
+		lock, err := ReadProtoLock(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewRegistry[string]()
+		r.GET("/users", "").OperationID("getUsers").Response(200, v, "")
+		b := new(bytes.Buffer)
+		if err := r.GenerateProto(b, ProtoLockFile(lock)); err != nil {
+			t.Fatal(err)
+		}
+		if err := lock.WriteFile(path); err != nil {
+			t.Fatal(err)
+		}
+		return b.String()
+	}
+
+	generate(before)
+	got := generate(after)
+	want := "message ProtoTestLockedUser {\n  string name = 2;\n  int64 age = 3;\n  reserved 1;\n  reserved \"email\";\n}\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("got\n%s\nwant it to contain\n%s", got, want)
+	}
+	if again := generate(after); again != got {
+		t.Errorf("got\n%s\nwant the same output as before\n%s", again, got)
+	}
+}
+
+func TestGenerateProtoFailed(t *testing.T) {
+	type duplicateNumbers struct {
+		A string `json:"a" proto:"1"`
+		B string `json:"b" proto:"1"`
+	}
+	type invalidNumber struct {
+		A string `json:"a" proto:"a"`
+	}
+	type nestedRepeated struct {
+		A [][]string `json:"a"`
+	}
+	type mapKey struct {
+		A map[protoTestParams]string `json:"a"`
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		opts []RegistryOption
+	}{
+		{name: "should reject random operation IDs", v: protoTestParams{}},
+		{name: "should reject duplicate numbers", v: duplicateNumbers{}, opts: []RegistryOption{OperationIDGenerator(MethodPathOperationIDGenerator)}},
+		{name: "should reject invalid numbers", v: invalidNumber{}, opts: []RegistryOption{OperationIDGenerator(MethodPathOperationIDGenerator)}},
+		{name: "should reject nested repeated fields", v: nestedRepeated{}, opts: []RegistryOption{OperationIDGenerator(MethodPathOperationIDGenerator)}},
+		{name: "should reject unsupported map keys", v: mapKey{}, opts: []RegistryOption{OperationIDGenerator(MethodPathOperationIDGenerator)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry[string](tt.opts...)
+			r.GET("/", "").Response(200, tt.v, "")
+			if err := r.GenerateProto(new(bytes.Buffer)); !errors.Is(err, ErrProtoGenerationFailed) {
+				t.Errorf("got %v, want %v", err, ErrProtoGenerationFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrQueryBindingFailed = errors.New("query binding failed")
+)
+
+var (
+	sortType    = reflect.TypeOf(Sort{})
+	filtersType = reflect.TypeOf(Filters{})
+)
+
+type FilterOperator string
+
+const (
+	FilterEq  FilterOperator = "eq"
+	FilterNe  FilterOperator = "ne"
+	FilterLt  FilterOperator = "lt"
+	FilterLte FilterOperator = "lte"
+	FilterGt  FilterOperator = "gt"
+	FilterGte FilterOperator = "gte"
+	// FilterIn matches any of the comma separated values.
+	FilterIn FilterOperator = "in"
+)
+
+func validFilterOperator(op FilterOperator) bool {
+	switch op {
+	case FilterEq, FilterNe, FilterLt, FilterLte, FilterGt, FilterGte, FilterIn:
+		return true
+	}
+	return false
+}
+
+// SortKey is a key of a Sort, encoded as "key" or "-key" if Desc.
+type SortKey struct {
+	Key  string
+	Desc bool
+}
+
+func (k SortKey) String() string {
+	if k.Desc {
+		return "-" + k.Key
+	}
+	return k.Key
+}
+
+// Sort is the order requested by a sort query parameter such as ?sort=-created,name. The query field
+// declares the keys using the sortable tag, e.g. `json:"sort" sortable:"created,name"`.
+type Sort []SortKey
+
+func (s Sort) String() string {
+	keys := make([]string, len(s))
+	for i, key := range s {
+		keys[i] = key.String()
+	}
+	return strings.Join(keys, ",")
+}
+
+// EncodeQuery sets the sort parameter name of query.
+func (s Sort) EncodeQuery(name string, query url.Values) {
+	if len(s) > 0 {
+		query.Set(name, s.String())
+	}
+}
+
+// Filter is a condition of Filters, e.g. ?filter[status][in]=active,invited.
+type Filter struct {
+	Field    string
+	Operator FilterOperator
+	Values   []string
+}
+
+// Filters are the conditions requested by a deepObject filter query parameter such as
+// ?filter[status]=active, which is short for ?filter[status][eq]=active. Fields of the query declare
+// the operators they can be filtered by using the filterable tag, e.g. `json:"status" filterable:"eq,in"`.
+// Filterable fields are documented and validated as values of the filter parameter only.
+type Filters []Filter
+
+// Get returns the values of the condition of field and op.
+func (f Filters) Get(field string, op FilterOperator) ([]string, bool) {
+	for _, filter := range f {
+		if filter.Field == field && filter.Operator == op {
+			return filter.Values, true
+		}
+	}
+	return nil, false
+}
+
+// EncodeQuery sets the parameters of the filter parameter name of query.
+func (f Filters) EncodeQuery(name string, query url.Values) {
+	for _, filter := range f {
+		query.Set(fmt.Sprintf("%s[%s][%s]", name, filter.Field, filter.Operator), strings.Join(filter.Values, ","))
+	}
+}
+
+// queryGrammar is the sort and filter grammar declared by a query.
+type queryGrammar struct {
+	sort       *Field
+	filter     *Field
+	filterable []Field
+}
+
+func (g queryGrammar) empty() bool {
+	return g.sort == nil && g.filter == nil
+}
+
+// handles reports whether the field is documented and bound by the grammar instead of as parameter.
+func (g queryGrammar) handles(name string) bool {
+	if (g.sort != nil && g.sort.Name == name) || (g.filter != nil && g.filter.Name == name) {
+		return true
+	}
+	for _, field := range g.filterable {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func newQueryGrammar(t reflect.Type) (queryGrammar, error) {
+	var g queryGrammar
+	if t == nil || removeIndirect(t).Kind() != reflect.Struct {
+		return g, nil
+	}
+
+	for _, field := range GetTypeInfo(removeIndirect(t)).Fields {
+		field := field
+		switch removeIndirect(field.Type) {
+		case sortType:
+			if g.sort != nil {
+				return g, fmt.Errorf("query %v declares sort %s and %s", t, g.sort.Name, field.Name)
+			}
+			if field.fieldInfo_Query == nil || len(field.Query_Sortable) == 0 {
+				return g, fmt.Errorf("sort %s declares no sortable keys", field.Name)
+			}
+			if len(field.Query_Filterable) > 0 {
+				return g, fmt.Errorf("sort %s cannot be filterable", field.Name)
+			}
+			g.sort = &field
+		case filtersType:
+			if g.filter != nil {
+				return g, fmt.Errorf("query %v declares filters %s and %s", t, g.filter.Name, field.Name)
+			}
+			if field.fieldInfo_Query != nil {
+				return g, fmt.Errorf("filters %s cannot be sortable or filterable", field.Name)
+			}
+			g.filter = &field
+		default:
+			if field.fieldInfo_Query == nil {
+				continue
+			}
+			if len(field.Query_Sortable) > 0 {
+				return g, fmt.Errorf("field %s is sortable, only fields of type Sort declare sortable keys", field.Name)
+			}
+			for _, op := range field.Query_Filterable {
+				if !validFilterOperator(op) {
+					return g, fmt.Errorf("unknown filter operator %q of field %s", op, field.Name)
+				}
+			}
+			g.filterable = append(g.filterable, field)
+		}
+	}
+
+	if len(g.filterable) > 0 && g.filter == nil {
+		return g, fmt.Errorf("query %v declares filterable fields without a field of type Filters", t)
+	}
+	if g.filter != nil && len(g.filterable) == 0 {
+		return g, fmt.Errorf("filters %s declare no filterable fields", g.filter.Name)
+	}
+	return g, nil
+}
+
+// annotateQueryGrammar declares the sort parameter as enum of its keys and the filter parameter as
+// deepObject of the filterable fields, using the property schemas of the query.
+func annotateQueryGrammar(operation *openapi3.Operation, g queryGrammar, properties openapi3.Schemas) {
+	if g.sort != nil {
+		var keys []interface{}
+		for _, key := range g.sort.Query_Sortable {
+			keys = append(keys, key, "-"+key)
+		}
+		explode := false
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{
+			Value: &openapi3.Parameter{
+				Name:        g.sort.Name,
+				In:          "query",
+				Description: "Comma separated keys to sort by, prefixed with - to sort in descending order",
+				Style:       openapi3.SerializationForm,
+				Explode:     &explode,
+				Schema:      openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema().WithEnum(keys...)).NewRef(),
+			},
+		})
+	}
+
+	if g.filter != nil {
+		schema := openapi3.NewObjectSchema()
+		for _, field := range g.filterable {
+			valueRef := properties[field.Name]
+			operators := openapi3.NewObjectSchema()
+			for _, op := range field.Query_Filterable {
+				ref := valueRef
+				if op == FilterIn {
+					ref = openapi3.NewArraySchema().WithItems(valueRef.Value).NewRef()
+				}
+				operators.WithPropertyRef(string(op), ref)
+			}
+			schema.WithPropertyRef(field.Name, operators.NewRef())
+		}
+		explode := true
+		operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{
+			Value: &openapi3.Parameter{
+				Name:        g.filter.Name,
+				In:          "query",
+				Description: fmt.Sprintf("Conditions such as %s[field][operator]=value, %s[field]=value is short for the eq operator", g.filter.Name, g.filter.Name),
+				Style:       openapi3.SerializationDeepObject,
+				Explode:     &explode,
+				Schema:      schema.NewRef(),
+			},
+		})
+	}
+
+	if operation.Responses.Get(http.StatusBadRequest) == nil {
+		if operation.Responses == nil {
+			operation.Responses = openapi3.Responses{}
+		}
+		description := "Unknown sort keys, filter fields or filter operators"
+		operation.Responses[fmt.Sprint(http.StatusBadRequest)] = &openapi3.ResponseRef{Value: &openapi3.Response{Description: &description}}
+	}
+}
+
+// BindQuery decodes the query parameters into dst, which must be a pointer to a struct. Fields are
+// named by their json name, as documented by the registry. Fields of type Sort and Filters are
+// parsed using the sortable and filterable tags of the query, unknown sort keys, filter fields and
+// operators or malformed filter values fail with ErrQueryBindingFailed.
+func BindQuery(query url.Values, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("destination must be a pointer to a struct, got %T: %w", dst, ErrQueryBindingFailed)
+	}
+	v = v.Elem()
+
+	g, err := newQueryGrammar(v.Type())
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, ErrQueryBindingFailed)
+	}
+
+	for _, field := range GetTypeInfo(v.Type()).Fields {
+		if g.handles(field.Name) {
+			continue
+		}
+		name := field.Name
+		values := query[name]
+		if len(values) == 0 {
+			continue
+		}
+		fv, err := allocFieldByIndex(v, field.Index)
+		if err != nil {
+			return fmt.Errorf("query parameter %s: %v: %w", name, err, ErrQueryBindingFailed)
+		}
+		if err := setFormValue(fv, values, nil); err != nil {
+			return fmt.Errorf("query parameter %s: %v: %w", name, err, ErrQueryBindingFailed)
+		}
+	}
+
+	if g.sort != nil {
+		s, err := parseSort(query[g.sort.Name], g.sort.Query_Sortable)
+		if err != nil {
+			return fmt.Errorf("query parameter %s: %v: %w", g.sort.Name, err, ErrQueryBindingFailed)
+		}
+		if err := setGrammarValue(v, *g.sort, reflect.ValueOf(s)); err != nil {
+			return err
+		}
+	}
+	if g.filter != nil {
+		f, err := parseFilters(query, g.filter.Name, g.filterable)
+		if err != nil {
+			return fmt.Errorf("query parameter %s: %v: %w", g.filter.Name, err, ErrQueryBindingFailed)
+		}
+		if err := setGrammarValue(v, *g.filter, reflect.ValueOf(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setGrammarValue(v reflect.Value, field Field, value reflect.Value) error {
+	fv, err := allocFieldByIndex(v, field.Index)
+	if err != nil {
+		return fmt.Errorf("query parameter %s: %v: %w", field.Name, err, ErrQueryBindingFailed)
+	}
+	if fv.Kind() == reflect.Ptr {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	fv.Set(value)
+	return nil
+}
+
+// parseSort parses comma separated or repeated sort keys, which must be one of keys.
+func parseSort(values []string, keys []string) (Sort, error) {
+	var s Sort
+	seen := map[string]struct{}{}
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			key := SortKey{Key: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+			if !containsValue(keys, key.Key) {
+				return nil, fmt.Errorf("unknown sort key %q, expected one of %s", key.Key, strings.Join(keys, ", "))
+			}
+			if _, ok := seen[key.Key]; ok {
+				return nil, fmt.Errorf("duplicate sort key %q", key.Key)
+			}
+			seen[key.Key] = struct{}{}
+			s = append(s, key)
+		}
+	}
+	return s, nil
+}
+
+// parseFilters parses the name[field] and name[field][operator] parameters of query, sorted by
+// field and operator.
+func parseFilters(query url.Values, name string, filterable []Field) (Filters, error) {
+	fields := make(map[string]Field, len(filterable))
+	for _, field := range filterable {
+		fields[field.Name] = field
+	}
+
+	var f Filters
+	for _, key := range sortedKeys(query) {
+		if key == name {
+			return nil, fmt.Errorf("expected %s[field]", name)
+		}
+		if !strings.HasPrefix(key, name+"[") {
+			continue
+		}
+		rest := strings.TrimPrefix(key, name)
+		fieldName, op, ok := parseFilterKey(rest)
+		if !ok {
+			return nil, fmt.Errorf("malformed filter %s", key)
+		}
+		field, ok := fields[fieldName]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter field %q", fieldName)
+		}
+		if !containsValue(field.Query_Filterable, op) {
+			return nil, fmt.Errorf("unknown filter operator %q of field %s", op, fieldName)
+		}
+
+		var values []string
+		for _, value := range query[key] {
+			if op == FilterIn {
+				values = append(values, strings.Split(value, ",")...)
+			} else {
+				values = append(values, value)
+			}
+		}
+		t := removeIndirect(field.Type)
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+			t = t.Elem()
+		}
+		for _, value := range values {
+			if err := setFormValue(reflect.New(t).Elem(), []string{value}, nil); err != nil {
+				return nil, fmt.Errorf("filter %s: invalid value %q: %v", key, value, err)
+			}
+		}
+		f = append(f, Filter{Field: fieldName, Operator: op, Values: values})
+	}
+
+	sort.SliceStable(f, func(i, j int) bool {
+		if f[i].Field != f[j].Field {
+			return f[i].Field < f[j].Field
+		}
+		return f[i].Operator < f[j].Operator
+	})
+	for i := 1; i < len(f); i++ {
+		if f[i].Field == f[i-1].Field && f[i].Operator == f[i-1].Operator {
+			return nil, fmt.Errorf("duplicate filter %s[%s][%s]", name, f[i].Field, f[i].Operator)
+		}
+	}
+	return f, nil
+}
+
+// parseFilterKey parses [field] or [field][operator], the operator defaults to eq.
+func parseFilterKey(key string) (string, FilterOperator, bool) {
+	var parts []string
+	for key != "" {
+		if !strings.HasPrefix(key, "[") {
+			return "", "", false
+		}
+		end := strings.Index(key, "]")
+		if end < 2 {
+			return "", "", false
+		}
+		parts = append(parts, key[1:end])
+		key = key[end+1:]
+	}
+	switch len(parts) {
+	case 1:
+		return parts[0], FilterEq, true
+	case 2:
+		return parts[0], FilterOperator(parts[1]), true
+	}
+	return "", "", false
+}
+
+func containsValue[V comparable](values []V, value V) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package specs
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type queryTestListUsers struct {
+	OffsetParams
+	Sort    Sort      `json:"sort" sortable:"created,name"`
+	Filter  Filters   `json:"filter"`
+	Status  string    `json:"status" filterable:"eq,in" validate:"omitempty,oneof=active invited"`
+	Created time.Time `json:"created" filterable:"gte,lte"`
+	Search  string    `json:"q" form:"search"`
+}
+
+func TestAnnotateQueryGrammar(t *testing.T) {
+	r := NewRegistry[string]()
+	r.GET("/users", "").Query(queryTestListUsers{}).Paginated(Paginate[paginationTestUser](OffsetPagination), "Users found")
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc)
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Fatal(err)
+	}
+
+	operation := doc.Paths.Find("/users").Get
+	params := map[string]*openapi3.Parameter{}
+	var names []string
+	for _, param := range operation.Parameters {
+		params[param.Value.Name] = param.Value
+		names = append(names, param.Value.Name)
+	}
+	if want := []string{"limit", "offset", "q", "sort", "filter"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("got params %v, want %v", names, want)
+	}
+
+	t.Run("should declare the sort keys as enum", func(t *testing.T) {
+		sort := params["sort"]
+		want := []interface{}{"created", "-created", "name", "-name"}
+		if sort.Style != openapi3.SerializationForm || *sort.Explode || !reflect.DeepEqual(sort.Schema.Value.Items.Value.Enum, want) {
+			t.Errorf("got %+v", sort)
+		}
+	})
+	t.Run("should declare the filter as deepObject of the filterable fields", func(t *testing.T) {
+		filter := params["filter"]
+		if filter.Style != openapi3.SerializationDeepObject || !*filter.Explode {
+			t.Errorf("got %+v", filter)
+		}
+		status := filter.Schema.Value.Properties["status"].Value
+		if len(status.Properties) != 2 || len(status.Properties["eq"].Value.Enum) != 2 || status.Properties["in"].Value.Type != "array" {
+			t.Errorf("got status %+v", status)
+		}
+		created := filter.Schema.Value.Properties["created"].Value
+		if created.Properties["gte"].Value.Format != "date-time" || created.Properties["eq"] != nil {
+			t.Errorf("got created %+v", created)
+		}
+	})
+	t.Run("should document invalid queries", func(t *testing.T) {
+		if operation.Responses.Get(400) == nil {
+			t.Errorf("got responses %v", operation.Responses)
+		}
+	})
+}
+
+func TestQueryRejectsInvalidDeclarations(t *testing.T) {
+	tests := []struct {
+		name  string
+		query interface{}
+	}{
+		{
+			name: "should reject sorts without keys",
+			query: struct {
+				Sort Sort `json:"sort"`
+			}{},
+		},
+		{
+			name: "should reject sortable fields of other types",
+			query: struct {
+				Sort string `json:"sort" sortable:"name"`
+			}{},
+		},
+		{
+			name: "should reject filterable fields without filters",
+			query: struct {
+				Status string `json:"status" filterable:"eq"`
+			}{},
+		},
+		{
+			name: "should reject filters without filterable fields",
+			query: struct {
+				Filter Filters `json:"filter"`
+			}{},
+		},
+		{
+			name: "should reject unknown operators",
+			query: struct {
+				Filter Filters `json:"filter"`
+				Status string  `json:"status" filterable:"like"`
+			}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrQueryAnnotationFailed) {
+					t.Errorf("got %v, want %v", err, ErrQueryAnnotationFailed)
+				}
+			}()
+			NewRegistry[string]().GET("/", "").Query(tt.query)
+		})
+	}
+}
+
+func TestBindQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    queryTestListUsers
+		wantErr error
+	}{
+		{
+			name:  "should bind parameters, sorts and filters",
+			query: "limit=10&q=john&sort=-created,name&filter[status]=active&filter[created][gte]=2023-01-01T00:00:00Z",
+			want: queryTestListUsers{
+				OffsetParams: OffsetParams{Limit: 10},
+				Search:       "john",
+				Sort:         Sort{{Key: "created", Desc: true}, {Key: "name"}},
+				Filter: Filters{
+					{Field: "created", Operator: FilterGte, Values: []string{"2023-01-01T00:00:00Z"}},
+					{Field: "status", Operator: FilterEq, Values: []string{"active"}},
+				},
+			},
+		},
+		{
+			name:  "should split values of in filters",
+			query: "filter[status][in]=active,invited",
+			want:  queryTestListUsers{Filter: Filters{{Field: "status", Operator: FilterIn, Values: []string{"active", "invited"}}}},
+		},
+		{
+			name:  "should bind repeated sort parameters",
+			query: "sort=name&sort=-created",
+			want:  queryTestListUsers{Sort: Sort{{Key: "name"}, {Key: "created", Desc: true}}},
+		},
+		{name: "should bind parameters by their documented json name", query: "search=john", want: queryTestListUsers{}},
+		{name: "should reject unknown sort keys", query: "sort=email", wantErr: ErrQueryBindingFailed},
+		{name: "should reject duplicate sort keys", query: "sort=name,-name", wantErr: ErrQueryBindingFailed},
+		{name: "should reject unknown filter fields", query: "filter[email]=a", wantErr: ErrQueryBindingFailed},
+		{name: "should reject unknown filter operators", query: "filter[status][gte]=a", wantErr: ErrQueryBindingFailed},
+		{name: "should reject duplicate filters", query: "filter[status]=a&filter[status][eq]=b", wantErr: ErrQueryBindingFailed},
+		{name: "should reject malformed filters", query: "filter[status]x=a", wantErr: ErrQueryBindingFailed},
+		{name: "should reject malformed filter values", query: "filter[created][gte]=yesterday", wantErr: ErrQueryBindingFailed},
+		{name: "should reject malformed parameters", query: "limit=ten", wantErr: ErrQueryBindingFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got queryTestListUsers
+			if err := BindQuery(values, &got); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("should encode sorts and filters", func(t *testing.T) {
+		query := url.Values{}
+		Sort{{Key: "created", Desc: true}, {Key: "name"}}.EncodeQuery("sort", query)
+		Filters{{Field: "status", Operator: FilterIn, Values: []string{"active", "invited"}}}.EncodeQuery("filter", query)
+		if got, want := query.Encode(), "filter%5Bstatus%5D%5Bin%5D=active%2Cinvited&sort=-created%2Cname"; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+}
@@ -3,13 +3,15 @@ package specs
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	gonanoid "github.com/matoous/go-nanoid/v2"
 )
 
 var (
-	httpRegistry = registry[http.Handler]{}
+	httpRegistry = NewRegistry[http.Handler]()
 )
 
 func GET(path string, handler http.Handler) Builder[http.Handler] {
@@ -48,17 +50,67 @@ func DefaultOperationIDGenerator(method string, path string) string {
 	return id
 }
 
+// MethodPathOperationIDGenerator derives a stable, readable operation ID from the method and path,
+// e.g. GET /api/users/{id} becomes getApiUsersById.
+func MethodPathOperationIDGenerator(method string, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, segment := range parsePathTemplate(path).Segments {
+		if segment.IsParam() {
+			parts = append(parts, "by", segment.Param)
+		} else {
+			parts = append(parts, segment.Literal)
+		}
+	}
+	return goIdentifier(strings.Join(parts, " "), false)
+}
+
 type registryOptions struct {
 	OperationIDGenerator OperationIDGeneratorFunc
+	StrictRoutes         bool
+	Codecs               *Codecs
 }
 
 type RegistryOption func(*registryOptions)
 
+// StrictRoutes makes the registry panic as soon as an endpoint is registered which conflicts
+// with a previously registered one (see Check).
+func StrictRoutes() RegistryOption {
+	return func(o *registryOptions) {
+		o.StrictRoutes = true
+	}
+}
+
+// WithCodecs makes the registry panic as soon as a payload or response is declared with a media type
+// the codecs cannot decode and encode. Without codecs, media types are only documented.
+func WithCodecs(codecs *Codecs) RegistryOption {
+	return func(o *registryOptions) {
+		o.Codecs = codecs
+	}
+}
+
 type Registry[T interface{}] map[string]*Endpoint[T]
 
+type registryMeta[T interface{}] struct {
+	tagDescriptions map[string]string
+	audiences       map[string][]EndpointFilter[T]
+
+	// registrations holds all endpoints in registration order, including those whose
+	// operation ID has been overwritten in routes
+	registrations []*Endpoint[T]
+
+	// webhooks are the requests sent by the api independent of an operation, by name
+	webhooks map[string]*Endpoint[T]
+
+	// randomOperationIDs holds the operation IDs generated by DefaultOperationIDGenerator, which
+	// change on every run
+	randomOperationIDs map[string]bool
+}
+
 type registry[T interface{}] struct {
 	options registryOptions
 	routes  map[string]*Endpoint[T]
+	meta    *registryMeta[T]
+	group   *group[T]
 }
 
 func NewRegistry[T interface{}](opts ...RegistryOption) *registry[T] {
@@ -72,11 +124,37 @@ func NewRegistry[T interface{}](opts ...RegistryOption) *registry[T] {
 	return &registry[T]{
 		options: *options,
 		routes:  map[string]*Endpoint[T]{},
+		meta: &registryMeta[T]{
+			tagDescriptions:    map[string]string{},
+			audiences:          map[string][]EndpointFilter[T]{},
+			webhooks:           map[string]*Endpoint[T]{},
+			randomOperationIDs: map[string]bool{},
+		},
+		group: &group[T]{},
 	}
 }
 
 func (r *registry[T]) generateOperationID(method string, path string) string {
-	return r.options.OperationIDGenerator(method, path)
+	operationID := r.options.OperationIDGenerator(method, path)
+	if reflect.ValueOf(r.options.OperationIDGenerator).Pointer() == reflect.ValueOf(DefaultOperationIDGenerator).Pointer() {
+		r.meta.randomOperationIDs[operationID] = true
+	}
+	return operationID
+}
+
+// requireStableOperationIDs fails if an operation ID of the endpoints was generated randomly. Generators
+// naming methods or fields after operation IDs would otherwise produce different output on every run.
+func (r *registry[T]) requireStableOperationIDs(endpoints []*Endpoint[T]) error {
+	var unstable []string
+	for _, endpoint := range endpoints {
+		if r.meta.randomOperationIDs[endpoint.OperationID] {
+			unstable = append(unstable, endpoint.Method+" "+endpoint.Path)
+		}
+	}
+	if len(unstable) > 0 {
+		return fmt.Errorf("operation IDs of %s are generated randomly, use OperationIDGenerator(MethodPathOperationIDGenerator) or set them with Builder.OperationID", strings.Join(unstable, ", "))
+	}
+	return nil
 }
 
 func (r *registry[T]) Eject() Registry[T] {
@@ -84,18 +162,32 @@ func (r *registry[T]) Eject() Registry[T] {
 }
 
 func (r *registry[T]) Build(method string, path string, handler T) Builder[T] {
-	operationID := r.generateOperationID(method, path)
 	e := Endpoint[T]{
-		OperationID: operationID,
-		Method:      method,
-		Path:        path,
-		Handler:     handler,
+		Method:  method,
+		Path:    path,
+		Handler: handler,
 	}
+	inheritedResponses := r.group.apply(&e)
+	e.OperationID = r.generateOperationID(method, e.Path)
+	e.Source = callerSource()
 	r.Add(&e)
-	return &builder[T]{&e}
+	b := &builder[T]{e: &e, routes: r.routes, inheritedResponses: inheritedResponses, group: r.group, codecs: r.options.Codecs}
+	if r.options.StrictRoutes {
+		b.registrations = &r.meta.registrations
+	}
+	return b
 }
 
 func (r *registry[T]) Add(e *Endpoint[T]) {
+	if e.Source.File == "" {
+		e.Source = callerSource()
+	}
+	if r.options.StrictRoutes {
+		if conflicts := findRouteConflicts(r.meta.registrations, e); len(conflicts) > 0 {
+			panic(fmt.Errorf("failed to register endpoint (%s %s): %w", e.Method, e.Path, &RouteConflictError{Conflicts: conflicts}))
+		}
+	}
+	r.meta.registrations = append(r.meta.registrations, e)
 	r.routes[e.OperationID] = e
 }
 
@@ -131,111 +223,177 @@ func (r *registry[T]) TRACE(path string, handler T) Builder[T] {
 	return r.Build(http.MethodTrace, path, handler)
 }
 
-func (r *registry[T]) Annotate(t *openapi3.T) {
+// Annotate adds the endpoints matching all filters to t. The components only contain the schemas
+// reachable from the annotated operations.
+func (r *registry[T]) Annotate(t *openapi3.T, filters ...EndpointFilter[T]) {
 	schemas := make(openapi3.Schemas)
 
 	typeInfoCache := NewTypeInfoCache()
 	schemaGenerator := NewSchemaRefGenerator(WithTypeInfoCache(typeInfoCache))
+	formSchemaGenerator := NewSchemaRefGenerator(WithTypeInfoCache(typeInfoCache), FormFieldNames())
+	xmlSchemaGenerator := NewSchemaRefGenerator(WithTypeInfoCache(typeInfoCache), XMLNames())
+
+	// channels are documented by AnnotateAsyncAPI
+	var endpoints []*Endpoint[T]
+	for _, endpoint := range r.filteredEndpoints(filters) {
+		if !isChannel(endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	for _, endpoint := range endpoints {
+		t.AddOperation(endpoint.Path, endpoint.Method, annotateOperation(endpoint, schemaGenerator, formSchemaGenerator, xmlSchemaGenerator, schemas))
+	}
+	webhookEndpoints := r.annotateWebhooks(t, filters, schemaGenerator, formSchemaGenerator, xmlSchemaGenerator, schemas)
 
-	for operationId, endpoint := range r.routes {
-		operation := openapi3.Operation{
-			Tags:        endpoint.Tags,
-			Summary:     endpoint.Title,
-			Description: endpoint.Description,
-			OperationID: operationId,
-			Deprecated:  endpoint.Deprecated,
+	t.Components = &openapi3.Components{
+		Schemas: schemas,
+	}
+	annotateTags(t, append(endpoints, webhookEndpoints...), r.meta.tagDescriptions)
+
+}
+
+// annotateOperation returns the operation of the endpoint, its schemas are added to schemas.
+func annotateOperation[T interface{}](endpoint *Endpoint[T], schemaGenerator *SchemaRefGenerator, formSchemaGenerator *SchemaRefGenerator, xmlSchemaGenerator *SchemaRefGenerator, schemas openapi3.Schemas) *openapi3.Operation {
+	operation := openapi3.Operation{
+		Tags:        endpoint.Tags,
+		Summary:     endpoint.Title,
+		Description: endpoint.Description,
+		OperationID: endpoint.OperationID,
+		Deprecated:  endpoint.Deprecated,
+	}
+	if endpoint.Security != nil {
+		security := endpoint.Security
+		operation.Security = &security
+	}
+
+	if endpoint.Parameters != nil {
+		parameterRef, err := schemaGenerator.GenerateSchemaRef(endpoint.Parameters, schemas)
+		if err != nil {
+			panic(err)
 		}
 
-		if endpoint.Parameters != nil {
-			parameterRef, err := schemaGenerator.GenerateSchemaRef(endpoint.Parameters, schemas)
-			if err != nil {
-				panic(err)
-			}
+		if operation.Parameters == nil {
+			operation.Parameters = make(openapi3.Parameters, 0)
+		}
+		for _, name := range sortedKeys(parameterRef.Value.Properties) {
+			property := parameterRef.Value.Properties[name]
+			operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{
+				Value: &openapi3.Parameter{
+					Name:     name,
+					In:       "path",
+					Required: true,
+					Schema:   property,
+				},
+			})
+		}
+	}
 
-			if operation.Parameters == nil {
-				operation.Parameters = make(openapi3.Parameters, 0)
-			}
-			for name, property := range parameterRef.Value.Properties {
-				operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{
-					Value: &openapi3.Parameter{
-						Name:     name,
-						In:       "path",
-						Required: true,
-						Schema:   property,
-					},
-				})
+	var queryProperties openapi3.Schemas
+	if endpoint.Query != nil {
+		queryRef, err := schemaGenerator.GenerateSchemaRef(endpoint.Query, schemas)
+		if err != nil {
+			panic(err)
+		}
+		queryProperties = queryRef.Value.Properties
+
+		if operation.Parameters == nil {
+			operation.Parameters = make(openapi3.Parameters, 0)
+		}
+		grammar, _ := newQueryGrammar(reflect.TypeOf(endpoint.Query))
+		for _, name := range sortedKeys(queryRef.Value.Properties) {
+			if grammar.handles(name) {
+				continue
 			}
+			property := queryRef.Value.Properties[name]
+			operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{
+				Value: &openapi3.Parameter{
+					Name:   name,
+					In:     "query",
+					Schema: property,
+				},
+			})
 		}
+	}
 
-		if endpoint.Query != nil {
-			queryRef, err := schemaGenerator.GenerateSchemaRef(endpoint.Query, schemas)
+	if endpoint.Method != http.MethodGet {
+		content := make(map[string]*openapi3.MediaType)
+		for _, requestBodyDeclaration := range endpoint.Payload {
+			generator := schemaGenerator
+			if isFormMediaType(requestBodyDeclaration.MediaType) {
+				generator = formSchemaGenerator
+			} else if isXMLMediaType(requestBodyDeclaration.MediaType) {
+				generator = xmlSchemaGenerator
+			}
+			requestBodyRef, err := generator.GenerateSchemaRef(requestBodyDeclaration.Value, schemas)
 			if err != nil {
 				panic(err)
 			}
 
-			if operation.Parameters == nil {
-				operation.Parameters = make(openapi3.Parameters, 0)
+			content[requestBodyDeclaration.MediaType] = &openapi3.MediaType{
+				Schema: requestBodyRef,
 			}
-			for name, property := range queryRef.Value.Properties {
-				operation.Parameters = append(operation.Parameters, &openapi3.ParameterRef{
-					Value: &openapi3.Parameter{
-						Name:   name,
-						In:     "query",
-						Schema: property,
-					},
-				})
+			if requestBodyDeclaration.MediaType == MediaTypeMultipartForm {
+				content[requestBodyDeclaration.MediaType].Encoding = formEncoding(requestBodyDeclaration.Value)
 			}
 		}
 
-		if endpoint.Method != http.MethodGet {
-			content := make(map[string]*openapi3.MediaType)
-			for _, requestBodyDeclaration := range endpoint.Payload {
-				requestBodyRef, err := schemaGenerator.GenerateSchemaRef(requestBodyDeclaration.Value, schemas)
-				if err != nil {
-					panic(err)
-				}
-
-				content[requestBodyDeclaration.MediaType] = &openapi3.MediaType{
-					Schema: requestBodyRef,
-				}
-			}
+		operation.RequestBody = &openapi3.RequestBodyRef{
+			Value: &openapi3.RequestBody{
+				Required: true,
+				Content:  content,
+			},
+		}
+	}
 
-			operation.RequestBody = &openapi3.RequestBodyRef{
-				Value: &openapi3.RequestBody{
-					Required: true,
-					Content:  content,
-				},
-			}
+	for status, response := range endpoint.Response {
+		if operation.Responses == nil {
+			operation.Responses = make(map[string]*openapi3.ResponseRef)
+		}
+		description := response.Description
+		operation.Responses[fmt.Sprintf("%d", status)] = &openapi3.ResponseRef{
+			Value: &openapi3.Response{
+				Description: &description,
+			},
 		}
 
-		for status, response := range endpoint.Response {
-			responseRef, err := schemaGenerator.GenerateSchemaRef(response.Value, schemas)
+		if len(response.Events) > 0 {
+			content, err := streamContent(response.Events, schemaGenerator, schemas)
 			if err != nil {
 				panic(err)
 			}
+			operation.Responses[fmt.Sprintf("%d", status)].Value.Content = content
+			continue
+		}
 
-			if operation.Responses == nil {
-				operation.Responses = make(map[string]*openapi3.ResponseRef)
+		// responses without a value (e.g. 204 No Content) have no content
+		if response.Value == nil {
+			continue
+		}
+		content := make(map[string]*openapi3.MediaType)
+		for _, mediaType := range response.mediaTypes() {
+			generator := schemaGenerator
+			if isXMLMediaType(mediaType) {
+				generator = xmlSchemaGenerator
 			}
-			operation.Responses[fmt.Sprintf("%d", status)] = &openapi3.ResponseRef{
-				Value: &openapi3.Response{
-					Description: &response.Description,
-					Content: map[string]*openapi3.MediaType{
-						response.MediaType: &openapi3.MediaType{
-							Schema: responseRef,
-						},
-					},
-				},
+			responseRef, err := generator.GenerateSchemaRef(response.Value, schemas)
+			if err != nil {
+				panic(err)
+			}
+			content[mediaType] = &openapi3.MediaType{
+				Schema: responseRef,
 			}
 		}
-
-		t.AddOperation(endpoint.Path, endpoint.Method, &operation)
+		operation.Responses[fmt.Sprintf("%d", status)].Value.Content = content
 	}
 
-	t.Components = &openapi3.Components{
-		Schemas: schemas,
+	if endpoint.Pagination != "" {
+		annotatePagination(&operation, endpoint.Pagination, endpoint.Response[http.StatusOK].Value, schemas)
 	}
-
+	if grammar, _ := newQueryGrammar(reflect.TypeOf(endpoint.Query)); !grammar.empty() {
+		annotateQueryGrammar(&operation, grammar, queryProperties)
+	}
+	operation.Callbacks = annotateCallbacks[T](endpoint.Callbacks, schemaGenerator, formSchemaGenerator, xmlSchemaGenerator, schemas)
+	return &operation
 }
 
 func safeMediaTypes(mediaTypes []string) []string {
@@ -0,0 +1,40 @@
+package specs
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type registryTestError struct {
+	Message string `json:"message"`
+}
+
+func TestAnnotateResponses(t *testing.T) {
+	r := NewRegistry[string]()
+	r.DELETE("/users/{id}", "").
+		Parameters(swagger2TestParams{}).
+		Response(204, nil, "User deleted").
+		Response(404, registryTestError{}, "User not found")
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc)
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Fatal(err)
+	}
+	responses := doc.Paths.Find("/users/{id}").Delete.Responses
+
+	t.Run("should declare responses without value without content", func(t *testing.T) {
+		if response := responses.Get(204).Value; response.Content != nil {
+			t.Errorf("got content %v", response.Content)
+		}
+	})
+	t.Run("should keep the description of every response", func(t *testing.T) {
+		if got := *responses.Get(204).Value.Description; got != "User deleted" {
+			t.Errorf("got %s", got)
+		}
+		if got := *responses.Get(404).Value.Description; got != "User not found" {
+			t.Errorf("got %s", got)
+		}
+	})
+}
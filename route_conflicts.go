@@ -0,0 +1,173 @@
+package specs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+var (
+	ErrRouteConflict = errors.New("route conflict")
+)
+
+const (
+	ConflictDuplicateRoute       = "duplicate-route"
+	ConflictEquivalentRoute      = "equivalent-route"
+	ConflictShadowedRoute        = "shadowed-route"
+	ConflictDuplicateOperationID = "duplicate-operation-id"
+)
+
+var specsPackagePath = reflect.TypeOf(Source{}).PkgPath()
+
+// Source is the location in the source code at which an endpoint has been registered.
+type Source struct {
+	File string
+	Line int
+}
+
+func (s Source) String() string {
+	if s.File == "" {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// callerSource returns the location of the first caller outside of this package.
+func callerSource() Source {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, specsPackagePath+".") || strings.HasSuffix(frame.File, "_test.go") {
+			return Source{File: frame.File, Line: frame.Line}
+		}
+		if !more {
+			return Source{}
+		}
+	}
+}
+
+// RouteConflict describes two registrations which cannot coexist in a router.
+type RouteConflict struct {
+	Kind    string
+	Message string
+	Sources []Source
+}
+
+func (c RouteConflict) String() string {
+	sources := make([]string, 0, len(c.Sources))
+	for _, source := range c.Sources {
+		sources = append(sources, source.String())
+	}
+	return fmt.Sprintf("%s (registered at %s)", c.Message, strings.Join(sources, " and "))
+}
+
+type RouteConflictError struct {
+	Conflicts []RouteConflict
+}
+
+func (e *RouteConflictError) Error() string {
+	var b strings.Builder
+	if len(e.Conflicts) == 1 {
+		b.WriteString("1 route conflict:")
+	} else {
+		fmt.Fprintf(&b, "%d route conflicts:", len(e.Conflicts))
+	}
+	for _, conflict := range e.Conflicts {
+		b.WriteString("\n  - ")
+		b.WriteString(conflict.String())
+	}
+	return b.String()
+}
+
+func (e *RouteConflictError) Unwrap() error {
+	return ErrRouteConflict
+}
+
+// Check reports duplicate routes, routes which are equivalent up to their parameter names, routes
+// whose static segments shadow parameters of other routes and duplicate operation IDs. The returned
+// error is a *RouteConflictError.
+func (r *registry[T]) Check() error {
+	var conflicts []RouteConflict
+	for i, e := range r.meta.registrations {
+		conflicts = append(conflicts, findRouteConflicts(r.meta.registrations[:i], e)...)
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return &RouteConflictError{Conflicts: conflicts}
+}
+
+// findRouteConflicts returns the conflicts of the endpoint e with the previously registered endpoints.
+func findRouteConflicts[T interface{}](registered []*Endpoint[T], e *Endpoint[T]) []RouteConflict {
+	var conflicts []RouteConflict
+	for _, other := range registered {
+		sources := []Source{other.Source, e.Source}
+		sameMethod := strings.EqualFold(other.Method, e.Method)
+
+		// a duplicate route usually also yields a duplicate operation ID, which is reported as part of the route
+		if other.OperationID == e.OperationID && !(sameMethod && other.Path == e.Path) {
+			conflicts = append(conflicts, RouteConflict{
+				Kind:    ConflictDuplicateOperationID,
+				Message: fmt.Sprintf("operation ID %s is used by %s %s and %s %s", e.OperationID, other.Method, other.Path, e.Method, e.Path),
+				Sources: sources,
+			})
+		}
+
+		if !sameMethod {
+			continue
+		}
+		a, b := parsePathTemplate(other.Path), parsePathTemplate(e.Path)
+		switch {
+		case a.Raw == b.Raw:
+			conflicts = append(conflicts, RouteConflict{
+				Kind:    ConflictDuplicateRoute,
+				Message: fmt.Sprintf("%s %s is registered more than once", e.Method, e.Path),
+				Sources: sources,
+			})
+		case a.Normalized() == b.Normalized():
+			conflicts = append(conflicts, RouteConflict{
+				Kind:    ConflictEquivalentRoute,
+				Message: fmt.Sprintf("%s %s and %s %s only differ in their parameter names", other.Method, other.Path, e.Method, e.Path),
+				Sources: sources,
+			})
+		default:
+			if path, ok := overlappingPath(a, b); ok {
+				conflicts = append(conflicts, RouteConflict{
+					Kind:    ConflictShadowedRoute,
+					Message: fmt.Sprintf("%s %s and %s %s both match %s", other.Method, other.Path, e.Method, e.Path, path),
+					Sources: sources,
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// overlappingPath returns a request path matched by both templates if there is one, i.e. if at
+// every position the segments are equal or at least one of them is a parameter.
+func overlappingPath(a, b pathTemplate) (string, bool) {
+	if len(a.Segments) != len(b.Segments) {
+		return "", false
+	}
+
+	var path strings.Builder
+	for i := range a.Segments {
+		sa, sb := a.Segments[i], b.Segments[i]
+		path.WriteString("/")
+		switch {
+		case sa.IsParam() && sb.IsParam():
+			path.WriteString("{" + sa.Param + "}")
+		case sa.IsParam():
+			path.WriteString(sb.Literal)
+		case sb.IsParam() || sa.Literal == sb.Literal:
+			path.WriteString(sa.Literal)
+		default:
+			return "", false
+		}
+	}
+	return path.String(), true
+}
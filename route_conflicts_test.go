@@ -0,0 +1,128 @@
+package specs
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *registry[string])
+		want     []string
+	}{
+		{
+			name: "should accept distinct routes",
+			register: func(r *registry[string]) {
+				r.GET("/users", "")
+				r.GET("/users/{id}", "")
+				r.DELETE("/users/{id}", "")
+				r.GET("/users/{id}/posts", "")
+			},
+		},
+		{
+			name: "should detect duplicate routes",
+			register: func(r *registry[string]) {
+				r.GET("/users/{id}", "")
+				r.GET("/users/{id}", "")
+			},
+			want: []string{ConflictDuplicateRoute},
+		},
+		{
+			name: "should detect routes differing in parameter names",
+			register: func(r *registry[string]) {
+				r.GET("/users/{id}", "")
+				r.GET("/users/{userId}", "")
+			},
+			want: []string{ConflictEquivalentRoute},
+		},
+		{
+			name: "should detect static segments shadowing parameters",
+			register: func(r *registry[string]) {
+				r.GET("/users/{id}", "")
+				r.GET("/users/me", "")
+			},
+			want: []string{ConflictShadowedRoute},
+		},
+		{
+			name: "should detect duplicate operation ids",
+			register: func(r *registry[string]) {
+				r.Add(&Endpoint[string]{OperationID: "getUser", Method: "GET", Path: "/users/{id}"})
+				r.Add(&Endpoint[string]{OperationID: "getUser", Method: "GET", Path: "/accounts/{id}"})
+			},
+			want: []string{ConflictDuplicateOperationID},
+		},
+		{
+			name: "should detect operation ids replaced by duplicates",
+			register: func(r *registry[string]) {
+				r.GET("/users/{id}", "").OperationID("getUser")
+				r.GET("/accounts/{id}", "").OperationID("getUser")
+			},
+			want: []string{ConflictDuplicateOperationID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+			tt.register(r)
+
+			err := r.Check()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrRouteConflict) {
+				t.Fatalf("got %v, want %v", err, ErrRouteConflict)
+			}
+
+			var conflictErr *RouteConflictError
+			errors.As(err, &conflictErr)
+			var kinds []string
+			for _, conflict := range conflictErr.Conflicts {
+				kinds = append(kinds, conflict.Kind)
+				for _, source := range conflict.Sources {
+					if !strings.HasSuffix(source.File, "route_conflicts_test.go") {
+						t.Errorf("source %s does not point to the registration", source)
+					}
+				}
+			}
+			if strings.Join(kinds, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", kinds, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictRoutes(t *testing.T) {
+	r := NewRegistry[string](StrictRoutes())
+	r.GET("/users/{id}", "")
+
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrRouteConflict) {
+			t.Errorf("got %v, want %v", err, ErrRouteConflict)
+		}
+	}()
+	r.GET("/users/{userId}", "")
+}
+
+func TestStrictRoutesOperationID(t *testing.T) {
+	r := NewRegistry[string](StrictRoutes())
+	r.GET("/users", "").OperationID("listUsers")
+	r.GET("/users/{id}", "").OperationID("getUser")
+
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrRouteConflict) {
+			t.Errorf("got %v, want %v", err, ErrRouteConflict)
+		}
+		if e := r.Eject()["listUsers"]; e == nil || e.Method != http.MethodGet {
+			t.Errorf("got endpoint %+v", e)
+		}
+	}()
+	r.POST("/users", "").OperationID("listUsers")
+}
@@ -0,0 +1,710 @@
+package specs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrScaffoldGenerationFailed = errors.New("scaffold generation failed")
+)
+
+type ScaffoldOption func(*scaffoldOptions)
+
+type scaffoldOptions struct {
+	packageName     string
+	registryName    string
+	declareRegistry bool
+	handlerType     string
+	handlerParams   string
+	handlerBody     string
+	handlerImports  []string
+}
+
+// ScaffoldPackage sets the package name of the generated code. Defaults to "api".
+func ScaffoldPackage(name string) ScaffoldOption {
+	return func(o *scaffoldOptions) {
+		o.packageName = name
+	}
+}
+
+// ScaffoldRegistry registers the endpoints on an existing registry variable of the package instead of
+// declaring a new registry named router.
+func ScaffoldRegistry(name string) ScaffoldOption {
+	return func(o *scaffoldOptions) {
+		o.registryName = name
+		o.declareRegistry = false
+	}
+}
+
+// ScaffoldHandler sets the handler type of the registry along with the parameters and body of the
+// generated handler stubs, e.g. ScaffoldHandler("gin.HandlerFunc", "c *gin.Context",
+// "c.Status(http.StatusNotImplemented)", "github.com/gin-gonic/gin", "net/http"). Defaults to
+// http.HandlerFunc stubs responding with 501 Not Implemented.
+func ScaffoldHandler(handlerType string, params string, body string, imports ...string) ScaffoldOption {
+	return func(o *scaffoldOptions) {
+		o.handlerType = handlerType
+		o.handlerParams = params
+		o.handlerBody = body
+		o.handlerImports = imports
+	}
+}
+
+// scaffold holds the state of a single GenerateScaffold run.
+type scaffold struct {
+	options scaffoldOptions
+	imports *goImports
+
+	types      *bytes.Buffer
+	typeNames  map[string]struct{}
+	structs    map[string]struct{}
+	components map[string]string
+}
+
+// GenerateScaffold writes go source for a spec-first API: a struct with json and validate tags per schema,
+// an init() registration per operation using the Builder API and a handler stub per operation. The
+// generated types round-trip through the SchemaRefGenerator, so that the code can become the source of
+// truth of the document afterwards.
+func GenerateScaffold(w io.Writer, t *openapi3.T, opts ...ScaffoldOption) error {
+	options := scaffoldOptions{
+		packageName:     "api",
+		registryName:    "router",
+		declareRegistry: true,
+		handlerType:     "http.HandlerFunc",
+		handlerParams:   "w http.ResponseWriter, r *http.Request",
+		handlerBody:     "http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)",
+		handlerImports:  []string{"net/http"},
+	}
+	for _, applyOption := range opts {
+		applyOption(&options)
+	}
+
+	s := &scaffold{
+		options:    options,
+		imports:    newGoImports(),
+		types:      new(bytes.Buffer),
+		typeNames:  map[string]struct{}{},
+		structs:    map[string]struct{}{},
+		components: map[string]string{},
+	}
+
+	body := new(bytes.Buffer)
+	if err := s.writeComponents(t); err != nil {
+		return err
+	}
+	body.Write(s.types.Bytes())
+
+	operationCount := 0
+	for _, path := range sortedKeys(t.Paths) {
+		pathItem := t.Paths[path]
+		for _, method := range sortedOperationMethods(pathItem) {
+			s.types.Reset()
+			registration := new(bytes.Buffer)
+			if err := s.writeOperation(registration, path, method, pathItem, pathItem.GetOperation(method)); err != nil {
+				return fmt.Errorf("failed to scaffold %s %s: %w", method, path, err)
+			}
+			body.Write(registration.Bytes())
+			body.Write(s.types.Bytes())
+			operationCount++
+		}
+	}
+	if operationCount > 0 {
+		for _, pkgPath := range options.handlerImports {
+			s.imports.Alias(pkgPath)
+		}
+	}
+
+	src := new(bytes.Buffer)
+	fmt.Fprintf(src, "// Code generated by github.com/jakoblorz/specs")
+	if t.Info != nil {
+		fmt.Fprintf(src, " from %s %s", t.Info.Title, t.Info.Version)
+	}
+	fmt.Fprintf(src, ".\n// The code is the source of truth of the document from now on, edit it as needed.\n\npackage %s\n\n", options.packageName)
+	if options.declareRegistry {
+		s.imports.Alias("github.com/jakoblorz/specs")
+	}
+	s.imports.Write(src)
+	if options.declareRegistry {
+		fmt.Fprintf(src, "\nvar (\n%s = specs.NewRegistry[%s]()\n)\n", options.registryName, options.handlerType)
+	}
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format scaffold: %v: %w", err, ErrScaffoldGenerationFailed)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+func sortedOperationMethods(pathItem *openapi3.PathItem) []string {
+	order := []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions, http.MethodTrace}
+	var methods []string
+	for _, method := range order {
+		if pathItem.GetOperation(method) != nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// typeName reserves a unique exported type name.
+func (s *scaffold) typeName(name string) string {
+	name = goIdentifier(name, true)
+	unique := name
+	for n := 2; ; n++ {
+		if _, ok := s.typeNames[unique]; !ok {
+			break
+		}
+		unique = name + strconv.Itoa(n)
+	}
+	s.typeNames[unique] = struct{}{}
+	return unique
+}
+
+func (s *scaffold) writeComponents(t *openapi3.T) error {
+	if t.Components == nil {
+		return nil
+	}
+	names := sortedKeys(t.Components.Schemas)
+	for _, name := range names {
+		s.components[name] = s.typeName(name)
+	}
+	for _, name := range names {
+		schemaRef := t.Components.Schemas[name]
+		if schemaRef.Value == nil {
+			continue
+		}
+		typeName := s.components[name]
+		if isStructSchema(schemaRef.Value) {
+			s.structs[typeName] = struct{}{}
+			if err := s.writeStruct(typeName, schemaRef.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		typeExpr, err := s.goType(&openapi3.SchemaRef{Value: schemaRef.Value}, typeName+"Item")
+		if err != nil {
+			return err
+		}
+		writeGoComment(s.types, schemaRef.Value.Description)
+		fmt.Fprintf(s.types, "type %s %s\n\n", typeName, typeExpr)
+		if strings.HasPrefix(typeExpr, "[]") || strings.HasPrefix(typeExpr, "map[") {
+			s.structs[typeName] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func isStructSchema(schema *openapi3.Schema) bool {
+	return (schema.Type == "object" || schema.Type == "") && len(schema.Properties) > 0
+}
+
+// goType returns the go type of the schema, declaring structs for inline objects named by nameHint.
+func (s *scaffold) goType(schemaRef *openapi3.SchemaRef, nameHint string) (string, error) {
+	if schemaRef == nil {
+		return "interface{}", nil
+	}
+	if strings.HasPrefix(schemaRef.Ref, "#/components/schemas/") {
+		typeName, ok := s.components[strings.TrimPrefix(schemaRef.Ref, "#/components/schemas/")]
+		if !ok {
+			return "", fmt.Errorf("unknown schema %s: %w", schemaRef.Ref, ErrScaffoldGenerationFailed)
+		}
+		return typeName, nil
+	}
+	if schemaRef.Ref != "" {
+		return "", fmt.Errorf("external reference %s: %w", schemaRef.Ref, ErrScaffoldGenerationFailed)
+	}
+
+	schema := schemaRef.Value
+	if schema == nil {
+		return "interface{}", nil
+	}
+
+	var typeExpr string
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			typeExpr = s.imports.Alias("time") + ".Time"
+		case "byte", "binary":
+			typeExpr = "[]byte"
+		default:
+			typeExpr = "string"
+		}
+	case "integer":
+		typeExpr = goIntegerType(schema)
+	case "number":
+		typeExpr = "float64"
+		if schema.Format == "float" {
+			typeExpr = "float32"
+		}
+	case "boolean":
+		typeExpr = "bool"
+	case "array":
+		items, err := s.goType(schema.Items, nameHint+"Item")
+		if err != nil {
+			return "", err
+		}
+		typeExpr = "[]" + items
+	default:
+		switch {
+		case isStructSchema(schema):
+			typeName := s.typeName(nameHint)
+			s.structs[typeName] = struct{}{}
+			if err := s.writeStruct(typeName, schema); err != nil {
+				return "", err
+			}
+			typeExpr = typeName
+		case schema.AdditionalProperties.Schema != nil:
+			value, err := s.goType(schema.AdditionalProperties.Schema, nameHint+"Value")
+			if err != nil {
+				return "", err
+			}
+			typeExpr = "map[string]" + value
+		case schema.Type == "object":
+			typeExpr = "map[string]interface{}"
+		default:
+			// oneOf, anyOf and untyped schemas cannot be expressed by go types
+			typeExpr = "interface{}"
+		}
+	}
+
+	if schema.Nullable && !strings.HasPrefix(typeExpr, "[]") && !strings.HasPrefix(typeExpr, "map[") && typeExpr != "interface{}" {
+		typeExpr = "*" + typeExpr
+	}
+	return typeExpr, nil
+}
+
+// goIntegerType maps the bounds emitted by the SchemaRefGenerator for sized integers back to their type.
+func goIntegerType(schema *openapi3.Schema) string {
+	switch schema.Format {
+	case "int32":
+		return "int32"
+	case "int64":
+		return "int64"
+	}
+	if schema.Min == nil || schema.Max == nil {
+		if schema.Min != nil && *schema.Min == 0 && !schema.ExclusiveMin && schema.Max == nil {
+			return "uint"
+		}
+		return "int"
+	}
+	switch [2]float64{*schema.Min, *schema.Max} {
+	case [2]float64{math.MinInt8, math.MaxInt8}:
+		return "int8"
+	case [2]float64{math.MinInt16, math.MaxInt16}:
+		return "int16"
+	case [2]float64{0, math.MaxUint8}:
+		return "uint8"
+	case [2]float64{0, math.MaxUint16}:
+		return "uint16"
+	case [2]float64{0, math.MaxUint32}:
+		return "uint32"
+	case [2]float64{0, math.MaxUint64}:
+		return "uint64"
+	}
+	return "int"
+}
+
+func (s *scaffold) writeStruct(typeName string, schema *openapi3.Schema) error {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	fields := new(bytes.Buffer)
+	fieldNames := map[string]struct{}{}
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+
+		fieldName := goIdentifier(name, true)
+		for n := 2; ; n++ {
+			if _, ok := fieldNames[fieldName]; !ok {
+				break
+			}
+			fieldName = goIdentifier(name, true) + strconv.Itoa(n)
+		}
+		fieldNames[fieldName] = struct{}{}
+
+		fieldType, err := s.goType(property, typeName+goIdentifier(name, true))
+		if err != nil {
+			return err
+		}
+		// optional references to structs are pointers, which allows recursive types
+		if !required[name] && property.Ref != "" && property.Value != nil && isStructSchema(property.Value) {
+			fieldType = "*" + fieldType
+		}
+		if err := s.writeField(fields, fieldName, fieldType, name, property, required[name]); err != nil {
+			return err
+		}
+	}
+
+	writeGoComment(s.types, schema.Description)
+	fmt.Fprintf(s.types, "type %s struct {\n%s}\n\n", typeName, fields.String())
+	return nil
+}
+
+func (s *scaffold) writeField(w io.Writer, fieldName string, fieldType string, name string, schemaRef *openapi3.SchemaRef, required bool) error {
+	jsonTag := name
+	if !required {
+		jsonTag += ",omitempty"
+	}
+
+	// constraints of referenced non-struct types (e.g. enums) are repeated on the field, as the
+	// SchemaRefGenerator inlines them
+	var constraints []string
+	if schemaRef.Value != nil && !(schemaRef.Ref != "" && isStructSchema(schemaRef.Value)) {
+		constraints = validateConstraints(schemaRef.Value)
+		if schemaRef.Ref == "" {
+			writeGoComment(w, schemaRef.Value.Description)
+		}
+		if schemaRef.Value.Pattern != "" {
+			fmt.Fprintf(w, "// pattern %s cannot be validated by validate tags\n", schemaRef.Value.Pattern)
+		}
+		if items := schemaRef.Value.Items; items != nil && items.Value != nil && items.Value.Pattern != "" {
+			fmt.Fprintf(w, "// pattern %s of the items cannot be validated by validate tags\n", items.Value.Pattern)
+		}
+	}
+	switch {
+	case required:
+		constraints = append([]string{"required"}, constraints...)
+	case len(constraints) > 0:
+		constraints = append([]string{"omitempty"}, constraints...)
+	}
+
+	tag := fmt.Sprintf("json:%q", jsonTag)
+	if len(constraints) > 0 {
+		tag += fmt.Sprintf(" validate:%q", strings.Join(constraints, ","))
+	}
+	fmt.Fprintf(w, "%s %s `%s`\n", fieldName, fieldType, tag)
+	return nil
+}
+
+// validateConstraints returns the validator operators equivalent to the constraints of the schema, which
+// are the inverse of the schema annotators.
+func validateConstraints(schema *openapi3.Schema) []string {
+	var constraints []string
+	lengthConstraints := func(min uint64, max *uint64) {
+		switch {
+		case max != nil && min == *max:
+			constraints = append(constraints, fmt.Sprintf("len=%d", min))
+		default:
+			if min > 0 {
+				constraints = append(constraints, fmt.Sprintf("min=%d", min))
+			}
+			if max != nil {
+				constraints = append(constraints, fmt.Sprintf("max=%d", *max))
+			}
+		}
+	}
+
+	switch schema.Type {
+	case "string":
+		lengthConstraints(schema.MinLength, schema.MaxLength)
+		switch schema.Format {
+		case "email", "uuid", "uri", "ipv4", "ipv6", "hostname":
+			constraints = append(constraints, schema.Format)
+		}
+	case "integer", "number":
+		// bounds of sized integers are implied by their type
+		if integerType := goIntegerType(schema); schema.Type == "number" || integerType == "int" || integerType == "int32" || integerType == "int64" {
+			if schema.Min != nil {
+				operator := "min"
+				if schema.ExclusiveMin {
+					operator = "gt"
+				}
+				constraints = append(constraints, operator+"="+strconv.FormatFloat(*schema.Min, 'f', -1, 64))
+			}
+			if schema.Max != nil {
+				operator := "max"
+				if schema.ExclusiveMax {
+					operator = "lt"
+				}
+				constraints = append(constraints, operator+"="+strconv.FormatFloat(*schema.Max, 'f', -1, 64))
+			}
+		}
+	case "array":
+		lengthConstraints(schema.MinItems, schema.MaxItems)
+	case "object":
+		if !isStructSchema(schema) {
+			lengthConstraints(schema.MinProps, schema.MaxProps)
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		values := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			v := fmt.Sprint(value)
+			if f, ok := value.(float64); ok {
+				v = strconv.FormatFloat(f, 'f', -1, 64)
+			}
+			if strings.ContainsAny(v, " ,|") {
+				v = "'" + strings.NewReplacer(",", utf8HexComma, "|", utf8Pipe).Replace(v) + "'"
+			}
+			values = append(values, v)
+		}
+		constraints = append(constraints, "oneof="+strings.Join(values, " "))
+	}
+
+	// constraints of the items follow dive, e.g. max=5,dive,oneof=a b
+	if schema.Type == "array" && schema.Items != nil && schema.Items.Value != nil && !isStructSchema(schema.Items.Value) {
+		if items := validateConstraints(schema.Items.Value); len(items) > 0 {
+			constraints = append(append(constraints, "dive"), items...)
+		}
+	}
+	return constraints
+}
+
+func (s *scaffold) writeOperation(w io.Writer, path string, method string, pathItem *openapi3.PathItem, operation *openapi3.Operation) error {
+	operationID := operation.OperationID
+	if operationID == "" {
+		operationID = MethodPathOperationIDGenerator(method, path)
+	}
+	name := goIdentifier(operationID, true)
+	handlerName := "Handle" + name
+
+	fmt.Fprintf(w, "func init() {\n%s.%s(%q, %s)", s.options.registryName, method, path, handlerName)
+	if operation.OperationID != "" {
+		fmt.Fprintf(w, ".\nOperationID(%q)", operation.OperationID)
+	}
+	if operation.Summary != "" {
+		fmt.Fprintf(w, ".\nTitle(%q)", operation.Summary)
+	}
+	if operation.Description != "" {
+		fmt.Fprintf(w, ".\nDescription(%q)", operation.Description)
+	}
+	if operation.Deprecated {
+		fmt.Fprintf(w, ".\nDeprecated()")
+	}
+	if len(operation.Tags) > 0 {
+		tags := make([]string, 0, len(operation.Tags))
+		for _, tag := range operation.Tags {
+			tags = append(tags, strconv.Quote(tag))
+		}
+		fmt.Fprintf(w, ".\nTags(%s)", strings.Join(tags, ", "))
+	}
+	if operation.Security != nil {
+		fmt.Fprintf(w, ".\nSecurity(%s)", s.securityRequirementsExpr(*operation.Security))
+	}
+
+	var unsupported []string
+
+	parameters := map[string][]*openapi3.Parameter{}
+	for _, parameterRefs := range []openapi3.Parameters{pathItem.Parameters, operation.Parameters} {
+		for _, parameterRef := range parameterRefs {
+			if parameterRef.Value == nil {
+				continue
+			}
+			parameters[parameterRef.Value.In] = append(parameters[parameterRef.Value.In], parameterRef.Value)
+		}
+	}
+	for _, location := range []struct {
+		in     string
+		method string
+		suffix string
+	}{
+		{in: openapi3.ParameterInPath, method: "Parameters", suffix: "Parameters"},
+		{in: openapi3.ParameterInQuery, method: "Query", suffix: "Query"},
+	} {
+		if len(parameters[location.in]) == 0 {
+			continue
+		}
+		typeName := s.typeName(name + location.suffix)
+		s.structs[typeName] = struct{}{}
+		if err := s.writeParametersStruct(typeName, parameters[location.in]); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, ".\n%s(%s{})", location.method, typeName)
+	}
+	for _, in := range []string{openapi3.ParameterInHeader, openapi3.ParameterInCookie} {
+		for _, parameter := range parameters[in] {
+			unsupported = append(unsupported, fmt.Sprintf("%s parameter %s is not supported by the registry", in, parameter.Name))
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		groups, err := s.contentGroups(operation.RequestBody.Value.Content, name+"Request")
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			fmt.Fprintf(w, ".\nPayload(%s%s)", s.zeroValueExpr(group.typeExpr), mediaTypesArgs(group.mediaTypes))
+		}
+	}
+
+	for _, status := range sortedKeys(operation.Responses) {
+		responseRef := operation.Responses[status]
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			unsupported = append(unsupported, fmt.Sprintf("response %s is not supported by the registry", status))
+			continue
+		}
+		if responseRef.Value == nil {
+			continue
+		}
+		description := ""
+		if responseRef.Value.Description != nil {
+			description = *responseRef.Value.Description
+		}
+
+		nameHint := name + "Response"
+		if code < 200 || code > 299 {
+			nameHint = name + goIdentifier(http.StatusText(code), true) + "Response"
+		}
+		groups, err := s.contentGroups(responseRef.Value.Content, nameHint)
+		if err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			fmt.Fprintf(w, ".\nResponse(%d, nil, %q)", code, description)
+			continue
+		}
+		fmt.Fprintf(w, ".\nResponse(%d, %s, %q%s)", code, s.zeroValueExpr(groups[0].typeExpr), description, mediaTypesArgs(groups[0].mediaTypes))
+		for _, group := range groups[1:] {
+			unsupported = append(unsupported, fmt.Sprintf("response %d with media types %s uses a different schema", code, strings.Join(group.mediaTypes, ", ")))
+		}
+	}
+	fmt.Fprintf(w, "\n")
+	for _, message := range unsupported {
+		fmt.Fprintf(w, "// TODO: %s\n", message)
+	}
+	fmt.Fprintf(w, "}\n\n")
+
+	fmt.Fprintf(s.types, "// %s handles %s %s.\nfunc %s(%s) {\n%s\n}\n\n", handlerName, method, path, handlerName, s.options.handlerParams, s.options.handlerBody)
+	return nil
+}
+
+func (s *scaffold) writeParametersStruct(typeName string, parameters []*openapi3.Parameter) error {
+	sort.Slice(parameters, func(i, j int) bool {
+		return parameters[i].Name < parameters[j].Name
+	})
+
+	fields := new(bytes.Buffer)
+	for _, parameter := range parameters {
+		schemaRef := parameter.Schema
+		if schemaRef == nil {
+			schemaRef = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		}
+		fieldName := goIdentifier(parameter.Name, true)
+		fieldType, err := s.goType(schemaRef, typeName+fieldName)
+		if err != nil {
+			return err
+		}
+		writeGoComment(fields, parameter.Description)
+		if err := s.writeField(fields, fieldName, fieldType, parameter.Name, schemaRef, parameter.Required); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(s.types, "type %s struct {\n%s}\n\n", typeName, fields.String())
+	return nil
+}
+
+type scaffoldContentGroup struct {
+	typeExpr   string
+	mediaTypes []string
+}
+
+// contentGroups groups the media types by their go type, starting with json.
+func (s *scaffold) contentGroups(content openapi3.Content, nameHint string) ([]scaffoldContentGroup, error) {
+	mediaTypes := sortedContentTypes(content)
+	sort.SliceStable(mediaTypes, func(i, j int) bool {
+		return mediaTypes[i] == "application/json" && mediaTypes[j] != "application/json"
+	})
+
+	var groups []scaffoldContentGroup
+	schemaTypes := map[*openapi3.Schema]string{}
+	for _, mediaType := range mediaTypes {
+		schemaRef := content[mediaType].Schema
+
+		var typeExpr string
+		if schemaRef != nil && schemaRef.Ref == "" && schemaRef.Value != nil && schemaTypes[schemaRef.Value] != "" {
+			typeExpr = schemaTypes[schemaRef.Value]
+		} else {
+			var err error
+			if typeExpr, err = s.goType(schemaRef, nameHint); err != nil {
+				return nil, err
+			}
+			if schemaRef != nil && schemaRef.Value != nil {
+				schemaTypes[schemaRef.Value] = typeExpr
+			}
+		}
+
+		grouped := false
+		for i := range groups {
+			if groups[i].typeExpr == typeExpr {
+				groups[i].mediaTypes = append(groups[i].mediaTypes, mediaType)
+				grouped = true
+			}
+		}
+		if !grouped {
+			groups = append(groups, scaffoldContentGroup{typeExpr: typeExpr, mediaTypes: []string{mediaType}})
+		}
+	}
+	return groups, nil
+}
+
+func mediaTypesArgs(mediaTypes []string) string {
+	if len(mediaTypes) == 1 && mediaTypes[0] == "application/json" {
+		return ""
+	}
+	var b strings.Builder
+	for _, mediaType := range mediaTypes {
+		b.WriteString(", ")
+		b.WriteString(strconv.Quote(mediaType))
+	}
+	return b.String()
+}
+
+// zeroValueExpr returns an expression of the type which the SchemaRefGenerator can inspect.
+func (s *scaffold) zeroValueExpr(typeExpr string) string {
+	switch {
+	case strings.HasPrefix(typeExpr, "*"):
+		return "(" + typeExpr + ")(nil)"
+	case typeExpr == "interface{}":
+		return "new(interface{})"
+	case strings.HasPrefix(typeExpr, "[]"), strings.HasPrefix(typeExpr, "map["), strings.HasSuffix(typeExpr, ".Time"):
+		return typeExpr + "{}"
+	}
+	if _, ok := s.structs[typeExpr]; ok {
+		return typeExpr + "{}"
+	}
+	return "*new(" + typeExpr + ")"
+}
+
+func (s *scaffold) securityRequirementsExpr(requirements openapi3.SecurityRequirements) string {
+	alias := s.imports.Alias("github.com/getkin/kin-openapi/openapi3")
+	exprs := make([]string, 0, len(requirements))
+	for _, requirement := range requirements {
+		var entries []string
+		for _, scheme := range sortedKeys(requirement) {
+			scopes := make([]string, 0, len(requirement[scheme]))
+			for _, scope := range requirement[scheme] {
+				scopes = append(scopes, strconv.Quote(scope))
+			}
+			entries = append(entries, fmt.Sprintf("%q: {%s}", scheme, strings.Join(scopes, ", ")))
+		}
+		exprs = append(exprs, fmt.Sprintf("%s.SecurityRequirement{%s}", alias, strings.Join(entries, ", ")))
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func writeGoComment(w io.Writer, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(w, "// %s\n", strings.TrimRight(line, " \t"))
+	}
+}
@@ -0,0 +1,130 @@
+package specs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const scaffoldTestSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      summary: Get a pet
+      parameters:
+        - {name: petId, in: path, required: true, schema: {type: string, format: uuid}}
+        - {name: fields, in: query, schema: {type: array, items: {type: string}, maxItems: 3}}
+        - {name: sort, in: query, schema: {type: array, items: {type: string, enum: [name, -name], minLength: 2}}}
+        - {name: tags, in: query, schema: {type: array, items: {type: string, pattern: "^[a-z]+$"}}}
+      responses:
+        "200":
+          description: Pet found
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Pet"}
+        "404": {description: Pet not found}
+components:
+  schemas:
+    Kind: {type: string, enum: [cat, dog, "sea lion"]}
+    Pet:
+      type: object
+      required: [name, kind]
+      properties:
+        name: {type: string, minLength: 1, maxLength: 64}
+        kind: {$ref: "#/components/schemas/Kind"}
+        owner: {type: string, format: email}
+        age: {type: integer, minimum: 0, exclusiveMinimum: true}
+        parent: {$ref: "#/components/schemas/Pet"}
+`
+
+func TestGenerateScaffold(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(scaffoldTestSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := new(bytes.Buffer)
+	if err := GenerateScaffold(b, doc, ScaffoldPackage("pets")); err != nil {
+		t.Fatal(err)
+	}
+	src := b.String()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "should declare the registry", want: "router = specs.NewRegistry[http.HandlerFunc]()"},
+		{name: "should declare named types for components", want: "type Kind string"},
+		{name: "should map lengths to min and max", want: "`json:\"name\" validate:\"required,min=1,max=64\"`"},
+		{name: "should map enums to oneof", want: "validate:\"required,oneof=cat dog 'sea lion'\"`"},
+		{name: "should map formats", want: "`json:\"owner,omitempty\" validate:\"omitempty,email\"`"},
+		{name: "should map exclusive bounds", want: "validate:\"omitempty,gt=0\"`"},
+		{name: "should use pointers for optional references", want: "Parent *Pet"},
+		{name: "should declare path parameters", want: "PetId string `json:\"petId\" validate:\"required,uuid\"`"},
+		{name: "should declare query parameters", want: "Fields []string `json:\"fields,omitempty\" validate:\"omitempty,max=3\"`"},
+		{name: "should map constraints of items after dive", want: "Sort   []string `json:\"sort,omitempty\" validate:\"omitempty,dive,min=2,oneof=name -name\"`"},
+		{name: "should note patterns of items", want: "// pattern ^[a-z]+$ of the items cannot be validated by validate tags"},
+		{name: "should register the operation", want: "router.GET(\"/pets/{petId}\", HandleGetPet)."},
+		{name: "should keep the operation id", want: "OperationID(\"getPet\")."},
+		{name: "should register responses", want: "Response(200, Pet{}, \"Pet found\")."},
+		{name: "should register responses without content", want: "Response(404, nil, \"Pet not found\")"},
+		{name: "should declare handler stubs", want: "func HandleGetPet(w http.ResponseWriter, r *http.Request) {"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(src, tt.want) {
+				t.Errorf("scaffold does not contain %s:\n%s", tt.want, src)
+			}
+		})
+	}
+}
+
+func TestSchemaAnnotators(t *testing.T) {
+	type annotated struct {
+		Name  string   `json:"name" validate:"min=1,max=64"`
+		Code  string   `json:"code" validate:"len=3"`
+		Kind  string   `json:"kind" validate:"oneof=cat dog 'sea lion'"`
+		Email string   `json:"email" validate:"omitempty,email"`
+		Tags  []string `json:"tags" validate:"max=5,dive,min=2"`
+		Age   int      `json:"age" validate:"gt=0,lte=150"`
+		Level int      `json:"level" validate:"oneof=1 2 3"`
+		Zip   string   `json:"zip" validate:"numeric"`
+	}
+
+	ref, err := NewSchemaRefGenerator().GenerateSchemaRef(annotated{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties := ref.Value.Properties
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{name: "min on strings should be minLength", got: properties["name"].Value.MinLength, want: uint64(1)},
+		{name: "max on strings should be maxLength", got: *properties["name"].Value.MaxLength, want: uint64(64)},
+		{name: "len on strings should be min and maxLength", got: [2]uint64{properties["code"].Value.MinLength, *properties["code"].Value.MaxLength}, want: [2]uint64{3, 3}},
+		{name: "oneof should be enum", got: len(properties["kind"].Value.Enum), want: 3},
+		{name: "oneof should support quoted values", got: properties["kind"].Value.Enum[2], want: "sea lion"},
+		{name: "oneof on numbers should be numeric enum", got: properties["level"].Value.Enum[0], want: float64(1)},
+		{name: "email should be a format", got: properties["email"].Value.Format, want: "email"},
+		{name: "max on arrays should be maxItems", got: *properties["tags"].Value.MaxItems, want: uint64(5)},
+		{name: "operators after dive should not apply to arrays", got: properties["tags"].Value.MinItems, want: uint64(0)},
+		{name: "numeric on strings should be a pattern", got: properties["zip"].Value.Pattern, want: `^[-+]?[0-9]+(?:\.[0-9]+)?$`},
+		{name: "gt on numbers should be exclusive minimum", got: properties["age"].Value.ExclusiveMin, want: true},
+		{name: "lte on numbers should be maximum", got: *properties["age"].Value.Max, want: float64(150)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package specs
 import (
 	"fmt"
 	"log"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -17,7 +19,7 @@ var defaultParentSchemaAnnotatorMap = map[string]ParentSchemaAnnotatorFunc{
 type ParentSchemaAnnotatorFunc func(field *Field, schema *openapi3.Schema)
 
 func requiredAnnotator(field *Field, schema *openapi3.Schema) {
-	if schema.Required != nil {
+	if schema.Required == nil {
 		schema.Required = []string{}
 	}
 	schema.Required = append(schema.Required, field.Name)
@@ -30,6 +32,7 @@ func requiredAnnotator(field *Field, schema *openapi3.Schema) {
 var defaultSchemaAnnotatorMap = map[string]SchemaAnnotatorFunc{
 	"min":                           minAnnotator,
 	"max":                           maxAnnotator,
+	"len":                           lenAnnotator,
 	"eq":                            warnAnnotator,
 	"eq_ignore_case":                warnAnnotator,
 	"ne":                            warnAnnotator,
@@ -38,24 +41,24 @@ var defaultSchemaAnnotatorMap = map[string]SchemaAnnotatorFunc{
 	"lte":                           lteAnnotator,
 	"gt":                            gtAnnotator,
 	"gte":                           gteAnnotator,
-	"alpha":                         warnAnnotator,
-	"alphanum":                      warnAnnotator,
+	"alpha":                         patternAnnotator(`^[a-zA-Z]+$`),
+	"alphanum":                      patternAnnotator(`^[a-zA-Z0-9]+$`),
 	"alphaunicode":                  warnAnnotator,
 	"alphanumunicode":               warnAnnotator,
 	"boolean":                       noopAnnotator,
-	"numeric":                       warnAnnotator,
-	"number":                        warnAnnotator,
-	"hexadecimal":                   warnAnnotator,
+	"numeric":                       patternAnnotator(`^[-+]?[0-9]+(?:\.[0-9]+)?$`),
+	"number":                        patternAnnotator(`^[0-9]+$`),
+	"hexadecimal":                   patternAnnotator(`^(0[xX])?[0-9a-fA-F]+$`),
 	"hexcolor":                      warnAnnotator,
 	"rgb":                           warnAnnotator,
 	"rgba":                          warnAnnotator,
 	"hsl":                           warnAnnotator,
 	"hsla":                          warnAnnotator,
 	"e164":                          warnAnnotator,
-	"email":                         warnAnnotator,
-	"url":                           warnAnnotator,
+	"email":                         formatAnnotator("email"),
+	"url":                           formatAnnotator("uri"),
 	"http_url":                      warnAnnotator,
-	"uri":                           warnAnnotator,
+	"uri":                           formatAnnotator("uri"),
 	"urn_rfc2141":                   warnAnnotator, // RFC 2141
 	"file":                          warnAnnotator,
 	"filepath":                      warnAnnotator,
@@ -75,7 +78,7 @@ var defaultSchemaAnnotatorMap = map[string]SchemaAnnotatorFunc{
 	"isbn":                          warnAnnotator,
 	"isbn10":                        warnAnnotator,
 	"isbn13":                        warnAnnotator,
-	"uuid":                          warnAnnotator,
+	"uuid":                          formatAnnotator("uuid"),
 	"uuid3":                         warnAnnotator,
 	"uuid4":                         warnAnnotator,
 	"uuid5":                         warnAnnotator,
@@ -101,8 +104,8 @@ var defaultSchemaAnnotatorMap = map[string]SchemaAnnotatorFunc{
 	"latitude":                      warnAnnotator,
 	"longitude":                     warnAnnotator,
 	"ssn":                           warnAnnotator,
-	"ipv4":                          warnAnnotator,
-	"ipv6":                          warnAnnotator,
+	"ipv4":                          formatAnnotator("ipv4"),
+	"ipv6":                          formatAnnotator("ipv6"),
 	"ip":                            warnAnnotator,
 	"cidrv4":                        warnAnnotator,
 	"cidrv6":                        warnAnnotator,
@@ -118,11 +121,11 @@ var defaultSchemaAnnotatorMap = map[string]SchemaAnnotatorFunc{
 	"ip_addr":                       warnAnnotator,
 	"unix_addr":                     warnAnnotator,
 	"mac":                           warnAnnotator,
-	"hostname":                      warnAnnotator, // RFC 952
-	"hostname_rfc1123":              warnAnnotator, // RFC 1123
+	"hostname":                      formatAnnotator("hostname"), // RFC 952
+	"hostname_rfc1123":              warnAnnotator,               // RFC 1123
 	"fqdn":                          warnAnnotator,
 	"unique":                        warnAnnotator,
-	"oneof":                         warnAnnotator,
+	"oneof":                         oneOfAnnotator,
 	"html":                          warnAnnotator,
 	"html_encoded":                  warnAnnotator,
 	"url_encoded":                   warnAnnotator,
@@ -163,22 +166,38 @@ var (
 	noopAnnotator = func(fieldTag *FieldTag, schema *openapi3.Schema) {}
 )
 
+// minAnnotator applies the lower bound to the length of strings, the number of items of arrays and
+// properties of maps or the value of numbers, just like the validator does.
 func minAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
 	gteAnnotator(fieldTag, schema)
 }
 
 func gteAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
-	i, err := strconv.ParseInt(fieldTag.Param, 10, 64)
-	if err != nil {
-		panic(fmt.Errorf("failed to parse %s as int64: %w", fieldTag.Param, err))
+	switch schema.Type {
+	case "string":
+		schema.MinLength = parseUint64Param(fieldTag)
+	case "array":
+		schema.MinItems = parseUint64Param(fieldTag)
+	case "object":
+		schema.MinProps = parseUint64Param(fieldTag)
+	default:
+		f := parseFloat64Param(fieldTag)
+		schema.Min = &f
 	}
-	f := float64(i)
-	schema.Min = &f
 }
 
 func gtAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
-	gteAnnotator(fieldTag, schema)
-	schema.ExclusiveMin = true
+	switch schema.Type {
+	case "string":
+		schema.MinLength = parseUint64Param(fieldTag) + 1
+	case "array":
+		schema.MinItems = parseUint64Param(fieldTag) + 1
+	case "object":
+		schema.MinProps = parseUint64Param(fieldTag) + 1
+	default:
+		gteAnnotator(fieldTag, schema)
+		schema.ExclusiveMin = true
+	}
 }
 
 func maxAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
@@ -186,15 +205,89 @@ func maxAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
 }
 
 func lteAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
-	i, err := strconv.ParseInt(fieldTag.Param, 10, 64)
-	if err != nil {
-		panic(fmt.Errorf("failed to parse %s as int64: %w", fieldTag.Param, err))
+	switch schema.Type {
+	case "string":
+		max := parseUint64Param(fieldTag)
+		schema.MaxLength = &max
+	case "array":
+		max := parseUint64Param(fieldTag)
+		schema.MaxItems = &max
+	case "object":
+		max := parseUint64Param(fieldTag)
+		schema.MaxProps = &max
+	default:
+		f := parseFloat64Param(fieldTag)
+		schema.Max = &f
 	}
-	f := float64(i)
-	schema.Max = &f
 }
 
 func ltAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
-	lteAnnotator(fieldTag, schema)
-	schema.ExclusiveMax = true
+	switch schema.Type {
+	case "string", "array", "object":
+		if parseUint64Param(fieldTag) == 0 {
+			panic(fmt.Errorf("lt=0 cannot be satisfied by a length"))
+		}
+		lteAnnotator(&FieldTag{Operator: "lte", Param: strconv.FormatUint(parseUint64Param(fieldTag)-1, 10)}, schema)
+	default:
+		lteAnnotator(fieldTag, schema)
+		schema.ExclusiveMax = true
+	}
+}
+
+func lenAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
+	switch schema.Type {
+	case "string", "array", "object":
+		gteAnnotator(fieldTag, schema)
+		lteAnnotator(fieldTag, schema)
+	default:
+		warnAnnotator(fieldTag, schema)
+	}
+}
+
+// formatAnnotator sets the format of string schemas.
+func formatAnnotator(format string) SchemaAnnotatorFunc {
+	return func(fieldTag *FieldTag, schema *openapi3.Schema) {
+		schema.Format = format
+	}
+}
+
+// patternAnnotator sets the pattern of string schemas to the regular expression used by the validator.
+func patternAnnotator(pattern string) SchemaAnnotatorFunc {
+	return func(fieldTag *FieldTag, schema *openapi3.Schema) {
+		if schema.Type == "string" {
+			schema.Pattern = pattern
+		}
+	}
+}
+
+var oneOfParamRegex = regexp.MustCompile(`'[^']*'|\S+`)
+
+// oneOfAnnotator declares the values of oneof as enum. Values containing spaces are quoted with
+// single quotes, just like the validator expects them.
+func oneOfAnnotator(fieldTag *FieldTag, schema *openapi3.Schema) {
+	for _, value := range oneOfParamRegex.FindAllString(fieldTag.Param, -1) {
+		value = strings.Trim(value, "'")
+		switch schema.Type {
+		case "integer", "number":
+			schema.Enum = append(schema.Enum, parseFloat64Param(&FieldTag{Operator: fieldTag.Operator, Param: value}))
+		default:
+			schema.Enum = append(schema.Enum, value)
+		}
+	}
+}
+
+func parseUint64Param(fieldTag *FieldTag) uint64 {
+	i, err := strconv.ParseUint(fieldTag.Param, 10, 64)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse %s of %s as uint64: %w", fieldTag.Param, fieldTag.Operator, err))
+	}
+	return i
+}
+
+func parseFloat64Param(fieldTag *FieldTag) float64 {
+	f, err := strconv.ParseFloat(fieldTag.Param, 64)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse %s of %s as float64: %w", fieldTag.Param, fieldTag.Operator, err))
+	}
+	return f
 }
@@ -0,0 +1,25 @@
+package specs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestRequiredAnnotator(t *testing.T) {
+	// the required list used to be reset whenever it was not empty, only the last required field
+	// was declared
+	ref, err := NewSchemaRefGenerator().GenerateSchemaRef(struct {
+		ID    string `json:"id" validate:"required"`
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"required,email"`
+		Nick  string `json:"nick"`
+	}{}, openapi3.Schemas{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"email", "id", "name"}; !reflect.DeepEqual(ref.Value.Required, want) {
+		t.Errorf("got %v, want %v", ref.Value.Required, want)
+	}
+}
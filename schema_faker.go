@@ -0,0 +1,272 @@
+package specs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	fakerMaxDepth = 6
+	fakerMaxItems = 3
+)
+
+var (
+	fakerWords = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet"}
+	fakerEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// SchemaFaker synthesizes values that satisfy an openapi3.Schema. Examples declared
+// on the schema are preferred, otherwise type-aware fake data is generated. The output
+// is deterministic for a given seed.
+type SchemaFaker struct {
+	rand *rand.Rand
+}
+
+func NewSchemaFaker(seed int64) *SchemaFaker {
+	return &SchemaFaker{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (f *SchemaFaker) Fake(schemaRef *openapi3.SchemaRef) interface{} {
+	return f.fake(schemaRef, 0)
+}
+
+func (f *SchemaFaker) fake(schemaRef *openapi3.SchemaRef, depth int) interface{} {
+	if schemaRef == nil || schemaRef.Value == nil || depth > fakerMaxDepth {
+		return nil
+	}
+	schema := schemaRef.Value
+
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[f.rand.Intn(len(schema.Enum))]
+	}
+	if len(schema.OneOf) > 0 {
+		return f.fake(schema.OneOf[0], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return f.fake(schema.AnyOf[0], depth+1)
+	}
+	if len(schema.AllOf) > 0 {
+		merged := map[string]interface{}{}
+		for _, s := range schema.AllOf {
+			if v, ok := f.fake(s, depth+1).(map[string]interface{}); ok {
+				for key, value := range v {
+					merged[key] = value
+				}
+			}
+		}
+		return merged
+	}
+
+	switch schema.Type {
+	case "boolean":
+		return f.rand.Intn(2) == 1
+	case "integer":
+		return math.Round(f.fakeNumber(schema, 1))
+	case "number":
+		return f.fakeNumber(schema, 0.5)
+	case "string":
+		return f.fakeString(schema)
+	case "array":
+		n := int(schema.MinItems)
+		if n == 0 {
+			n = 1 + f.rand.Intn(fakerMaxItems)
+		}
+		if schema.MaxItems != nil && uint64(n) > *schema.MaxItems {
+			n = int(*schema.MaxItems)
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			items = append(items, f.fake(schema.Items, depth+1))
+		}
+		return items
+	case "object", "":
+		if schema.Type == "" && len(schema.Properties) == 0 && schema.AdditionalProperties.Schema == nil {
+			return nil
+		}
+		return f.fakeObject(schema, depth)
+	}
+	return nil
+}
+
+func (f *SchemaFaker) fakeObject(schema *openapi3.Schema, depth int) map[string]interface{} {
+	object := make(map[string]interface{}, len(schema.Properties))
+
+	// properties are visited in a stable order to keep the output deterministic
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if value := f.fake(schema.Properties[name], depth+1); value != nil {
+			object[name] = value
+		}
+	}
+	if schema.AdditionalProperties.Schema != nil && len(names) == 0 {
+		object[fakerWords[f.rand.Intn(len(fakerWords))]] = f.fake(schema.AdditionalProperties.Schema, depth+1)
+	}
+	return object
+}
+
+func (f *SchemaFaker) fakeNumber(schema *openapi3.Schema, step float64) float64 {
+	min, max := float64(0), float64(1000)
+	if schema.Min != nil {
+		min = *schema.Min
+		if schema.ExclusiveMin {
+			min += step
+		}
+	}
+	if schema.Max != nil {
+		max = *schema.Max
+		if schema.ExclusiveMax {
+			max -= step
+		}
+	}
+	if schema.Min != nil && schema.Max == nil {
+		max = min + 1000
+	}
+	if schema.Max != nil && schema.Min == nil {
+		min = max - 1000
+	}
+	if max <= min {
+		return min
+	}
+	if step == 1 {
+		min, max = math.Ceil(min), math.Floor(max)
+		if max <= min {
+			return min
+		}
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		// pick one of the multiples within [min, max] so rounding never leaves the bounds
+		m := *schema.MultipleOf
+		lo, hi := math.Ceil(min/m), math.Floor(max/m)
+		if hi < lo {
+			return lo * m
+		}
+		return (lo + math.Floor(f.rand.Float64()*(hi-lo+1))) * m
+	}
+	return min + f.rand.Float64()*(max-min)
+}
+
+func (f *SchemaFaker) fakeString(schema *openapi3.Schema) string {
+	switch schema.Format {
+	case "date-time":
+		return fakerEpoch.Add(time.Duration(f.rand.Int63n(int64(365 * 24 * time.Hour)))).Format(time.RFC3339)
+	case "date":
+		return fakerEpoch.AddDate(0, 0, f.rand.Intn(365)).Format("2006-01-02")
+	case "email":
+		return fmt.Sprintf("%s@example.com", fakerWords[f.rand.Intn(len(fakerWords))])
+	case "uuid":
+		b := make([]byte, 16)
+		f.rand.Read(b)
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "uri", "url":
+		return fmt.Sprintf("https://example.com/%s", fakerWords[f.rand.Intn(len(fakerWords))])
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+f.rand.Intn(254))
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(fakerWords[f.rand.Intn(len(fakerWords))]))
+	}
+
+	if schema.Pattern != "" {
+		if value, ok := f.fakePattern(schema.Pattern); ok {
+			return value
+		}
+	}
+
+	value := fakerWords[f.rand.Intn(len(fakerWords))]
+	for uint64(len(value)) < schema.MinLength {
+		value += fakerWords[f.rand.Intn(len(fakerWords))]
+	}
+	if schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+// fakePattern generates a string matching the regular expression pattern by walking its syntax tree.
+// Unbounded repetitions are capped at fakerMaxItems.
+func (f *SchemaFaker) fakePattern(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if !f.fakeRegexp(&b, re.Simplify()) {
+		return "", false
+	}
+	return b.String(), true
+}
+
+func (f *SchemaFaker) fakeRegexp(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+		return true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(fakerWords[f.rand.Intn(len(fakerWords))][0])
+		return true
+	case syntax.OpCharClass:
+		// re.Rune holds inclusive ranges as pairs [lo, hi, lo, hi, ...]
+		if len(re.Rune) == 0 {
+			return false
+		}
+		i := 2 * f.rand.Intn(len(re.Rune)/2)
+		lo, hi := re.Rune[i], re.Rune[i+1]
+		if hi > lo+0x7f {
+			hi = lo + 0x7f
+		}
+		b.WriteRune(lo + rune(f.rand.Intn(int(hi-lo)+1)))
+		return true
+	case syntax.OpCapture:
+		return f.fakeRegexp(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !f.fakeRegexp(b, sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		return f.fakeRegexp(b, re.Sub[f.rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max == -1 {
+			max = min + fakerMaxItems
+		}
+		for n := min + f.rand.Intn(max-min+1); n > 0; n-- {
+			if !f.fakeRegexp(b, re.Sub[0]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
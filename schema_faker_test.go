@@ -0,0 +1,60 @@
+package specs
+
+import (
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestSchemaFakerNumbers(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *openapi3.Schema
+	}{
+		{name: "multiple of within bounds", schema: withMultipleOf(openapi3.NewFloat64Schema().WithMin(0).WithMax(10), 3)},
+		{name: "multiple of close to the maximum", schema: withMultipleOf(openapi3.NewFloat64Schema().WithMin(9.5).WithMax(10.4), 0.5)},
+		{name: "integer multiple of", schema: withMultipleOf(openapi3.NewIntegerSchema().WithMin(1).WithMax(99), 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewSchemaFaker(1)
+			for i := 0; i < 200; i++ {
+				value := f.Fake(tt.schema.NewRef()).(float64)
+				if value < *tt.schema.Min || value > *tt.schema.Max {
+					t.Fatalf("%v out of [%v, %v]", value, *tt.schema.Min, *tt.schema.Max)
+				}
+				if q := value / *tt.schema.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+					t.Fatalf("%v is not a multiple of %v", value, *tt.schema.MultipleOf)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaFakerPattern(t *testing.T) {
+	patterns := []string{
+		`^[A-Z]{3}-[0-9]{4}$`,
+		`^(foo|bar)+\.txt$`,
+		`^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+		`^\w+@example\.com$`,
+	}
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			f := NewSchemaFaker(1)
+			re := regexp.MustCompile(pattern)
+			schema := openapi3.NewStringSchema().WithPattern(pattern)
+			for i := 0; i < 50; i++ {
+				if value := f.Fake(schema.NewRef()).(string); !re.MatchString(value) {
+					t.Fatalf("%q does not match %s", value, pattern)
+				}
+			}
+		})
+	}
+}
+
+func withMultipleOf(schema *openapi3.Schema, multipleOf float64) *openapi3.Schema {
+	schema.MultipleOf = &multipleOf
+	return schema
+}
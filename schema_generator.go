@@ -44,6 +44,8 @@ type SchemaRefGeneratorOption func(*schemaRefGeneratorOption)
 
 type schemaRefGeneratorOption struct {
 	throwErrorOnCycle bool
+	formFieldNames    bool
+	xmlNames          bool
 	typeInfoCache     *TypeInfoCache
 
 	schemaAnnotatorMap       map[string]SchemaAnnotatorFunc
@@ -57,6 +59,21 @@ func ThrowErrorOnCycle() SchemaRefGeneratorOption {
 	}
 }
 
+// FormFieldNames names the properties by the form tag of the fields, as used by form payloads.
+func FormFieldNames() SchemaRefGeneratorOption {
+	return func(opt *schemaRefGeneratorOption) {
+		opt.formFieldNames = true
+	}
+}
+
+// XMLNames names the properties by the xml tag of the fields and annotates them with the xml object,
+// as used by XML media types. Character data, inner XML and comments are not documented.
+func XMLNames() SchemaRefGeneratorOption {
+	return func(opt *schemaRefGeneratorOption) {
+		opt.xmlNames = true
+	}
+}
+
 func SchemaAnnotatorMap(schemaAnnotatorMap map[string]SchemaAnnotatorFunc) SchemaRefGeneratorOption {
 	return func(opt *schemaRefGeneratorOption) {
 		opt.schemaAnnotatorMap = schemaAnnotatorMap
@@ -85,8 +102,9 @@ type SchemaRefGenerator struct {
 	// An OpenAPI identifier has been assigned to each.
 	SchemaRefs map[*openapi3.SchemaRef]int
 
-	// componentSchemaRefs is a set of schemas that must be defined in the components to avoid cycles
-	componentSchemaRefs map[string]struct{}
+	// componentSchemaRefs maps the type names of schemas that must be defined in the components to
+	// avoid cycles to their component names
+	componentSchemaRefs map[string]string
 }
 
 func NewSchemaRefGenerator(opts ...SchemaRefGeneratorOption) *SchemaRefGenerator {
@@ -107,9 +125,10 @@ func NewSchemaRefGenerator(opts ...SchemaRefGeneratorOption) *SchemaRefGenerator
 	}
 
 	return &SchemaRefGenerator{
-		Types:      make(map[reflect.Type]*openapi3.SchemaRef),
-		SchemaRefs: make(map[*openapi3.SchemaRef]int),
-		options:    *options,
+		Types:               make(map[reflect.Type]*openapi3.SchemaRef),
+		SchemaRefs:          make(map[*openapi3.SchemaRef]int),
+		componentSchemaRefs: make(map[string]string),
+		options:             *options,
 	}
 }
 
@@ -128,12 +147,15 @@ func (g *SchemaRefGenerator) GenerateSchemaRef(v interface{}, schemas openapi3.S
 		return nil, err
 	}
 	if ref != nil {
+		if g.options.xmlNames && ref.Value != nil && ref.Value.Type == "object" {
+			ref.Value.XML = &openapi3.XML{Name: xmlElementName(removeIndirect(t))}
+		}
 		g.Types[t] = ref
 		g.SchemaRefs[ref]++
 	}
 	for ref := range g.SchemaRefs {
-		if _, ok := g.componentSchemaRefs[ref.Ref]; ok && schemas != nil {
-			schemas[ref.Ref] = &openapi3.SchemaRef{
+		if name, ok := g.componentSchemaRefs[ref.Ref]; ok && schemas != nil {
+			schemas[name] = &openapi3.SchemaRef{
 				Value: ref.Value,
 			}
 		}
@@ -148,15 +170,10 @@ func (g *SchemaRefGenerator) GenerateSchemaRef(v interface{}, schemas openapi3.S
 
 func (g *SchemaRefGenerator) generateSchemaRef(parents []*TypeInfo, t reflect.Type, name string, parentField *Field) (*openapi3.SchemaRef, error) {
 	typeInfo := g.options.typeInfoCache.GetTypeInfo(t)
-	for _, parent := range parents {
-		if parent == typeInfo {
-			return nil, ErrCycleDetected
-		}
-	}
-	if cap(parents) == 0 {
-		parents = make([]*TypeInfo, 0, 4)
+	parents, err := appendParent(parents, typeInfo)
+	if err != nil {
+		return nil, err
 	}
-	parents = append(parents, typeInfo)
 
 	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -281,8 +298,20 @@ func (g *SchemaRefGenerator) generateSchemaRef(parents []*TypeInfo, t reflect.Ty
 		case timeType:
 			schema.Type = "string"
 			schema.Format = "date-time"
+		case fileHeaderType, fileType:
+			schema.Type = "string"
+			schema.Format = "binary"
 		default:
 			for _, fieldInfo := range typeInfo.Fields {
+				if g.options.formFieldNames {
+					fieldInfo.Name = fieldInfo.FormName()
+				}
+				if g.options.xmlNames {
+					if !fieldInfo.documentedInXML() {
+						continue
+					}
+					fieldInfo.Name = fieldInfo.XML_Name
+				}
 				fieldName, fType := fieldInfo.Name, fieldInfo.Type
 				ref, err := g.generateSchemaRef(parents, fType, fieldName, &fieldInfo)
 				if err != nil {
@@ -297,7 +326,12 @@ func (g *SchemaRefGenerator) generateSchemaRef(parents []*TypeInfo, t reflect.Ty
 				}
 
 				g.SchemaRefs[ref]++
-				schema.WithPropertyRef(fieldName, ref)
+				parentSchema := schema
+				if g.options.xmlNames {
+					parentSchema = withXMLPropertyRef(schema, &fieldInfo, ref)
+				} else {
+					schema.WithPropertyRef(fieldName, ref)
+				}
 				createFieldTagWalker(fieldInfo.fieldInfo_Validator).Walk(func(fieldTag *FieldTag) {
 					applyAnnotation, hasAnnotator := g.options.parentSchemaAnnotatorMap[fieldTag.Operator]
 					if !hasAnnotator {
@@ -306,7 +340,7 @@ func (g *SchemaRefGenerator) generateSchemaRef(parents []*TypeInfo, t reflect.Ty
 						}
 						return
 					}
-					applyAnnotation(&fieldInfo, schema)
+					applyAnnotation(&fieldInfo, parentSchema)
 				})
 			}
 
@@ -339,6 +373,20 @@ func (g *SchemaRefGenerator) generateSchemaRef(parents []*TypeInfo, t reflect.Ty
 	return openapi3.NewSchemaRef(t.Name(), schema), nil
 }
 
+// appendParent appends typeInfo to the types enclosing the type being generated and fails with
+// ErrCycleDetected if it already encloses it.
+func appendParent(parents []*TypeInfo, typeInfo *TypeInfo) ([]*TypeInfo, error) {
+	for _, parent := range parents {
+		if parent == typeInfo {
+			return nil, ErrCycleDetected
+		}
+	}
+	if cap(parents) == 0 {
+		parents = make([]*TypeInfo, 0, 4)
+	}
+	return append(parents, typeInfo), nil
+}
+
 func (g *SchemaRefGenerator) generateCycleSchemaRef(t reflect.Type, schema *openapi3.Schema) *openapi3.SchemaRef {
 	var typeName string
 	switch t.Kind() {
@@ -357,9 +405,86 @@ func (g *SchemaRefGenerator) generateCycleSchemaRef(t reflect.Type, schema *open
 		mapSchema.AdditionalProperties = openapi3.AdditionalProperties{Schema: ref}
 		return openapi3.NewSchemaRef("", mapSchema)
 	default:
-		typeName = t.Name()
+		typeName = schemaTypeName(t)
 	}
 
-	g.componentSchemaRefs[typeName] = struct{}{}
+	// XML schemas are named differently, they are declared next to the JSON schemas
+	if g.options.xmlNames {
+		typeName += "XML"
+	}
+	g.componentSchemaRefs[t.Name()] = typeName
 	return openapi3.NewSchemaRef(fmt.Sprintf("#/components/schemas/%s", typeName), schema)
 }
+
+// schemaTypeName returns the name of t used for components and generated types. Generic types are
+// named by their type arguments followed by their name, e.g. Page[api.User] becomes UserPage.
+func schemaTypeName(t reflect.Type) string {
+	return cleanTypeName(t.Name())
+}
+
+func cleanTypeName(name string) string {
+	switch {
+	case strings.HasPrefix(name, "*"):
+		return cleanTypeName(name[1:])
+	case strings.HasPrefix(name, "map["):
+		key, value := splitMapTypeName(name)
+		return goIdentifier(cleanTypeName(key), true) + goIdentifier(cleanTypeName(value), true) + "Map"
+	case strings.HasPrefix(name, "["):
+		return goIdentifier(cleanTypeName(name[strings.IndexByte(name, ']')+1:]), true) + "List"
+	}
+
+	base, args := name, ""
+	if open := strings.IndexByte(name, '['); open != -1 && strings.HasSuffix(name, "]") {
+		base, args = name[:open], name[open+1:len(name)-1]
+	}
+	base = base[strings.LastIndexByte(base, '.')+1:]
+	if args == "" {
+		return base
+	}
+
+	var b strings.Builder
+	for _, arg := range splitTypeArgs(args) {
+		b.WriteString(goIdentifier(cleanTypeName(arg), true))
+	}
+	return b.String() + base
+}
+
+// splitTypeArgs splits the type arguments of a generic type name at the top level commas.
+func splitTypeArgs(args string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, r := range args {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(args[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(args[start:]))
+}
+
+// splitMapTypeName returns the key and value type names of map[K]V.
+func splitMapTypeName(name string) (string, string) {
+	depth := 0
+	for i := len("map"); i < len(name); i++ {
+		switch name[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return name[len("map["):i], name[i+1:]
+			}
+		}
+	}
+	return name, ""
+}
@@ -0,0 +1,28 @@
+package specs
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type schemaGeneratorTestNode struct {
+	Name     string                     `json:"name"`
+	Children []*schemaGeneratorTestNode `json:"children"`
+}
+
+func TestGenerateSchemaRefCycles(t *testing.T) {
+	// the components of cycles used to be recorded in a map that NewSchemaRefGenerator left nil,
+	// generating the first cyclic type panicked
+	schemas := openapi3.Schemas{}
+	ref, err := NewSchemaRefGenerator().GenerateSchemaRef(schemaGeneratorTestNode{}, schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ref.Value.Properties["children"].Value.Items.Ref; got != "#/components/schemas/schemaGeneratorTestNode" {
+		t.Errorf("got ref %s", got)
+	}
+	if component := schemas["schemaGeneratorTestNode"]; component == nil || component.Value.Properties["name"] == nil {
+		t.Errorf("got components %v", schemas)
+	}
+}
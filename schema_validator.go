@@ -0,0 +1,119 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// StructValidator validates decoded values, e.g. the *validator.Validate of
+// github.com/go-playground/validator, which supports custom validations.
+type StructValidator interface {
+	Struct(s interface{}) error
+}
+
+// SchemaValidator validates json documents against the schemas generated for Go types, i.e. against
+// the documented contract of the types rather than their validate tags.
+type SchemaValidator struct {
+	mu    sync.Mutex
+	cache *TypeInfoCache
+	types map[reflect.Type]*openapi3.Schema
+}
+
+// NewSchemaValidator returns a SchemaValidator, which generates the schema of each type once.
+func NewSchemaValidator() *SchemaValidator {
+	return &SchemaValidator{
+		cache: NewTypeInfoCache(),
+		types: map[reflect.Type]*openapi3.Schema{},
+	}
+}
+
+// ValidateJSON validates the json document data against the schema of t. Documents of interface
+// types are not validated.
+func (v *SchemaValidator) ValidateJSON(t reflect.Type, data []byte) error {
+	t = removeIndirect(t)
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	schema, err := v.schema(t)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return schema.VisitJSON(value, openapi3.VisitAsRequest(), openapi3.SetSchemaErrorMessageCustomizer(schemaErrorMessage))
+}
+
+// schemaErrorMessage formats the error as the json pointer of the value and the reason, omitting
+// the schema and the value.
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	if err.Origin != nil || err.Reason == "" {
+		return ""
+	}
+	if path := err.JSONPointer(); len(path) > 0 {
+		return "/" + strings.Join(path, "/") + ": " + err.Reason
+	}
+	return err.Reason
+}
+
+func (v *SchemaValidator) schema(t reflect.Type) (*openapi3.Schema, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if schema, ok := v.types[t]; ok {
+		return schema, nil
+	}
+	// a generator unsets the values of the references it emitted before on every call, which would
+	// undo the resolution of the schemas of other types
+	schemas := make(openapi3.Schemas)
+	schemaRef, err := NewSchemaRefGenerator(WithTypeInfoCache(v.cache)).GenerateSchemaRef(reflect.New(t).Elem().Interface(), schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the schema of %v: %w", t, err)
+	}
+	schema := ResolveSchemaRefs(schemaRef, schemas).Value
+	v.types[t] = schema
+	return schema, nil
+}
+
+// ResolveSchemaRefs sets the values of the references to the components of schemas within ref, the
+// SchemaRefGenerator only sets the references of named types to avoid cycles. Each call of
+// GenerateSchemaRef unsets the values again.
+func ResolveSchemaRefs(ref *openapi3.SchemaRef, schemas openapi3.Schemas) *openapi3.SchemaRef {
+	return resolveSchemaRefs(ref, schemas, map[*openapi3.SchemaRef]struct{}{})
+}
+
+func resolveSchemaRefs(ref *openapi3.SchemaRef, schemas openapi3.Schemas, visited map[*openapi3.SchemaRef]struct{}) *openapi3.SchemaRef {
+	if ref == nil {
+		return nil
+	}
+	if _, ok := visited[ref]; ok {
+		return ref
+	}
+	visited[ref] = struct{}{}
+	if ref.Value == nil {
+		if component, ok := schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; ok {
+			ref.Value = resolveSchemaRefs(component, schemas, visited).Value
+		}
+		return ref
+	}
+
+	schema := ref.Value
+	for _, property := range schema.Properties {
+		resolveSchemaRefs(property, schemas, visited)
+	}
+	for _, refs := range []openapi3.SchemaRefs{schema.OneOf, schema.AnyOf, schema.AllOf} {
+		for _, ref := range refs {
+			resolveSchemaRefs(ref, schemas, visited)
+		}
+	}
+	resolveSchemaRefs(schema.Items, schemas, visited)
+	resolveSchemaRefs(schema.AdditionalProperties.Schema, schemas, visited)
+	resolveSchemaRefs(schema.Not, schemas, visited)
+	return ref
+}
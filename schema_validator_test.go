@@ -0,0 +1,43 @@
+package specs
+
+import (
+	"reflect"
+	"testing"
+)
+
+type schemaValidatorTestNode struct {
+	Name     string                     `json:"name" validate:"required,max=4"`
+	Children []*schemaValidatorTestNode `json:"children,omitempty"`
+}
+
+type schemaValidatorTestList struct {
+	Value int                      `json:"value"`
+	Next  *schemaValidatorTestList `json:"next,omitempty"`
+}
+
+func TestSchemaValidator(t *testing.T) {
+	v := NewSchemaValidator()
+	nodeType := reflect.TypeOf(schemaValidatorTestNode{})
+	listType := reflect.TypeOf(schemaValidatorTestList{})
+
+	tests := []struct {
+		name    string
+		t       reflect.Type
+		data    string
+		wantErr bool
+	}{
+		{name: "should accept valid documents", t: nodeType, data: `{"name":"a","children":[{"name":"b"}]}`},
+		{name: "should reject invalid nested documents", t: nodeType, data: `{"name":"a","children":[{"name":"bbbbb"}]}`, wantErr: true},
+		{name: "should validate other types", t: listType, data: `{"value":1,"next":{"value":2}}`},
+		{name: "should keep the schemas of validated types", t: nodeType, data: `{"name":"a","children":[{"name":"b"}]}`},
+		{name: "should reject invalid nested documents of validated types", t: nodeType, data: `{"name":"a","children":[{"name":"bbbbb"}]}`, wantErr: true},
+		{name: "should accept documents of interface types", t: reflect.TypeOf((*interface{})(nil)).Elem(), data: `1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.ValidateJSON(tt.t, []byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
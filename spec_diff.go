@@ -0,0 +1,572 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	ChangeOperationAdded       = "operation-added"
+	ChangeOperationRemoved     = "operation-removed"
+	ChangeOperationDeprecated  = "operation-deprecated"
+	ChangeOperationIDChanged   = "operation-id-changed"
+	ChangeOperationPathChanged = "operation-path-changed"
+	ChangeParameterAdded       = "parameter-added"
+	ChangeParameterRemoved     = "parameter-removed"
+	ChangeParameterRequired    = "parameter-required"
+	ChangeRequestBodyAdded     = "request-body-added"
+	ChangeRequestBodyRemoved   = "request-body-removed"
+	ChangeRequestBodyRequired  = "request-body-required"
+	ChangeMediaTypeAdded       = "media-type-added"
+	ChangeMediaTypeRemoved     = "media-type-removed"
+	ChangeResponseAdded        = "response-added"
+	ChangeResponseRemoved      = "response-removed"
+	ChangeTypeChanged          = "type-changed"
+	ChangeFormatChanged        = "format-changed"
+	ChangePropertyAdded        = "property-added"
+	ChangePropertyRemoved      = "property-removed"
+	ChangePropertyRequired     = "property-required"
+	ChangePropertyOptional     = "property-optional"
+	ChangeEnumNarrowed         = "enum-narrowed"
+	ChangeEnumWidened          = "enum-widened"
+	ChangeConstraintTightened  = "constraint-tightened"
+	ChangeConstraintLoosened   = "constraint-loosened"
+	ChangePatternChanged       = "pattern-changed"
+)
+
+const (
+	diffDirectionRequest  = "request"
+	diffDirectionResponse = "response"
+)
+
+// SpecChange is a single difference between two specs.
+type SpecChange struct {
+	Breaking    bool   `json:"breaking"`
+	Kind        string `json:"kind"`
+	Operation   string `json:"operation"`
+	OperationID string `json:"operationId,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Message     string `json:"message"`
+}
+
+func (c SpecChange) String() string {
+	label := "non-breaking"
+	if c.Breaking {
+		label = "BREAKING"
+	}
+	location := c.Operation
+	if c.Location != "" {
+		location += " " + c.Location
+	}
+	return fmt.Sprintf("%-12s %s: %s", label, location, c.Message)
+}
+
+// SpecDiff is the result of comparing a baseline spec with the current spec.
+type SpecDiff struct {
+	Changes []SpecChange `json:"changes"`
+}
+
+// Breaking reports whether at least one change breaks existing clients.
+func (d *SpecDiff) Breaking() bool {
+	for _, change := range d.Changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakingChanges returns only the changes that break existing clients.
+func (d *SpecDiff) BreakingChanges() []SpecChange {
+	changes := []SpecChange{}
+	for _, change := range d.Changes {
+		if change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// WriteText writes a human readable report with one change per line.
+func (d *SpecDiff) WriteText(w io.Writer) error {
+	if len(d.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+	for _, change := range d.Changes {
+		if _, err := fmt.Fprintln(w, change.String()); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d changes, %d breaking\n", len(d.Changes), len(d.BreakingChanges()))
+	return err
+}
+
+// WriteJSON writes a machine readable report.
+func (d *SpecDiff) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Breaking bool         `json:"breaking"`
+		Changes  []SpecChange `json:"changes"`
+	}{d.Breaking(), d.Changes})
+}
+
+type diffOperation struct {
+	method    string
+	path      string
+	key       string
+	operation *openapi3.Operation
+}
+
+func collectDiffOperations(t *openapi3.T) []diffOperation {
+	operations := []diffOperation{}
+	for path, pathItem := range t.Paths {
+		for method, operation := range pathItem.Operations() {
+			operations = append(operations, diffOperation{
+				method:    method,
+				path:      path,
+				key:       method + " " + parsePathTemplate(path).Normalized(),
+				operation: operation,
+			})
+		}
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].key < operations[j].key
+	})
+	return operations
+}
+
+type specDiffer struct {
+	changes []SpecChange
+	current diffOperation
+}
+
+func (d *specDiffer) add(breaking bool, kind string, location string, format string, args ...interface{}) {
+	d.changes = append(d.changes, SpecChange{
+		Breaking:    breaking,
+		Kind:        kind,
+		Operation:   d.current.method + " " + d.current.path,
+		OperationID: d.current.operation.OperationID,
+		Location:    location,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+// DiffSpecs compares the baseline spec with the current spec and classifies every change as breaking
+// or non-breaking for existing clients. Operations are matched by method and path template (ignoring
+// parameter names) first and by operation ID second, so renamed paths are recognized once operation
+// IDs are stable (see MethodPathOperationIDGenerator).
+func DiffSpecs(base *openapi3.T, current *openapi3.T) (*SpecDiff, error) {
+	base, err := resolvedOpenAPI3(base)
+	if err != nil {
+		return nil, err
+	}
+	current, err = resolvedOpenAPI3(current)
+	if err != nil {
+		return nil, err
+	}
+
+	baseOperations, currentOperations := collectDiffOperations(base), collectDiffOperations(current)
+	matched := map[int]bool{}
+
+	findCurrent := func(baseOperation diffOperation) int {
+		for i, currentOperation := range currentOperations {
+			if !matched[i] && currentOperation.key == baseOperation.key {
+				return i
+			}
+		}
+		if baseOperation.operation.OperationID == "" {
+			return -1
+		}
+		for i, currentOperation := range currentOperations {
+			if !matched[i] && currentOperation.operation.OperationID == baseOperation.operation.OperationID {
+				return i
+			}
+		}
+		return -1
+	}
+
+	d := &specDiffer{}
+	for _, baseOperation := range baseOperations {
+		i := findCurrent(baseOperation)
+		if i < 0 {
+			d.current = baseOperation
+			d.add(true, ChangeOperationRemoved, "", "operation was removed")
+			continue
+		}
+		matched[i] = true
+		d.current = currentOperations[i]
+		d.diffOperation(baseOperation, currentOperations[i])
+	}
+	for i, currentOperation := range currentOperations {
+		if !matched[i] {
+			d.current = currentOperation
+			d.add(false, ChangeOperationAdded, "", "operation was added")
+		}
+	}
+
+	return &SpecDiff{Changes: d.changes}, nil
+}
+
+func resolvedOpenAPI3(t *openapi3.T) (*openapi3.T, error) {
+	t, err := cloneOpenAPI3(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := openapi3.NewLoader().ResolveRefsIn(t, nil); err != nil {
+		return nil, fmt.Errorf("failed to resolve references: %w", err)
+	}
+	return t, nil
+}
+
+func (d *specDiffer) diffOperation(base diffOperation, current diffOperation) {
+	if base.key != current.key {
+		d.add(true, ChangeOperationPathChanged, "", "operation moved from %s %s", base.method, base.path)
+	}
+	if base.operation.OperationID != current.operation.OperationID {
+		d.add(false, ChangeOperationIDChanged, "", "operation ID changed from %q to %q", base.operation.OperationID, current.operation.OperationID)
+	}
+	if !base.operation.Deprecated && current.operation.Deprecated {
+		d.add(false, ChangeOperationDeprecated, "", "operation was deprecated")
+	}
+
+	d.diffParameters(base, current)
+	d.diffRequestBody(base.operation.RequestBody, current.operation.RequestBody)
+	d.diffResponses(base.operation.Responses, current.operation.Responses)
+}
+
+func diffParameterKey(path string, parameter *openapi3.Parameter) string {
+	if parameter.In != openapi3.ParameterInPath {
+		return parameter.In + " " + parameter.Name
+	}
+	// path parameters are matched by position, as their names do not matter to clients
+	for i, segment := range parsePathTemplate(path).Segments {
+		if segment.Param == parameter.Name {
+			return fmt.Sprintf("path #%d", i)
+		}
+	}
+	return "path " + parameter.Name
+}
+
+func (d *specDiffer) diffParameters(base diffOperation, current diffOperation) {
+	baseParameters := map[string]*openapi3.Parameter{}
+	for _, parameterRef := range base.operation.Parameters {
+		if parameterRef.Value != nil {
+			baseParameters[diffParameterKey(base.path, parameterRef.Value)] = parameterRef.Value
+		}
+	}
+	currentParameters := map[string]*openapi3.Parameter{}
+	for _, parameterRef := range current.operation.Parameters {
+		if parameterRef.Value != nil {
+			currentParameters[diffParameterKey(current.path, parameterRef.Value)] = parameterRef.Value
+		}
+	}
+
+	for _, key := range sortedKeys(baseParameters) {
+		baseParameter := baseParameters[key]
+		location := fmt.Sprintf("%s parameter %s", baseParameter.In, baseParameter.Name)
+		currentParameter, ok := currentParameters[key]
+		if !ok {
+			d.add(baseParameter.In == openapi3.ParameterInPath, ChangeParameterRemoved, location, "parameter was removed")
+			continue
+		}
+		if !baseParameter.Required && currentParameter.Required {
+			d.add(true, ChangeParameterRequired, location, "parameter became required")
+		}
+		if baseParameter.Schema != nil && currentParameter.Schema != nil {
+			d.diffSchema(diffDirectionRequest, location, baseParameter.Schema.Value, currentParameter.Schema.Value, map[schemaPair]struct{}{})
+		}
+	}
+	for _, key := range sortedKeys(currentParameters) {
+		if _, ok := baseParameters[key]; ok {
+			continue
+		}
+		currentParameter := currentParameters[key]
+		location := fmt.Sprintf("%s parameter %s", currentParameter.In, currentParameter.Name)
+		if currentParameter.Required {
+			d.add(true, ChangeParameterAdded, location, "required parameter was added")
+		} else {
+			d.add(false, ChangeParameterAdded, location, "optional parameter was added")
+		}
+	}
+}
+
+func requestBodyContent(requestBodyRef *openapi3.RequestBodyRef) (openapi3.Content, bool) {
+	if requestBodyRef == nil || requestBodyRef.Value == nil || len(requestBodyRef.Value.Content) == 0 {
+		return nil, false
+	}
+	return requestBodyRef.Value.Content, requestBodyRef.Value.Required
+}
+
+func (d *specDiffer) diffRequestBody(base *openapi3.RequestBodyRef, current *openapi3.RequestBodyRef) {
+	baseContent, baseRequired := requestBodyContent(base)
+	currentContent, currentRequired := requestBodyContent(current)
+
+	switch {
+	case baseContent == nil && currentContent == nil:
+		return
+	case baseContent == nil:
+		d.add(currentRequired, ChangeRequestBodyAdded, "request body", "request body was added")
+		return
+	case currentContent == nil:
+		d.add(false, ChangeRequestBodyRemoved, "request body", "request body was removed")
+		return
+	}
+	if !baseRequired && currentRequired {
+		d.add(true, ChangeRequestBodyRequired, "request body", "request body became required")
+	}
+
+	for _, mediaType := range sortedContentTypes(baseContent) {
+		location := "request body " + mediaType
+		currentMediaType, ok := currentContent[mediaType]
+		if !ok {
+			d.add(true, ChangeMediaTypeRemoved, location, "media type is no longer accepted")
+			continue
+		}
+		d.diffSchemaRef(diffDirectionRequest, location, baseContent[mediaType].Schema, currentMediaType.Schema)
+	}
+	for _, mediaType := range sortedContentTypes(currentContent) {
+		if _, ok := baseContent[mediaType]; !ok {
+			d.add(false, ChangeMediaTypeAdded, "request body "+mediaType, "media type is now accepted")
+		}
+	}
+}
+
+func (d *specDiffer) diffResponses(base openapi3.Responses, current openapi3.Responses) {
+	for _, status := range sortedKeys(base) {
+		location := "response " + status
+		currentResponse, ok := current[status]
+		if !ok {
+			d.add(true, ChangeResponseRemoved, location, "status code %s is no longer returned", status)
+			continue
+		}
+		baseResponse := base[status]
+		if baseResponse.Value == nil || currentResponse.Value == nil {
+			continue
+		}
+		for _, mediaType := range sortedContentTypes(baseResponse.Value.Content) {
+			currentMediaType, ok := currentResponse.Value.Content[mediaType]
+			if !ok {
+				d.add(true, ChangeMediaTypeRemoved, location+" "+mediaType, "media type is no longer returned")
+				continue
+			}
+			d.diffSchemaRef(diffDirectionResponse, location+" "+mediaType, baseResponse.Value.Content[mediaType].Schema, currentMediaType.Schema)
+		}
+		for _, mediaType := range sortedContentTypes(currentResponse.Value.Content) {
+			if _, ok := baseResponse.Value.Content[mediaType]; !ok {
+				d.add(false, ChangeMediaTypeAdded, location+" "+mediaType, "media type is now returned")
+			}
+		}
+	}
+	for _, status := range sortedKeys(current) {
+		if _, ok := base[status]; !ok {
+			d.add(false, ChangeResponseAdded, "response "+status, "status code %s was added", status)
+		}
+	}
+}
+
+func (d *specDiffer) diffSchemaRef(direction string, location string, base *openapi3.SchemaRef, current *openapi3.SchemaRef) {
+	if base == nil || current == nil {
+		return
+	}
+	d.diffSchema(direction, location, base.Value, current.Value, map[schemaPair]struct{}{})
+}
+
+// schemaPair is a base and current schema compared by diffSchema.
+type schemaPair struct {
+	base    *openapi3.Schema
+	current *openapi3.Schema
+}
+
+// diffSchema compares two schemas. Changes that make a schema stricter break requests, changes that
+// make a schema looser break responses, as clients may not handle the new values. Pairs of schemas
+// are compared once, which ends the recursion of recursive types.
+func (d *specDiffer) diffSchema(direction string, location string, base *openapi3.Schema, current *openapi3.Schema, visited map[schemaPair]struct{}) {
+	if base == nil || current == nil {
+		return
+	}
+	if _, ok := visited[schemaPair{base, current}]; ok {
+		return
+	}
+	visited[schemaPair{base, current}] = struct{}{}
+	isRequest := direction == diffDirectionRequest
+
+	if base.Type != current.Type {
+		d.add(true, ChangeTypeChanged, location, "type changed from %q to %q", base.Type, current.Type)
+		return
+	}
+	if base.Format != current.Format {
+		d.add(true, ChangeFormatChanged, location, "format changed from %q to %q", base.Format, current.Format)
+	}
+
+	switch {
+	case len(base.Enum) == 0 && len(current.Enum) > 0:
+		d.add(isRequest, ChangeEnumNarrowed, location, "values are restricted to %v", current.Enum)
+	case len(base.Enum) > 0 && len(current.Enum) == 0:
+		d.add(!isRequest, ChangeEnumWidened, location, "values are no longer restricted to %v", base.Enum)
+	default:
+		removed, added := diffEnum(base.Enum, current.Enum)
+		if len(removed) > 0 {
+			d.add(isRequest, ChangeEnumNarrowed, location, "enum values %v were removed", removed)
+		}
+		if len(added) > 0 {
+			d.add(!isRequest, ChangeEnumWidened, location, "enum values %v were added", added)
+		}
+	}
+
+	d.diffBounds(isRequest, location, "minimum", base.Min, current.Min, true, base.ExclusiveMin, current.ExclusiveMin)
+	d.diffBounds(isRequest, location, "maximum", base.Max, current.Max, false, base.ExclusiveMax, current.ExclusiveMax)
+	d.diffBounds(isRequest, location, "minLength", uint64Ptr(base.MinLength), uint64Ptr(current.MinLength), true, false, false)
+	d.diffBounds(isRequest, location, "maxLength", uint64PtrPtr(base.MaxLength), uint64PtrPtr(current.MaxLength), false, false, false)
+	d.diffBounds(isRequest, location, "minItems", uint64Ptr(base.MinItems), uint64Ptr(current.MinItems), true, false, false)
+	d.diffBounds(isRequest, location, "maxItems", uint64PtrPtr(base.MaxItems), uint64PtrPtr(current.MaxItems), false, false, false)
+	d.diffBounds(isRequest, location, "minProperties", uint64Ptr(base.MinProps), uint64Ptr(current.MinProps), true, false, false)
+	d.diffBounds(isRequest, location, "maxProperties", uint64PtrPtr(base.MaxProps), uint64PtrPtr(current.MaxProps), false, false, false)
+
+	// whether a changed pattern accepts fewer values cannot be decided, it breaks both directions
+	switch {
+	case base.Pattern == current.Pattern:
+	case base.Pattern == "":
+		d.add(isRequest, ChangePatternChanged, location, "pattern %q was added", current.Pattern)
+	case current.Pattern == "":
+		d.add(!isRequest, ChangePatternChanged, location, "pattern %q was removed", base.Pattern)
+	default:
+		d.add(true, ChangePatternChanged, location, "pattern changed from %q to %q", base.Pattern, current.Pattern)
+	}
+
+	baseRequired, currentRequired := stringSet(base.Required), stringSet(current.Required)
+	for _, name := range sortedKeys(base.Properties) {
+		propertyLocation := location + "." + name
+		currentProperty, ok := current.Properties[name]
+		if !ok {
+			d.add(!isRequest, ChangePropertyRemoved, propertyLocation, "property was removed")
+			continue
+		}
+		if isRequest && !baseRequired[name] && currentRequired[name] {
+			d.add(true, ChangePropertyRequired, propertyLocation, "property became required")
+		}
+		if !isRequest && baseRequired[name] && !currentRequired[name] {
+			d.add(true, ChangePropertyOptional, propertyLocation, "property is no longer guaranteed to be present")
+		}
+		d.diffSchema(direction, propertyLocation, base.Properties[name].Value, currentProperty.Value, visited)
+	}
+	for _, name := range sortedKeys(current.Properties) {
+		if _, ok := base.Properties[name]; ok {
+			continue
+		}
+		propertyLocation := location + "." + name
+		if isRequest && currentRequired[name] {
+			d.add(true, ChangePropertyAdded, propertyLocation, "required property was added")
+		} else {
+			d.add(false, ChangePropertyAdded, propertyLocation, "property was added")
+		}
+	}
+
+	if base.Items != nil && current.Items != nil {
+		d.diffSchema(direction, location+"[]", base.Items.Value, current.Items.Value, visited)
+	}
+	if base.AdditionalProperties.Schema != nil && current.AdditionalProperties.Schema != nil {
+		d.diffSchema(direction, location+"{}", base.AdditionalProperties.Schema.Value, current.AdditionalProperties.Schema.Value, visited)
+	}
+}
+
+// diffBounds compares a lower (isLower) or upper bound. A bound is tightened if it was added, if a lower
+// bound increased, if an upper bound decreased or if it became exclusive.
+func (d *specDiffer) diffBounds(isRequest bool, location string, name string, base *float64, current *float64, isLower bool, baseExclusive bool, currentExclusive bool) {
+	var tightened, loosened bool
+	switch {
+	case base == nil && current == nil:
+		return
+	case base == nil:
+		tightened = true
+	case current == nil:
+		loosened = true
+	case *base != *current:
+		tightened = (*current > *base) == isLower
+		loosened = !tightened
+	case !baseExclusive && currentExclusive:
+		tightened = true
+	case baseExclusive && !currentExclusive:
+		loosened = true
+	default:
+		return
+	}
+
+	describe := func(v *float64, exclusive bool) string {
+		if v == nil {
+			return "none"
+		}
+		if exclusive {
+			return fmt.Sprintf("%v (exclusive)", *v)
+		}
+		return fmt.Sprintf("%v", *v)
+	}
+	message := fmt.Sprintf("%s changed from %s to %s", name, describe(base, baseExclusive), describe(current, currentExclusive))
+	if tightened {
+		d.add(isRequest, ChangeConstraintTightened, location, "%s", message)
+	}
+	if loosened {
+		d.add(!isRequest, ChangeConstraintLoosened, location, "%s", message)
+	}
+}
+
+func diffEnum(base []interface{}, current []interface{}) (removed []interface{}, added []interface{}) {
+	contains := func(values []interface{}, v interface{}) bool {
+		for _, value := range values {
+			if reflect.DeepEqual(value, v) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range base {
+		if !contains(current, v) {
+			removed = append(removed, v)
+		}
+	}
+	for _, v := range current {
+		if !contains(base, v) {
+			added = append(added, v)
+		}
+	}
+	return
+}
+
+func uint64Ptr(v uint64) *float64 {
+	if v == 0 {
+		return nil
+	}
+	f := float64(v)
+	return &f
+}
+
+func uint64PtrPtr(v *uint64) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,237 @@
+package specs
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type diffTestUserV1 struct {
+	ID     string `json:"id" validate:"required"`
+	Name   string `json:"name" validate:"required"`
+	Age    int    `json:"age" validate:"min=0"`
+	Status string `json:"status"`
+}
+
+type diffTestUserV2 struct {
+	ID    string `json:"id" validate:"required"`
+	Age   int    `json:"age" validate:"min=18"`
+	Email string `json:"email" validate:"required"`
+}
+
+type diffTestParameters struct {
+	ID string `json:"id" validate:"required"`
+}
+
+func annotateDiffTestRegistry(build func(r *registry[http.HandlerFunc])) *openapi3.T {
+	r := NewRegistry[http.HandlerFunc](OperationIDGenerator(MethodPathOperationIDGenerator))
+	build(r)
+	t := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(t)
+	return t
+}
+
+func TestDiffSpecs(t *testing.T) {
+	base := annotateDiffTestRegistry(func(r *registry[http.HandlerFunc]) {
+		r.GET("/users/{id}", nil).Parameters(diffTestParameters{}).Response(200, diffTestUserV1{}, "User found")
+		r.POST("/users", nil).Payload(diffTestUserV1{}).Response(201, diffTestUserV1{}, "User created").Response(409, nil, "Conflict")
+		r.DELETE("/users/{id}", nil).Parameters(diffTestParameters{}).Response(204, nil, "User deleted")
+	})
+	current := annotateDiffTestRegistry(func(r *registry[http.HandlerFunc]) {
+		r.GET("/users/{userId}", nil).Parameters(struct {
+			UserID string `json:"userId" validate:"required"`
+		}{}).Response(200, diffTestUserV2{}, "User found")
+		r.POST("/users", nil).Payload(diffTestUserV2{}).Response(201, diffTestUserV2{}, "User created")
+		r.GET("/users", nil).Response(200, []diffTestUserV2{}, "Users found")
+	})
+
+	diff, err := DiffSpecs(base, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Breaking() {
+		t.Fatal("expected breaking changes")
+	}
+
+	tests := []struct {
+		name      string
+		operation string
+		kind      string
+		location  string
+		breaking  bool
+	}{
+		{name: "should detect removed operation", operation: "DELETE /users/{id}", kind: ChangeOperationRemoved, breaking: true},
+		{name: "should detect added operation", operation: "GET /users", kind: ChangeOperationAdded, breaking: false},
+		{name: "should detect removed response field", operation: "GET /users/{userId}", kind: ChangePropertyRemoved, location: "response 200 application/json.name", breaking: true},
+		{name: "should detect added required request field", operation: "POST /users", kind: ChangePropertyAdded, location: "request body application/json.email", breaking: true},
+		{name: "should detect tightened minimum", operation: "POST /users", kind: ChangeConstraintTightened, location: "request body application/json.age", breaking: true},
+		{name: "should detect removed status code", operation: "POST /users", kind: ChangeResponseRemoved, location: "response 409", breaking: true},
+		{name: "should ignore removed request field", operation: "POST /users", kind: ChangePropertyRemoved, location: "request body application/json.status", breaking: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, change := range diff.Changes {
+				if change.Operation == tt.operation && change.Kind == tt.kind && change.Location == tt.location {
+					if change.Breaking != tt.breaking {
+						t.Errorf("breaking = %v, want %v", change.Breaking, tt.breaking)
+					}
+					return
+				}
+			}
+			t.Errorf("change %s %s %s not found in %v", tt.operation, tt.kind, tt.location, diff.Changes)
+		})
+	}
+
+	for _, change := range diff.Changes {
+		if change.Kind == ChangeParameterRemoved || change.Kind == ChangeParameterAdded {
+			t.Errorf("renamed path parameter reported as %s", change)
+		}
+	}
+}
+
+func TestDiffSpecs_RecursiveTypes(t *testing.T) {
+	type node struct {
+		Value int   `json:"value"`
+		Left  *node `json:"left"`
+		Right *node `json:"right"`
+	}
+	type nodeV2 struct {
+		Value string  `json:"value"`
+		Left  *nodeV2 `json:"left"`
+		Right *nodeV2 `json:"right"`
+	}
+
+	base := annotateDiffTestRegistry(func(r *registry[http.HandlerFunc]) {
+		r.GET("/tree", nil).Response(200, node{}, "Tree found")
+	})
+	current := annotateDiffTestRegistry(func(r *registry[http.HandlerFunc]) {
+		r.GET("/tree", nil).Response(200, nodeV2{}, "Tree found")
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		diff, err := DiffSpecs(base, base)
+		if err != nil || len(diff.Changes) != 0 {
+			t.Errorf("got %v, %v", diff, err)
+		}
+		diff, err = DiffSpecs(base, current)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, change := range diff.Changes {
+			if change.Kind == ChangeTypeChanged && change.Location == "response 200 application/json.value" {
+				return
+			}
+		}
+		t.Errorf("changed type not found in %v", diff.Changes)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("diffing recursive types did not terminate")
+	}
+}
+
+func TestDiffSpecs_Enum(t *testing.T) {
+	schema := func(enum ...interface{}) *openapi3.T {
+		return &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info:    &openapi3.Info{Title: "test", Version: "1.0.0"},
+			Paths: openapi3.Paths{
+				"/things": &openapi3.PathItem{
+					Get: &openapi3.Operation{
+						Parameters: openapi3.Parameters{{Value: openapi3.NewQueryParameter("kind").WithSchema(openapi3.NewStringSchema().WithEnum(enum...))}},
+						Responses:  openapi3.NewResponses(),
+					},
+				},
+			},
+		}
+	}
+
+	diff, err := DiffSpecs(schema("a", "b"), schema("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Kind != ChangeEnumNarrowed || !diff.Changes[0].Breaking {
+		t.Errorf("narrowed enum not detected as breaking: %v", diff.Changes)
+	}
+
+	diff, err = DiffSpecs(schema("a"), schema("a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Kind != ChangeEnumWidened || diff.Changes[0].Breaking {
+		t.Errorf("widened request enum detected as breaking: %v", diff.Changes)
+	}
+}
+
+func TestDiffSpecs_Constraints(t *testing.T) {
+	document := func(required bool, request *openapi3.Schema, response *openapi3.Schema) *openapi3.T {
+		return &openapi3.T{
+			OpenAPI: "3.0.3",
+			Info:    &openapi3.Info{Title: "test", Version: "1.0.0"},
+			Paths: openapi3.Paths{
+				"/things": &openapi3.PathItem{
+					Post: &openapi3.Operation{
+						RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(request).WithRequired(required)},
+						Responses:   openapi3.Responses{"200": &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("ok").WithJSONSchema(response)}},
+					},
+				},
+			},
+		}
+	}
+	pattern := func(pattern string) *openapi3.Schema {
+		return openapi3.NewStringSchema().WithPattern(pattern)
+	}
+	object := func(min int64, max int64) *openapi3.Schema {
+		return openapi3.NewObjectSchema().WithMinProperties(min).WithMaxProperties(max)
+	}
+	str := openapi3.NewStringSchema
+
+	tests := []struct {
+		name     string
+		base     *openapi3.T
+		current  *openapi3.T
+		kind     string
+		location string
+		breaking bool
+	}{
+		{name: "should break requests on added patterns", base: document(true, str(), str()), current: document(true, pattern("^a$"), str()), kind: ChangePatternChanged, location: "request body application/json", breaking: true},
+		{name: "should not break requests on removed patterns", base: document(true, pattern("^a$"), str()), current: document(true, str(), str()), kind: ChangePatternChanged, location: "request body application/json", breaking: false},
+		{name: "should break responses on removed patterns", base: document(true, str(), pattern("^a$")), current: document(true, str(), str()), kind: ChangePatternChanged, location: "response 200 application/json", breaking: true},
+		{name: "should not break responses on added patterns", base: document(true, str(), str()), current: document(true, str(), pattern("^a$")), kind: ChangePatternChanged, location: "response 200 application/json", breaking: false},
+		{name: "should break on changed patterns", base: document(true, pattern("^a$"), str()), current: document(true, pattern("^a+$"), str()), kind: ChangePatternChanged, location: "request body application/json", breaking: true},
+		{name: "should break on required request bodies", base: document(false, str(), str()), current: document(true, str(), str()), kind: ChangeRequestBodyRequired, location: "request body", breaking: true},
+		{name: "should break requests on raised minProperties", base: document(true, object(0, 5), str()), current: document(true, object(1, 5), str()), kind: ChangeConstraintTightened, location: "request body application/json", breaking: true},
+		{name: "should not break requests on raised maxProperties", base: document(true, object(0, 5), str()), current: document(true, object(0, 6), str()), kind: ChangeConstraintLoosened, location: "request body application/json", breaking: false},
+		{name: "should break responses on raised maxProperties", base: document(true, str(), object(0, 5)), current: document(true, str(), object(0, 6)), kind: ChangeConstraintLoosened, location: "response 200 application/json", breaking: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffSpecs(tt.base, tt.current)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(diff.Changes) != 1 {
+				t.Fatalf("got changes %v", diff.Changes)
+			}
+			if change := diff.Changes[0]; change.Kind != tt.kind || change.Location != tt.location || change.Breaking != tt.breaking {
+				t.Errorf("got %s (%s), want %s %s breaking=%v", change, change.Kind, tt.kind, tt.location, tt.breaking)
+			}
+		})
+	}
+
+	t.Run("should not report request bodies becoming optional", func(t *testing.T) {
+		diff, err := DiffSpecs(document(true, str(), str()), document(false, str(), str()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(diff.Changes) != 0 {
+			t.Errorf("got changes %v", diff.Changes)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedSpecFormat = errors.New("unsupported spec format")
+)
+
+var parameterLocationOrder = map[string]int{
+	openapi3.ParameterInPath:   0,
+	openapi3.ParameterInQuery:  1,
+	openapi3.ParameterInHeader: 2,
+	openapi3.ParameterInCookie: 3,
+}
+
+// MarshalCanonicalJSON marshals t into indented JSON with a stable order: object keys (and hence paths,
+// operations, responses and components) are sorted, parameters are sorted by location and name. The
+// same document always yields the same bytes, so the output can be committed and diffed.
+func MarshalCanonicalJSON(t *openapi3.T) ([]byte, error) {
+	doc, err := cloneOpenAPI3(t)
+	if err != nil {
+		return nil, err
+	}
+	canonicalizeOpenAPI3(doc)
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal openapi document: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// MarshalCanonicalYAML marshals t into YAML with the same order as MarshalCanonicalJSON.
+func MarshalCanonicalYAML(t *openapi3.T) ([]byte, error) {
+	content, err := MarshalCanonicalJSON(t)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(content)
+}
+
+// WriteSpecFile writes t in canonical form to path. The format is chosen by the extension
+// (.json, .yaml or .yml). It is meant to be used from go generate to commit the spec.
+func WriteSpecFile(path string, t *openapi3.T) error {
+	var (
+		content []byte
+		err     error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		content, err = MarshalCanonicalJSON(t)
+	case ".yaml", ".yml":
+		content, err = MarshalCanonicalYAML(t)
+	default:
+		return fmt.Errorf("%s: %w", path, ErrUnsupportedSpecFormat)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+func canonicalizeOpenAPI3(t *openapi3.T) {
+	for _, pathItem := range t.Paths {
+		sortParameters(pathItem.Parameters)
+		for _, operation := range pathItem.Operations() {
+			sortParameters(operation.Parameters)
+		}
+	}
+}
+
+func sortParameters(parameters openapi3.Parameters) {
+	key := func(parameterRef *openapi3.ParameterRef) (int, string) {
+		if parameterRef.Value == nil {
+			return len(parameterLocationOrder), parameterRef.Ref
+		}
+		return parameterLocationOrder[parameterRef.Value.In], parameterRef.Value.Name
+	}
+	sort.SliceStable(parameters, func(i, j int) bool {
+		li, ni := key(parameters[i])
+		lj, nj := key(parameters[j])
+		if li != lj {
+			return li < lj
+		}
+		return ni < nj
+	})
+}
+
+// jsonToYAML converts a JSON document into block style YAML, preserving the order of the keys.
+func jsonToYAML(content []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert openapi document to yaml: %w", err)
+	}
+	resetYAMLStyle(&node)
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to convert openapi document to yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// resetYAMLStyle removes the flow style and quotes of the JSON input, so that yaml chooses the
+// style. Strings which would be ambiguous without quotes (e.g. "200" or "true") are still quoted.
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
@@ -0,0 +1,118 @@
+package specs
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	annotate := func() *openapi3.T {
+		r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+		r.GET("/orgs/{org}/users/{id}", "").
+			Tags("users").
+			Parameters(struct {
+				Org string `json:"org"`
+				ID  string `json:"id"`
+			}{}).
+			Query(struct {
+				Sort   string `json:"sort"`
+				Limit  int    `json:"limit"`
+				Offset int    `json:"offset"`
+			}{}).
+			Response(200, struct {
+				Name string `json:"name"`
+			}{}, "User found").
+			Response(404, nil, "User not found")
+		r.DELETE("/orgs/{org}/users/{id}", "").Tags("users").Response(204, nil, "User deleted")
+		r.GET("/orgs", "").Tags("orgs").Response(200, []string{}, "Orgs found")
+
+		t := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+		r.Annotate(t)
+		return t
+	}
+
+	tests := []struct {
+		name    string
+		marshal func(t *openapi3.T) ([]byte, error)
+	}{
+		{name: "json", marshal: MarshalCanonicalJSON},
+		{name: "yaml", marshal: MarshalCanonicalYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := tt.marshal(annotate())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 20; i++ {
+				got, err := tt.marshal(annotate())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("output is not stable:\n%s\n---\n%s", got, want)
+				}
+			}
+		})
+	}
+
+	doc := annotate()
+	parameters := doc.Paths.Find("/orgs/{org}/users/{id}").Get.Parameters
+	for i, j := 0, len(parameters)-1; i < j; i, j = i+1, j-1 {
+		parameters[i], parameters[j] = parameters[j], parameters[i]
+	}
+	content, err := MarshalCanonicalJSON(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := openapi3.NewLoader().LoadFromData(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, parameterRef := range canonical.Paths.Find("/orgs/{org}/users/{id}").Get.Parameters {
+		names = append(names, parameterRef.Value.In+":"+parameterRef.Value.Name)
+	}
+	if got, want := names, []string{"path:id", "path:org", "query:limit", "query:offset", "query:sort"}; !equalStrings(got, want) {
+		t.Errorf("got parameters %v, want %v", got, want)
+	}
+}
+
+func TestWriteSpecFile(t *testing.T) {
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}, Paths: openapi3.Paths{}}
+	dir := t.TempDir()
+
+	for _, name := range []string{"openapi.json", "openapi.yaml", "openapi.yml"} {
+		path := filepath.Join(dir, name)
+		if err := WriteSpecFile(path, doc); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := openapi3.NewLoader().LoadFromFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if loaded.Info.Title != "test" {
+			t.Errorf("%s: got title %s", name, loaded.Info.Title)
+		}
+	}
+
+	if err := WriteSpecFile(filepath.Join(dir, "openapi.txt"), doc); !errors.Is(err, ErrUnsupportedSpecFormat) {
+		t.Errorf("got %v, want %v", err, ErrUnsupportedSpecFormat)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
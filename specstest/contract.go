@@ -0,0 +1,640 @@
+package specstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/jakoblorz/specs"
+)
+
+const (
+	defaultContractIterations = 10
+	contractMaxAttempts       = 10
+	invalidValue              = "invalid"
+)
+
+// Registry is the registry checked by RunContract, as returned by specs.NewRegistry.
+type Registry[T interface{}] interface {
+	Annotate(t *openapi3.T, filters ...specs.EndpointFilter[T])
+	Eject() specs.Registry[T]
+}
+
+// contractEndpoint is an endpoint of the registry together with its annotated operation. Endpoints
+// declaring payloads of several media types are checked once per media type.
+type contractEndpoint struct {
+	name        string
+	operationID string
+	method      string
+	path        string
+	operation   *openapi3.Operation
+
+	parameters interface{}
+	query      interface{}
+	payload    interface{}
+	mediaType  string
+}
+
+// contractRequest is a request to an endpoint before it is encoded. The body is the decoded object
+// of json payloads, the formBody of form payloads and the generated value otherwise.
+type contractRequest struct {
+	path  url.Values
+	query url.Values
+	body  interface{}
+
+	// raw replaces the encoded body if set
+	raw []byte
+}
+
+// formBody is the body of form payloads, files are sent as parts of multipart forms.
+type formBody struct {
+	values url.Values
+	files  []string
+}
+
+// invalidRequest is a request which violates the declaration of an endpoint in exactly one way.
+type invalidRequest struct {
+	name string
+	req  contractRequest
+}
+
+// RunContract runs a subtest for every endpoint of the registry against the handler, which
+// should serve the registry just like in production. Requests are synthesized from the declared
+// parameters, query and payload types:
+//
+//   - valid requests must be answered with a declared status and a body matching its schema
+//   - requests with a malformed body, a missing required property or a value of the wrong type
+//     must be rejected with a 4xx status
+//
+// Payloads are encoded using the Codecs for each declared media type, multipart forms are encoded
+// with a file part per file field. Missing and mistyped properties are only synthesized for json and
+// form payloads, media types without codec are not synthesized. Use RequestEditor to authenticate the
+// requests.
+func RunContract[T interface{}](t *testing.T, registry Registry[T], handler http.Handler, opts ...Option) {
+	t.Helper()
+	o := newOptions(append([]Option{Iterations(defaultContractIterations)}, opts...))
+
+	for _, e := range newContractEndpoints(t, registry, o) {
+		e := e
+		t.Run(e.name, func(t *testing.T) {
+			checkContract(t, e, handler, o)
+		})
+	}
+}
+
+// RequestEditor modifies every request sent by RunContract, e.g. to add credentials.
+func RequestEditor(edit func(req *http.Request)) Option {
+	return func(o *options) {
+		o.requestEditors = append(o.requestEditors, edit)
+	}
+}
+
+// Codecs sets the codecs encoding the payloads of RunContract, defaults to specs.DefaultCodecs.
+func Codecs(codecs *specs.Codecs) Option {
+	return func(o *options) {
+		o.codecs = codecs
+	}
+}
+
+func newContractEndpoints[T interface{}](t testing.TB, r Registry[T], o *options) []contractEndpoint {
+	t.Helper()
+
+	registry := r.Eject()
+	operationIDs := make([]string, 0, len(registry))
+	for operationID := range registry {
+		operationIDs = append(operationIDs, operationID)
+	}
+	sort.Strings(operationIDs)
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:   "Contract",
+			Version: "0.0.0",
+		},
+	}
+	r.Annotate(doc)
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		t.Fatalf("specstest: failed to resolve references: %v", err)
+		return nil
+	}
+
+	endpoints := make([]contractEndpoint, 0, len(operationIDs))
+	for _, operationID := range operationIDs {
+		endpoint := registry[operationID]
+		pathItem := doc.Paths.Find(endpoint.Path)
+		if pathItem == nil || pathItem.GetOperation(endpoint.Method) == nil {
+			continue
+		}
+
+		e := contractEndpoint{
+			name:        operationID,
+			operationID: operationID,
+			method:      endpoint.Method,
+			path:        endpoint.Path,
+			operation:   pathItem.GetOperation(endpoint.Method),
+			parameters:  endpoint.Parameters,
+			query:       endpoint.Query,
+		}
+		var payloads []contractEndpoint
+		for _, payload := range endpoint.Payload {
+			if _, ok := o.codecs.Lookup(payload.MediaType); !ok && payload.MediaType != specs.MediaTypeMultipartForm {
+				continue
+			}
+			p := e
+			p.payload, p.mediaType = payload.Value, payload.MediaType
+			payloads = append(payloads, p)
+		}
+		if len(payloads) > 1 {
+			for i := range payloads {
+				payloads[i].name += " " + payloads[i].mediaType
+			}
+		}
+		if len(payloads) == 0 {
+			payloads = append(payloads, e)
+		}
+		endpoints = append(endpoints, payloads...)
+	}
+	return endpoints
+}
+
+func checkContract(t testing.TB, e contractEndpoint, handler http.Handler, o *options) {
+	t.Helper()
+	g := NewValueGenerator(o.seed)
+
+	var base *contractRequest
+	for i := 0; i < o.iterations; i++ {
+		req, ok := e.validRequest(g, o)
+		if !ok {
+			continue
+		}
+		if base == nil {
+			base = &req
+		}
+		rec, err := e.send(handler, req, o)
+		if err != nil {
+			t.Errorf("specstest: %s: failed to send valid request %d: %v", e.name, i, err)
+			return
+		}
+		if !e.checkResponse(t, fmt.Sprintf("valid request %d", i), rec, false) {
+			return
+		}
+	}
+	if base == nil {
+		if o.iterations > 0 {
+			t.Errorf("specstest: %s: none of the requests generated were accepted by the validator", e.name)
+		}
+		return
+	}
+
+	for _, invalid := range e.invalidRequests(*base) {
+		rec, err := e.send(handler, invalid.req, o)
+		if err != nil {
+			t.Errorf("specstest: %s: failed to send %s: %v", e.name, invalid.name, err)
+			continue
+		}
+		e.checkResponse(t, invalid.name, rec, true)
+	}
+}
+
+// validRequest generates a request from the declared types which is accepted by the validator.
+func (e contractEndpoint) validRequest(g *ValueGenerator, o *options) (req contractRequest, ok bool) {
+	if req.path, ok = generateValues(g, e.parameters, o); !ok {
+		return req, false
+	}
+	for name := range req.path {
+		// empty path parameters would not match the route
+		if req.path.Get(name) == "" {
+			return req, false
+		}
+	}
+	if req.query, ok = generateValues(g, e.query, o); !ok {
+		return req, false
+	}
+	if req.body, ok = e.generateBody(g, o); !ok {
+		return req, false
+	}
+	return req, true
+}
+
+func generateValue(g *ValueGenerator, v interface{}, o *options) (interface{}, bool) {
+	t := reflect.TypeOf(v)
+	for attempt := 0; attempt < contractMaxAttempts; attempt++ {
+		value := g.Generate(t)
+		if reflect.Indirect(reflect.ValueOf(value)).Kind() != reflect.Struct || o.validate.Struct(value) == nil {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func generateValues(g *ValueGenerator, v interface{}, o *options) (url.Values, bool) {
+	values := url.Values{}
+	if v == nil {
+		return values, true
+	}
+	value, ok := generateValue(g, v, o)
+	if !ok {
+		return nil, false
+	}
+
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(content, &object); err != nil {
+		return nil, false
+	}
+	for name, property := range object {
+		switch property := property.(type) {
+		case nil, map[string]interface{}:
+		case []interface{}:
+			for _, item := range property {
+				values.Add(name, formatValue(item))
+			}
+		default:
+			values.Set(name, formatValue(property))
+		}
+	}
+	return values, true
+}
+
+// generateBody generates the payload, json and form payloads are decoded to be able to remove or
+// replace properties for invalid requests.
+func (e contractEndpoint) generateBody(g *ValueGenerator, o *options) (interface{}, bool) {
+	if e.payload == nil {
+		return nil, true
+	}
+	value, ok := generateValue(g, e.payload, o)
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case isJSON(e.mediaType):
+		content, err := json.Marshal(value)
+		if err != nil {
+			return nil, false
+		}
+		var body interface{}
+		if err := json.Unmarshal(content, &body); err != nil {
+			return nil, false
+		}
+		return body, true
+	case isForm(e.mediaType):
+		return generateFormBody(value, e.mediaType == specs.MediaTypeMultipartForm)
+	}
+	return value, true
+}
+
+// generateFormBody encodes the form fields of value, file fields are only kept by multipart forms.
+func generateFormBody(value interface{}, multipart bool) (interface{}, bool) {
+	content := new(bytes.Buffer)
+	if err := (specs.FormCodec{}).Encode(content, value); err != nil {
+		return nil, false
+	}
+	values, err := url.ParseQuery(content.String())
+	if err != nil {
+		return nil, false
+	}
+
+	body := formBody{values: values}
+	v := reflect.Indirect(reflect.ValueOf(value))
+	for _, field := range specs.GetTypeInfo(v.Type()).Fields {
+		if !isFile(field.Type) {
+			continue
+		}
+		name := field.FormName()
+		body.values.Del(name)
+		if fv, err := v.FieldByIndexErr(field.Index); multipart && err == nil && !fv.IsZero() {
+			body.files = append(body.files, name)
+		}
+	}
+	return body, true
+}
+
+func isFile(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(multipart.FileHeader{}) || t == reflect.TypeOf(specs.File{})
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	content, _ := json.Marshal(v)
+	return string(content)
+}
+
+// invalidRequests derives requests from the valid request which violate the declaration.
+func (e contractEndpoint) invalidRequests(base contractRequest) []invalidRequest {
+	var requests []invalidRequest
+
+	for _, parameterRef := range e.operation.Parameters {
+		parameter := parameterRef.Value
+		if parameter == nil || parameter.Schema == nil || !isScalar(parameter.Schema.Value) {
+			continue
+		}
+		req := base
+		switch parameter.In {
+		case openapi3.ParameterInPath:
+			req.path = cloneValues(base.path)
+			req.path.Set(parameter.Name, invalidValue)
+		case openapi3.ParameterInQuery:
+			req.query = cloneValues(base.query)
+			req.query.Set(parameter.Name, invalidValue)
+		default:
+			continue
+		}
+		requests = append(requests, invalidRequest{
+			name: fmt.Sprintf("%s parameter %s of the wrong type", parameter.In, parameter.Name),
+			req:  req,
+		})
+	}
+
+	if e.payload == nil {
+		return requests
+	}
+	if raw, ok := malformedBody(e.mediaType); ok {
+		requests = append(requests, invalidRequest{
+			name: "malformed body",
+			req:  contractRequest{path: base.path, query: base.query, raw: raw},
+		})
+	}
+
+	content := e.operation.RequestBody.Value.Content.Get(e.mediaType)
+	if content == nil || content.Schema == nil || content.Schema.Value == nil {
+		return requests
+	}
+	schema := content.Schema.Value
+
+	var ok bool
+	required := append([]string{}, schema.Required...)
+	sort.Strings(required)
+	if len(required) > 0 {
+		req := base
+		if req.body, ok = replaceProperty(base.body, required[0], nil); ok {
+			requests = append(requests, invalidRequest{
+				name: fmt.Sprintf("body without required property %s", required[0]),
+				req:  req,
+			})
+		}
+	}
+
+	for _, name := range sortedNames(schema.Properties) {
+		if !isScalar(schema.Properties[name].Value) {
+			continue
+		}
+		req := base
+		if req.body, ok = replaceProperty(base.body, name, invalidValue); ok {
+			requests = append(requests, invalidRequest{
+				name: fmt.Sprintf("body property %s of the wrong type", name),
+				req:  req,
+			})
+		}
+		break
+	}
+	return requests
+}
+
+// malformedBody returns a body which cannot be decoded as the media type.
+func malformedBody(mediaType string) ([]byte, bool) {
+	switch {
+	case isJSON(mediaType):
+		return []byte("{"), true
+	case isXML(mediaType):
+		return []byte("<"), true
+	case mediaType == specs.MediaTypeURLEncodedForm:
+		return []byte("%"), true
+	case mediaType == specs.MediaTypeMultipartForm:
+		return []byte("--"), true
+	}
+	return nil, false
+}
+
+// replaceProperty returns a copy of the json or form body with the property set to value, or removed
+// if value is nil. Other bodies cannot be modified.
+func replaceProperty(body interface{}, name string, value interface{}) (interface{}, bool) {
+	switch body := body.(type) {
+	case map[string]interface{}:
+		clone := cloneObject(body)
+		if value == nil {
+			delete(clone, name)
+		} else {
+			clone[name] = value
+		}
+		return clone, true
+	case formBody:
+		clone := formBody{values: cloneValues(body.values)}
+		for _, file := range body.files {
+			if file != name {
+				clone.files = append(clone.files, file)
+			}
+		}
+		if value == nil {
+			clone.values.Del(name)
+		} else {
+			clone.values.Set(name, fmt.Sprint(value))
+		}
+		return clone, true
+	}
+	return body, false
+}
+
+// isScalar reports whether a string is a value of the wrong type for the schema.
+func isScalar(schema *openapi3.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	switch schema.Type {
+	case "integer", "number", "boolean":
+		return true
+	}
+	return false
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, value := range values {
+		clone[key] = append([]string{}, value...)
+	}
+	return clone
+}
+
+func cloneObject(object map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		clone[key] = value
+	}
+	return clone
+}
+
+func (e contractEndpoint) send(handler http.Handler, req contractRequest, o *options) (*httptest.ResponseRecorder, error) {
+	path := e.path
+	for name := range req.path {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(req.path.Get(name)))
+	}
+	if len(req.query) > 0 {
+		path += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	contentType := e.mediaType
+	switch {
+	case req.raw != nil:
+		body = bytes.NewReader(req.raw)
+	case req.body != nil:
+		var err error
+		if body, contentType, err = e.encodeBody(req.body, o); err != nil {
+			return nil, err
+		}
+	}
+
+	r := httptest.NewRequest(e.method, path, body)
+	if body != nil {
+		r.Header.Set("Content-Type", contentType)
+	}
+	r.Header.Set("Accept", "application/json")
+	for _, edit := range o.requestEditors {
+		edit(r)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	return rec, nil
+}
+
+// encodeBody encodes the body as the media type of the endpoint and returns its content type.
+func (e contractEndpoint) encodeBody(body interface{}, o *options) (io.Reader, string, error) {
+	content := new(bytes.Buffer)
+	form, ok := body.(formBody)
+	switch {
+	case ok && e.mediaType == specs.MediaTypeMultipartForm:
+		w := multipart.NewWriter(content)
+		for _, name := range sortedNames(form.values) {
+			for _, value := range form.values[name] {
+				if err := w.WriteField(name, value); err != nil {
+					return nil, "", err
+				}
+			}
+		}
+		for _, name := range form.files {
+			header := textproto.MIMEHeader{}
+			header.Set("Content-Disposition", fmt.Sprintf("form-data; name=%q; filename=%q", name, name))
+			header.Set("Content-Type", e.fileContentType(name))
+			part, err := w.CreatePart(header)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, err := io.WriteString(part, "specstest"); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", err
+		}
+		return content, w.FormDataContentType(), nil
+	case ok:
+		content.WriteString(form.values.Encode())
+		return content, e.mediaType, nil
+	}
+
+	codec, ok := o.codecs.Lookup(e.mediaType)
+	if !ok {
+		return nil, "", fmt.Errorf("no codec for %s", e.mediaType)
+	}
+	if err := codec.Encode(content, body); err != nil {
+		return nil, "", err
+	}
+	return content, e.mediaType, nil
+}
+
+// fileContentType returns the first content type declared for the file part name.
+func (e contractEndpoint) fileContentType(name string) string {
+	if content := e.operation.RequestBody.Value.Content.Get(e.mediaType); content != nil {
+		if encoding, ok := content.Encoding[name]; ok && encoding.ContentType != "" {
+			return strings.TrimSpace(strings.Split(encoding.ContentType, ",")[0])
+		}
+	}
+	return "application/octet-stream"
+}
+
+// checkResponse reports whether the response complies with the declaration of the endpoint.
+func (e contractEndpoint) checkResponse(t testing.TB, name string, rec *httptest.ResponseRecorder, invalid bool) bool {
+	t.Helper()
+
+	if invalid && (rec.Code < 400 || rec.Code >= 500) {
+		t.Errorf("specstest: %s: %s was answered with status %d instead of a 4xx status:\n%s", e.name, name, rec.Code, rec.Body.String())
+		return false
+	}
+
+	responseRef := e.operation.Responses.Get(rec.Code)
+	if responseRef == nil || responseRef.Value == nil {
+		if invalid {
+			return true
+		}
+		t.Errorf("specstest: %s: %s was answered with undeclared status %d:\n%s", e.name, name, rec.Code, rec.Body.String())
+		return false
+	}
+	if len(responseRef.Value.Content) == 0 || e.method == http.MethodHead {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if err != nil {
+		t.Errorf("specstest: %s: %s was answered with status %d and invalid content type %q", e.name, name, rec.Code, rec.Header().Get("Content-Type"))
+		return false
+	}
+	content := responseRef.Value.Content.Get(mediaType)
+	if content == nil {
+		t.Errorf("specstest: %s: %s was answered with status %d and undeclared content type %s", e.name, name, rec.Code, mediaType)
+		return false
+	}
+	if content.Schema == nil || content.Schema.Value == nil || !isJSON(mediaType) {
+		return true
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Errorf("specstest: %s: %s was answered with status %d and an invalid json body: %v", e.name, name, rec.Code, err)
+		return false
+	}
+	if err := content.Schema.Value.VisitJSON(body); err != nil {
+		t.Errorf("specstest: %s: %s was answered with status %d and a body not matching the schema:\n%s\n%v", e.name, name, rec.Code, rec.Body.String(), err)
+		return false
+	}
+	return true
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func isXML(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+func isForm(mediaType string) bool {
+	return mediaType == specs.MediaTypeURLEncodedForm || mediaType == specs.MediaTypeMultipartForm
+}
@@ -0,0 +1,253 @@
+package specstest
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jakoblorz/specs"
+)
+
+type contractUser struct {
+	ID    string `json:"id" validate:"required,uuid"`
+	Name  string `json:"name" validate:"required,min=1,max=32"`
+	Age   int    `json:"age" validate:"required,gt=0,lte=150"`
+	Email string `json:"email,omitempty" validate:"omitempty,email"`
+}
+
+type contractUserParameters struct {
+	ID int `json:"id" validate:"required,gt=0"`
+}
+
+type contractUserQuery struct {
+	Limit  int    `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+	Filter string `json:"filter,omitempty"`
+}
+
+type contractError struct {
+	Message string `json:"message"`
+}
+
+func TestRunContract(t *testing.T) {
+	r := specs.NewRegistry[string](specs.OperationIDGenerator(specs.MethodPathOperationIDGenerator))
+	r.GET("/users", "").
+		Query(contractUserQuery{}).
+		Response(200, []contractUser{}, "Users found")
+	r.GET("/users/{id}", "").
+		Parameters(contractUserParameters{}).
+		Response(200, contractUser{}, "User found").
+		Response(404, contractError{}, "User not found")
+	r.POST("/users", "").
+		Payload(contractUser{}).
+		Response(201, contractUser{}, "User created").
+		Response(400, contractError{}, "Invalid user")
+
+	var edited bool
+	RunContract[string](t, r, specs.NewMockServer(r), RequestEditor(func(req *http.Request) {
+		edited = true
+	}))
+	if !edited {
+		t.Error("requests were not edited")
+	}
+}
+
+func TestRunContractMediaTypes(t *testing.T) {
+	type avatar struct {
+		Avatar *multipart.FileHeader `form:"avatar" contentType:"image/png" validate:"required"`
+		Alt    string                `form:"alt"`
+	}
+
+	r := specs.NewRegistry[string](specs.OperationIDGenerator(specs.MethodPathOperationIDGenerator))
+	r.POST("/users", "").
+		Payload(contractUser{}, specs.MediaTypeJSON, specs.MediaTypeXML, specs.MediaTypeURLEncodedForm).
+		Response(201, contractUser{}, "User created").
+		Response(400, contractError{}, "Invalid user")
+	r.PUT("/avatar", "").
+		Payload(avatar{}, specs.MediaTypeMultipartForm).
+		Response(204, nil, "Avatar uploaded").
+		Response(400, contractError{}, "Invalid avatar")
+
+	validate := validator.New()
+	received := map[string]bool{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			v   interface{} = &contractUser{}
+			err error
+		)
+		if r.URL.Path == "/avatar" {
+			v = &avatar{}
+		}
+		if err = specs.DefaultCodecs.DecodeRequest(r, v, specs.DefaultMaxFormMemory); err == nil {
+			err = validate.Struct(v)
+		}
+		if a, ok := v.(*avatar); ok && err == nil && a.Avatar.Header.Get("Content-Type") != "image/png" {
+			err = errors.New("avatar is not a png")
+		}
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(contractError{Message: err.Error()})
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		received[mediaType] = true
+		if r.URL.Path == "/avatar" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(v)
+	})
+
+	RunContract[string](t, r, handler)
+	for _, mediaType := range []string{specs.MediaTypeJSON, specs.MediaTypeXML, specs.MediaTypeURLEncodedForm, specs.MediaTypeMultipartForm} {
+		if !received[mediaType] {
+			t.Errorf("no valid %s payload was received", mediaType)
+		}
+	}
+}
+
+func TestRunContractDetectsViolations(t *testing.T) {
+	r := specs.NewRegistry[string](specs.OperationIDGenerator(specs.MethodPathOperationIDGenerator))
+	r.GET("/users", "").
+		Query(contractUserQuery{}).
+		Response(200, []contractUser{}, "Users found")
+	r.GET("/users/{id}", "").
+		Parameters(contractUserParameters{}).
+		Response(200, contractUser{}, "User found")
+	r.POST("/users", "").
+		Payload(contractUser{}).
+		Response(201, contractUser{}, "User created").
+		Response(400, contractError{}, "Invalid user")
+	o := newOptions([]Option{Iterations(defaultContractIterations)})
+	endpoints := newContractEndpoints[string](t, r, o)
+
+	writeJSON := func(w http.ResponseWriter, status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	tests := []struct {
+		name        string
+		operationID string
+		handler     http.HandlerFunc
+		want        string
+	}{
+		{
+			name:        "undeclared status",
+			operationID: "getUsersById",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusInternalServerError, contractError{Message: "boom"})
+			},
+			want: "undeclared status 500",
+		},
+		{
+			name:        "body not matching the schema",
+			operationID: "getUsers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, []map[string]interface{}{{"id": 1}})
+			},
+			want: "body not matching the schema",
+		},
+		{
+			name:        "undeclared content type",
+			operationID: "getUsers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				w.Write([]byte("users"))
+			},
+			want: "undeclared content type text/plain",
+		},
+		{
+			name:        "invalid input accepted",
+			operationID: "postUsers",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusCreated, contractUser{ID: "1f8a5bb6-6f39-4e3c-9d4a-2a1a8c1f5b11", Name: "a", Age: 1})
+			},
+			want: "malformed body was answered with status 201 instead of a 4xx status",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var endpoint *contractEndpoint
+			for _, e := range endpoints {
+				if e.operationID == tt.operationID {
+					e := e
+					endpoint = &e
+				}
+			}
+			if endpoint == nil {
+				t.Fatalf("endpoint %s not found", tt.operationID)
+			}
+
+			rec := &recorder{TB: t}
+			checkContract(rec, *endpoint, tt.handler, o)
+			if !containsError(rec.errors, tt.want) {
+				t.Errorf("got errors %v, want %s", rec.errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	r := specs.NewRegistry[string](specs.OperationIDGenerator(specs.MethodPathOperationIDGenerator))
+	r.POST("/users", "").
+		Payload(contractUser{}, specs.MediaTypeJSON, specs.MediaTypeXML, specs.MediaTypeURLEncodedForm, "text/csv").
+		Response(201, contractUser{}, "User created")
+	o := newOptions(nil)
+
+	tests := map[string][]string{
+		"postUsers application/json":                  {"malformed body", "body without required property age", "body property age of the wrong type"},
+		"postUsers application/xml":                   {"malformed body"},
+		"postUsers application/x-www-form-urlencoded": {"malformed body", "body without required property age", "body property age of the wrong type"},
+	}
+	endpoints := newContractEndpoints[string](t, r, o)
+	if len(endpoints) != len(tests) {
+		t.Fatalf("got %d endpoints, want %d", len(endpoints), len(tests))
+	}
+	for _, endpoint := range endpoints {
+		endpoint := endpoint
+		t.Run(endpoint.name, func(t *testing.T) {
+			req, ok := endpoint.validRequest(NewValueGenerator(1), o)
+			if !ok {
+				t.Fatal("no valid request generated")
+			}
+			var names []string
+			for _, invalid := range endpoint.invalidRequests(req) {
+				names = append(names, invalid.name)
+			}
+			if want := tests[endpoint.name]; !equalStrings(names, want) {
+				t.Errorf("got %v, want %v", names, want)
+			}
+		})
+	}
+}
+
+func containsError(errors []string, want string) bool {
+	for _, err := range errors {
+		if strings.Contains(err, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
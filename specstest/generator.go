@@ -0,0 +1,374 @@
+package specstest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jakoblorz/specs"
+)
+
+const (
+	generatorMaxDepth     = 4
+	generatorMaxItems     = 3
+	generatorMaxStringLen = 16
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	oneOfRegex   = regexp.MustCompile(`'[^']*'|\S+`)
+	epoch        = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	letters      = "abcdefghijklmnopqrstuvwxyz"
+	digits       = "0123456789"
+	alphanumeric = letters + digits
+)
+
+// constraints are the validate operators applying to a single value.
+type constraints struct {
+	required  bool
+	omitempty bool
+
+	min, max                   *float64
+	exclusiveMin, exclusiveMax bool
+
+	oneOf   []string
+	format  string
+	charset string
+
+	// dive holds the operators applying to the elements of slices and maps
+	dive *specs.FieldTag
+}
+
+func parseConstraints(tag *specs.FieldTag) constraints {
+	var c constraints
+	for current := tag; current != nil; current = current.Next {
+		switch current.Type {
+		case specs.TagTypeDive:
+			c.dive = current.Next
+			return c
+		case specs.TagTypeOmitEmpty:
+			c.omitempty = true
+			continue
+		case specs.TagTypeOr, specs.TagTypeKeys, specs.TagTypeEndKeys:
+			// values which do not satisfy these are rejected by the validator and not checked
+			continue
+		}
+
+		switch current.Operator {
+		case "":
+		case "required":
+			c.required = true
+		case "min", "gte":
+			c.min = parseFloat(current.Param)
+		case "max", "lte":
+			c.max = parseFloat(current.Param)
+		case "gt":
+			c.min, c.exclusiveMin = parseFloat(current.Param), true
+		case "lt":
+			c.max, c.exclusiveMax = parseFloat(current.Param), true
+		case "len":
+			c.min, c.max = parseFloat(current.Param), parseFloat(current.Param)
+		case "eq":
+			c.oneOf = []string{current.Param}
+		case "oneof":
+			for _, value := range oneOfRegex.FindAllString(current.Param, -1) {
+				c.oneOf = append(c.oneOf, strings.Trim(value, "'"))
+			}
+		case "email", "uuid", "uuid4", "uuid_rfc4122", "uuid4_rfc4122", "uri", "url", "http_url", "ipv4", "ipv6", "ip", "hostname", "datetime":
+			c.format = current.Operator
+		case "alpha", "lowercase":
+			c.charset = letters
+		case "alphanum":
+			c.charset = alphanumeric
+		case "numeric", "number":
+			c.charset = digits
+		case "uppercase":
+			c.charset = strings.ToUpper(letters)
+		}
+	}
+	return c
+}
+
+func parseFloat(param string) *float64 {
+	f, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		if d, err := time.ParseDuration(param); err == nil {
+			f = float64(d)
+		}
+	}
+	return &f
+}
+
+// ValueGenerator generates random values which satisfy the validate tags of their fields. The
+// output is deterministic for a given seed.
+type ValueGenerator struct {
+	rand *rand.Rand
+}
+
+func NewValueGenerator(seed int64) *ValueGenerator {
+	return &ValueGenerator{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Generate returns a pointer to a new random value of type t.
+func (g *ValueGenerator) Generate(t reflect.Type) interface{} {
+	v := reflect.New(t)
+	g.fill(v.Elem(), constraints{}, 0)
+	return v.Interface()
+}
+
+// skip decides whether an optional value is left empty.
+func (g *ValueGenerator) skip(c constraints) bool {
+	return !c.required && c.omitempty && g.rand.Intn(4) == 0
+}
+
+func (g *ValueGenerator) fill(v reflect.Value, c constraints, depth int) {
+	if depth > generatorMaxDepth || g.skip(c) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !c.required && c.omitempty && depth == generatorMaxDepth {
+			return
+		}
+		// the pointer is not empty anymore, the constraints apply to the value it points to
+		c.omitempty = false
+		elem := reflect.New(v.Type().Elem())
+		g.fill(elem.Elem(), c, depth+1)
+		v.Set(elem)
+
+	case reflect.Bool:
+		v.SetBool(c.required || g.rand.Intn(2) == 1)
+
+	case reflect.String:
+		v.SetString(g.string(c))
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) && c.min == nil && c.max == nil {
+			v.SetInt(int64(g.rand.Intn(3600)) * int64(time.Second))
+			return
+		}
+		bits := v.Type().Bits()
+		v.SetInt(int64(g.integer(c, -math.Pow(2, float64(bits-1)), math.Pow(2, float64(bits-1))-1)))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits := v.Type().Bits()
+		v.SetUint(uint64(g.integer(c, 0, math.Pow(2, float64(bits))-1)))
+
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(g.float(c))
+
+	case reflect.Slice:
+		n := g.length(c, depth)
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, n)
+			g.rand.Read(b)
+			v.SetBytes(b)
+			return
+		}
+		slice := reflect.MakeSlice(v.Type(), n, n)
+		elem := parseConstraints(c.dive)
+		for i := 0; i < n; i++ {
+			g.fill(slice.Index(i), elem, depth+1)
+		}
+		v.Set(slice)
+
+	case reflect.Array:
+		elem := parseConstraints(c.dive)
+		for i := 0; i < v.Len(); i++ {
+			g.fill(v.Index(i), elem, depth+1)
+		}
+
+	case reflect.Map:
+		n := g.length(c, depth)
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		elem := parseConstraints(c.dive)
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			g.fill(key, constraints{required: true}, depth+1)
+			value := reflect.New(v.Type().Elem()).Elem()
+			g.fill(value, elem, depth+1)
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			v.Set(reflect.ValueOf(epoch.Add(time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour)))).Truncate(time.Second)))
+			return
+		}
+		for _, field := range specs.GetTypeInfo(v.Type()).Fields {
+			fv, ok := fieldByIndex(v, field.Index)
+			if !ok || !fv.CanSet() {
+				continue
+			}
+			c := parseConstraints(field.ValidateTag())
+			// fields omitted by encoding/json are as optional as fields omitted by the validator
+			c.omitempty = c.omitempty || field.JSONOmitEmpty()
+			g.fill(fv, c, depth+1)
+		}
+	}
+}
+
+// fieldByIndex returns the nested field, allocating embedded pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// bounds returns the inclusive bounds of the constraints within [lo, hi] using the step for exclusive bounds.
+func (c constraints) bounds(lo, hi, step float64) (float64, float64) {
+	if c.min != nil {
+		min := *c.min
+		if c.exclusiveMin {
+			min += step
+		}
+		lo = math.Max(lo, min)
+	}
+	if c.max != nil {
+		max := *c.max
+		if c.exclusiveMax {
+			max -= step
+		}
+		hi = math.Min(hi, max)
+	}
+	return lo, hi
+}
+
+func (g *ValueGenerator) length(c constraints, depth int) int {
+	lo := float64(0)
+	if c.required {
+		lo = 1
+	}
+	lo, hi := c.bounds(lo, math.Inf(1), 1)
+	if depth >= generatorMaxDepth-1 {
+		// elements would exceed the depth and be left empty
+		return int(lo)
+	}
+	if math.IsInf(hi, 1) {
+		hi = lo + generatorMaxItems
+	}
+	if hi <= lo {
+		return int(lo)
+	}
+	return int(lo) + g.rand.Intn(int(hi-lo)+1)
+}
+
+func (g *ValueGenerator) integer(c constraints, lo, hi float64) float64 {
+	if len(c.oneOf) > 0 {
+		f, _ := strconv.ParseFloat(c.oneOf[g.rand.Intn(len(c.oneOf))], 64)
+		return f
+	}
+	lo, hi = c.bounds(math.Max(lo, -1000), math.Min(hi, 1000), 1)
+	if c.min != nil && c.max == nil {
+		hi = lo + 1000
+	}
+	if c.max != nil && c.min == nil {
+		lo = math.Max(hi-1000, lo)
+	}
+	lo, hi = math.Ceil(lo), math.Floor(hi)
+	if hi <= lo {
+		return lo
+	}
+	for {
+		value := lo + float64(g.rand.Int63n(int64(hi-lo)+1))
+		if value != 0 || !c.required {
+			return value
+		}
+	}
+}
+
+func (g *ValueGenerator) float(c constraints) float64 {
+	if len(c.oneOf) > 0 {
+		f, _ := strconv.ParseFloat(c.oneOf[g.rand.Intn(len(c.oneOf))], 64)
+		return f
+	}
+	lo, hi := c.bounds(-1000, 1000, 0)
+	if c.min != nil && c.max == nil {
+		hi = lo + 1000
+	}
+	if c.max != nil && c.min == nil {
+		lo = hi - 1000
+	}
+	for {
+		// values are rounded to keep them representable as float32
+		value := math.Round((lo+g.rand.Float64()*(hi-lo))*100) / 100
+		if value < lo || value > hi || (c.exclusiveMin && value == lo) || (c.exclusiveMax && value == hi) {
+			continue
+		}
+		if value != 0 || !c.required {
+			return value
+		}
+	}
+}
+
+func (g *ValueGenerator) string(c constraints) string {
+	if len(c.oneOf) > 0 {
+		return c.oneOf[g.rand.Intn(len(c.oneOf))]
+	}
+
+	word := func(n int) string {
+		charset := c.charset
+		if charset == "" {
+			charset = letters
+		}
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = charset[g.rand.Intn(len(charset))]
+		}
+		return string(b)
+	}
+
+	switch c.format {
+	case "email":
+		return word(1+g.rand.Intn(8)) + "@example.com"
+	case "uuid", "uuid4", "uuid_rfc4122", "uuid4_rfc4122":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "uri", "url", "http_url":
+		return "https://example.com/" + word(1+g.rand.Intn(8))
+	case "ipv4", "ip":
+		return fmt.Sprintf("192.0.2.%d", 1+g.rand.Intn(254))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+g.rand.Intn(0xffff))
+	case "hostname":
+		return word(1+g.rand.Intn(8)) + ".example.com"
+	case "datetime":
+		return epoch.Add(time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour)))).Format(time.RFC3339)
+	}
+
+	lo := float64(0)
+	if c.required {
+		lo = 1
+	}
+	lo, hi := c.bounds(lo, math.Inf(1), 1)
+	if math.IsInf(hi, 1) {
+		hi = lo + generatorMaxStringLen
+	}
+	n := int(lo)
+	if hi > lo {
+		n += g.rand.Intn(int(hi-lo) + 1)
+	}
+	return word(n)
+}
@@ -0,0 +1,242 @@
+// Package specstest provides property-based checks for types and registries built with specs.
+//
+// RoundTrip verifies that the schema generated for a type agrees with its validate tags: values
+// accepted by the validator must be accepted by the schema, and values accepted by the schema
+// must be accepted by the validator.
+//
+// RunContract verifies that a handler serves the endpoints of a registry as declared.
+//
+// The package is a module of its own, hence github.com/go-playground/validator is only a dependency
+// of tests using it.
+package specstest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-playground/validator/v10"
+	"github.com/jakoblorz/specs"
+)
+
+const defaultIterations = 100
+
+type Option func(*options)
+
+type options struct {
+	iterations    int
+	seed          int64
+	validate      *validator.Validate
+	schemaOptions []specs.SchemaRefGeneratorOption
+
+	codecs         *specs.Codecs
+	requestEditors []func(req *http.Request)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		iterations: defaultIterations,
+		seed:       1,
+		codecs:     specs.DefaultCodecs,
+	}
+	for _, applyOption := range opts {
+		applyOption(o)
+	}
+	if o.validate == nil {
+		o.validate = validator.New()
+		o.validate.RegisterTagNameFunc(jsonTagName)
+	}
+	return o
+}
+
+// Iterations sets the number of values checked in each direction, defaults to 100. RunContract
+// sends as many valid requests to each endpoint, defaults to 10.
+func Iterations(n int) Option {
+	return func(o *options) {
+		o.iterations = n
+	}
+}
+
+// Seed sets the seed of the random values, failures are reported with the seed to reproduce them.
+func Seed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+	}
+}
+
+// Validator sets the validator, use it to check against custom validations.
+func Validator(validate *validator.Validate) Option {
+	return func(o *options) {
+		o.validate = validate
+	}
+}
+
+// SchemaOptions sets the options of the schema generator, use it to check custom annotators.
+func SchemaOptions(opts ...specs.SchemaRefGeneratorOption) Option {
+	return func(o *options) {
+		o.schemaOptions = opts
+	}
+}
+
+// RoundTrip checks that the schema generated for the struct v and its validate tags accept the
+// same values. The first mismatch of each direction is reported as an error on t.
+func RoundTrip(t testing.TB, v interface{}, opts ...Option) {
+	t.Helper()
+	o := newOptions(opts)
+
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		t.Fatalf("specstest: RoundTrip requires a struct, got %v", typ)
+		return
+	}
+
+	schemas := openapi3.Schemas{}
+	schemaRef, err := specs.NewSchemaRefGenerator(o.schemaOptions...).GenerateSchemaRef(reflect.New(typ).Elem().Interface(), schemas)
+	if err != nil {
+		t.Fatalf("specstest: failed to generate schema of %v: %v", typ, err)
+		return
+	}
+	specs.ResolveSchemaRefs(schemaRef, schemas)
+
+	checkValidatorToSchema(t, typ, schemaRef.Value, o)
+	checkSchemaToValidator(t, typ, schemaRef, o)
+}
+
+// checkValidatorToSchema checks that values accepted by the validator are accepted by the schema.
+func checkValidatorToSchema(t testing.TB, typ reflect.Type, schema *openapi3.Schema, o *options) {
+	t.Helper()
+	generator := NewValueGenerator(o.seed)
+
+	var accepted int
+	var lastErr error
+	for i := 0; i < o.iterations; i++ {
+		value := generator.Generate(typ)
+		if err := o.validate.Struct(value); err != nil {
+			// the generator does not understand every operator, such values are not conclusive
+			lastErr = err
+			continue
+		}
+		accepted++
+
+		content, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("specstest: failed to marshal %v: %v", typ, err)
+			return
+		}
+		var data interface{}
+		if err := json.Unmarshal(content, &data); err != nil {
+			t.Fatalf("specstest: failed to unmarshal %v: %v", typ, err)
+			return
+		}
+		if err := schema.VisitJSON(data); err != nil {
+			t.Errorf("specstest: %v (seed %d, iteration %d) accepted by the validator but rejected by the schema:\n%s\n%v", typ, o.seed, i, content, err)
+			return
+		}
+	}
+	if accepted == 0 && o.iterations > 0 {
+		t.Errorf("specstest: none of the values generated for %v were accepted by the validator: %v", typ, lastErr)
+	}
+}
+
+// checkSchemaToValidator checks that values accepted by the schema are accepted by the validator.
+func checkSchemaToValidator(t testing.TB, typ reflect.Type, schemaRef *openapi3.SchemaRef, o *options) {
+	t.Helper()
+	faker := specs.NewSchemaFaker(o.seed)
+	r := rand.New(rand.NewSource(o.seed))
+
+	var accepted int
+	var lastErr error
+	for i := 0; i < o.iterations; i++ {
+		data := dropOptional(r, faker.Fake(schemaRef), schemaRef.Value, 0)
+		if err := schemaRef.Value.VisitJSON(data); err != nil {
+			// the faker does not understand every keyword, such values are not conclusive
+			lastErr = err
+			continue
+		}
+		accepted++
+
+		content, err := json.Marshal(data)
+		if err != nil {
+			t.Fatalf("specstest: failed to marshal %v: %v", typ, err)
+			return
+		}
+		value := reflect.New(typ).Interface()
+		if err := json.Unmarshal(content, value); err != nil {
+			t.Errorf("specstest: %v (seed %d, iteration %d) accepted by the schema but cannot be unmarshaled:\n%s\n%v", typ, o.seed, i, content, err)
+			return
+		}
+		if err := o.validate.Struct(value); err != nil {
+			t.Errorf("specstest: %v (seed %d, iteration %d) accepted by the schema but rejected by the validator:\n%s\n%v", typ, o.seed, i, content, err)
+			return
+		}
+	}
+	if accepted == 0 && o.iterations > 0 {
+		t.Errorf("specstest: none of the values faked for %v were accepted by the schema: %v", typ, lastErr)
+	}
+}
+
+// dropOptional removes some of the properties which are not required by the schema, the faker
+// always populates all of them.
+func dropOptional(r *rand.Rand, data interface{}, schema *openapi3.Schema, depth int) interface{} {
+	if schema == nil || depth > generatorMaxDepth {
+		return data
+	}
+
+	switch data := data.(type) {
+	case map[string]interface{}:
+		required := map[string]struct{}{}
+		for _, name := range schema.Required {
+			required[name] = struct{}{}
+		}
+		for _, name := range sortedNames(data) {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties.Schema != nil {
+					data[name] = dropOptional(r, data[name], schema.AdditionalProperties.Schema.Value, depth+1)
+				}
+				continue
+			}
+			if _, ok := required[name]; !ok && r.Intn(3) == 0 {
+				delete(data, name)
+				continue
+			}
+			data[name] = dropOptional(r, data[name], property.Value, depth+1)
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i := range data {
+				data[i] = dropOptional(r, data[i], schema.Items.Value, depth+1)
+			}
+		}
+	}
+	return data
+}
+
+func sortedNames[V interface{}](data map[string]V) []string {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonTagName reports validation errors with the json names of the fields.
+func jsonTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
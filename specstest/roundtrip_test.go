@@ -0,0 +1,160 @@
+package specstest
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-playground/validator/v10"
+	"github.com/jakoblorz/specs"
+)
+
+// recorder records the errors reported by RoundTrip.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type roundTripAddress struct {
+	Street string `json:"street" validate:"required,min=3,max=64"`
+	Zip    string `json:"zip" validate:"required,len=5"`
+}
+
+type roundTripNode struct {
+	Name     string           `json:"name" validate:"required,alphanum"`
+	Children []*roundTripNode `json:"children,omitempty" validate:"omitempty,max=2,dive"`
+}
+
+type roundTripUser struct {
+	ID       string            `json:"id" validate:"required,uuid"`
+	Email    string            `json:"email" validate:"required,email"`
+	Name     string            `json:"name" validate:"required,min=1,max=32"`
+	Age      int               `json:"age" validate:"required,gt=0,lte=150"`
+	Score    float64           `json:"score" validate:"gte=0,lt=1"`
+	Role     string            `json:"role" validate:"required,oneof=admin user 'read only'"`
+	Level    uint8             `json:"level" validate:"required,oneof=1 2 3"`
+	Tags     []string          `json:"tags" validate:"max=5,dive,min=2"`
+	Labels   map[string]string `json:"labels,omitempty" validate:"omitempty,max=3"`
+	Address  roundTripAddress  `json:"address" validate:"required"`
+	Previous *roundTripAddress `json:"previous,omitempty"`
+	Website  string            `json:"website,omitempty" validate:"omitempty,url"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "should accept consistent tags", v: roundTripUser{}},
+		{name: "should accept pointers", v: &roundTripAddress{}},
+		{name: "should accept cycles", v: roundTripNode{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RoundTrip(t, tt.v)
+		})
+	}
+}
+
+func TestRoundTripDetectsMismatches(t *testing.T) {
+	type minLength struct {
+		Name string `json:"name" validate:"min=5"`
+	}
+	type exclusiveMinimum struct {
+		Count int `json:"count" validate:"gt=10,lt=13"`
+	}
+
+	tests := []struct {
+		name      string
+		v         interface{}
+		operator  string
+		annotator specs.SchemaAnnotatorFunc
+	}{
+		{
+			name:     "min on strings as maxLength",
+			v:        minLength{},
+			operator: "min",
+			annotator: func(fieldTag *specs.FieldTag, schema *openapi3.Schema) {
+				max, _ := strconv.ParseUint(fieldTag.Param, 10, 64)
+				schema.MaxLength = &max
+			},
+		},
+		{
+			name:     "gt as inclusive minimum",
+			v:        exclusiveMinimum{},
+			operator: "gt",
+			annotator: func(fieldTag *specs.FieldTag, schema *openapi3.Schema) {
+				min, _ := strconv.ParseFloat(fieldTag.Param, 64)
+				schema.Min = &min
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			RoundTrip(r, tt.v, SchemaOptions(specs.SchemaAnnotatorMap(map[string]specs.SchemaAnnotatorFunc{tt.operator: tt.annotator})))
+			if len(r.errors) == 0 {
+				t.Error("mismatch was not detected")
+			}
+		})
+	}
+}
+
+func TestRoundTripValidator(t *testing.T) {
+	type even struct {
+		Value int `json:"value" validate:"even"`
+	}
+
+	validate := validator.New()
+	validate.RegisterValidation("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	})
+
+	r := &recorder{TB: t}
+	RoundTrip(r, even{}, Validator(validate))
+	if len(r.errors) == 0 {
+		t.Error("custom validation without annotator was not detected")
+	}
+}
+
+func TestValueGenerator(t *testing.T) {
+	validate := validator.New()
+	g := NewValueGenerator(42)
+	for i := 0; i < 100; i++ {
+		if err := validate.Struct(g.Generate(reflect.TypeOf(roundTripUser{}))); err != nil {
+			t.Fatalf("iteration %d: %v", i, err)
+		}
+	}
+}
+
+func TestRoundTripInconclusiveSchema(t *testing.T) {
+	type unsatisfiable struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	r := &recorder{TB: t}
+	RoundTrip(r, unsatisfiable{}, SchemaOptions(specs.SchemaAnnotatorMap(map[string]specs.SchemaAnnotatorFunc{
+		"required": func(fieldTag *specs.FieldTag, schema *openapi3.Schema) {
+			schema.Not = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		},
+	})))
+	for _, err := range r.errors {
+		if strings.Contains(err, "were accepted by the schema") {
+			return
+		}
+	}
+	t.Errorf("schema accepting none of the faked values was not reported: %v", r.errors)
+}
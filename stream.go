@@ -0,0 +1,181 @@
+package specs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrStreamAnnotationFailed = errors.New("stream annotation failed")
+	ErrStreamEventInvalid     = errors.New("stream event invalid")
+)
+
+const (
+	MediaTypeEventStream = "text/event-stream"
+	MediaTypeNDJSON      = "application/x-ndjson"
+)
+
+// StreamEvent is an event of a streamed response, declared using Builder.Stream.
+type StreamEvent struct {
+	Name  string
+	Value interface{}
+}
+
+// Event declares an event of a streamed response, whose data is the json representation of value.
+func Event(name string, value interface{}) StreamEvent {
+	return StreamEvent{
+		Name:  name,
+		Value: value,
+	}
+}
+
+// streamContent declares a streamed response as text/event-stream and application/x-ndjson. Both
+// share the item schema, an envelope of the event name and its data, and list the data schema of
+// every event in x-events.
+func streamContent(events []StreamEvent, generator *SchemaRefGenerator, schemas openapi3.Schemas) (openapi3.Content, error) {
+	xEvents := make(map[string]*openapi3.SchemaRef, len(events))
+	itemSchema := openapi3.NewOneOfSchema()
+	for _, event := range events {
+		dataRef, err := generator.GenerateSchemaRef(event.Value, schemas)
+		if err != nil {
+			return nil, err
+		}
+		xEvents[event.Name] = dataRef
+
+		envelope := openapi3.NewObjectSchema().
+			WithProperty("event", openapi3.NewStringSchema().WithEnum(event.Name)).
+			WithPropertyRef("data", dataRef)
+		envelope.Required = []string{"event", "data"}
+		itemSchema.OneOf = append(itemSchema.OneOf, envelope.NewRef())
+	}
+
+	content := openapi3.Content{}
+	for _, mediaType := range []string{MediaTypeEventStream, MediaTypeNDJSON} {
+		content[mediaType] = &openapi3.MediaType{
+			Schema:     itemSchema.NewRef(),
+			Extensions: map[string]interface{}{"x-events": xEvents},
+		}
+	}
+	return content, nil
+}
+
+type StreamWriterOption func(*streamWriterOptions)
+
+type streamWriterOptions struct {
+	mediaType string
+	events    map[string]reflect.Type
+}
+
+// StreamMediaType frames the events as mediaType (MediaTypeEventStream or MediaTypeNDJSON) instead of
+// negotiating it using the Accept header.
+func StreamMediaType(mediaType string) StreamWriterOption {
+	return func(o *streamWriterOptions) {
+		o.mediaType = mediaType
+	}
+}
+
+// ValidateEvents rejects events which are not declared or whose value does not have the declared
+// type, e.g. in debug builds.
+func ValidateEvents(events ...StreamEvent) StreamWriterOption {
+	return func(o *streamWriterOptions) {
+		o.events = make(map[string]reflect.Type, len(events))
+		for _, event := range events {
+			o.events[event.Name] = removeIndirect(reflect.TypeOf(event.Value))
+		}
+	}
+}
+
+// StreamWriter writes framed events of a streamed response and flushes each of them.
+type StreamWriter struct {
+	w         http.ResponseWriter
+	mediaType string
+	events    map[string]reflect.Type
+}
+
+// NewStreamWriter returns a StreamWriter framing events as application/x-ndjson if the request
+// prefers it and as text/event-stream otherwise. The response headers are set but not yet written.
+func NewStreamWriter(w http.ResponseWriter, req *http.Request, opts ...StreamWriterOption) *StreamWriter {
+	options := &streamWriterOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.mediaType == "" {
+		options.mediaType = negotiateStreamMediaType(req.Header.Get("Accept"))
+	}
+
+	w.Header().Set("Content-Type", options.mediaType)
+	w.Header().Set("Cache-Control", "no-cache")
+	return &StreamWriter{
+		w:         w,
+		mediaType: options.mediaType,
+		events:    options.events,
+	}
+}
+
+func negotiateStreamMediaType(accept string) string {
+	for _, accepted := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if mediaType == MediaTypeEventStream || mediaType == MediaTypeNDJSON {
+			return mediaType
+		}
+	}
+	return MediaTypeEventStream
+}
+
+// MediaType is the media type the events are framed as.
+func (s *StreamWriter) MediaType() string {
+	return s.mediaType
+}
+
+// Send writes the event and flushes it to the client.
+func (s *StreamWriter) Send(name string, v interface{}) error {
+	if strings.ContainsAny(name, "\r\n") {
+		return fmt.Errorf("invalid event name %q: %w", name, ErrStreamEventInvalid)
+	}
+	if s.events != nil {
+		declared, ok := s.events[name]
+		if !ok {
+			return fmt.Errorf("event %s is not declared: %w", name, ErrStreamEventInvalid)
+		}
+		if v == nil || removeIndirect(reflect.TypeOf(v)) != declared {
+			return fmt.Errorf("event %s has value of type %T, want %v: %w", name, v, declared, ErrStreamEventInvalid)
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event %s: %w", name, err)
+	}
+
+	if s.mediaType == MediaTypeNDJSON {
+		line, err := json.Marshal(struct {
+			Event string          `json:"event"`
+			Data  json.RawMessage `json:"data"`
+		}{name, data})
+		if err != nil {
+			return fmt.Errorf("failed to marshal event %s: %w", name, err)
+		}
+		if _, err := fmt.Fprintf(s.w, "%s\n", line); err != nil {
+			return err
+		}
+	} else {
+		if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data); err != nil {
+			return err
+		}
+	}
+
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
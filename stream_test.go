@@ -0,0 +1,136 @@
+package specs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type streamTestProgress struct {
+	Percent int `json:"percent" validate:"required,min=0,max=100"`
+}
+
+type streamTestDone struct {
+	URL string `json:"url" validate:"required,url"`
+}
+
+func TestAnnotateStream(t *testing.T) {
+	r := NewRegistry[string]()
+	r.GET("/exports/progress", "").
+		Stream(200, Event("progress", streamTestProgress{}), Event("done", &streamTestDone{})).
+		Response(404, nil, "Export not found")
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc)
+	if err := doc.Validate(openapi3.NewLoader().Context); err != nil {
+		t.Fatal(err)
+	}
+
+	content := doc.Paths.Find("/exports/progress").Get.Responses.Get(200).Value.Content
+	for _, mediaType := range []string{MediaTypeEventStream, MediaTypeNDJSON} {
+		t.Run(mediaType, func(t *testing.T) {
+			mt := content.Get(mediaType)
+			if mt == nil {
+				t.Fatalf("media type %s not declared", mediaType)
+			}
+			events, _ := mt.Extensions["x-events"].(map[string]*openapi3.SchemaRef)
+			if events["progress"].Value.Properties["percent"] == nil || events["done"].Value.Properties["url"] == nil {
+				t.Errorf("got x-events %v", events)
+			}
+			if len(mt.Schema.Value.OneOf) != 2 {
+				t.Fatalf("got %d item schemas, want 2", len(mt.Schema.Value.OneOf))
+			}
+			item := mt.Schema.Value.OneOf[0].Value
+			if item.Properties["event"].Value.Enum[0] != "progress" || item.Properties["data"].Value.Properties["percent"] == nil {
+				t.Errorf("got item schema %+v", item)
+			}
+		})
+	}
+}
+
+func TestStreamRejectsInvalidEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []StreamEvent
+	}{
+		{name: "should reject streams without events", events: nil},
+		{name: "should reject unnamed events", events: []StreamEvent{Event("", streamTestDone{})}},
+		{name: "should reject events without value", events: []StreamEvent{Event("done", nil)}},
+		{name: "should reject duplicate events", events: []StreamEvent{Event("done", streamTestDone{}), Event("done", streamTestDone{})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrStreamAnnotationFailed) {
+					t.Errorf("got %v, want %v", err, ErrStreamAnnotationFailed)
+				}
+			}()
+			NewRegistry[string]().GET("/exports", "").Stream(200, tt.events...)
+		})
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		opts   []StreamWriterOption
+		want   string
+	}{
+		{
+			name: "should frame server-sent events by default",
+			want: "event: progress\ndata: {\"percent\":50}\n\n",
+		},
+		{
+			name:   "should frame ndjson if accepted",
+			accept: "application/x-ndjson, text/event-stream",
+			want:   "{\"event\":\"progress\",\"data\":{\"percent\":50}}\n",
+		},
+		{
+			name:   "should use the media type option",
+			accept: "application/x-ndjson",
+			opts:   []StreamWriterOption{StreamMediaType(MediaTypeEventStream)},
+			want:   "event: progress\ndata: {\"percent\":50}\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/exports", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+
+			s := NewStreamWriter(rec, req, tt.opts...)
+			if err := s.Send("progress", streamTestProgress{Percent: 50}); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if got := rec.Header().Get("Content-Type"); got != s.MediaType() {
+				t.Errorf("got content type %s, want %s", got, s.MediaType())
+			}
+			if !rec.Flushed {
+				t.Error("event was not flushed")
+			}
+		})
+	}
+
+	t.Run("should validate events", func(t *testing.T) {
+		s := NewStreamWriter(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/exports", nil),
+			ValidateEvents(Event("progress", streamTestProgress{}), Event("done", &streamTestDone{})))
+
+		if err := s.Send("done", &streamTestDone{URL: "https://example.com"}); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if err := s.Send("done", streamTestProgress{}); !errors.Is(err, ErrStreamEventInvalid) {
+			t.Errorf("got %v, want %v", err, ErrStreamEventInvalid)
+		}
+		if err := s.Send("failed", streamTestDone{}); !errors.Is(err, ErrStreamEventInvalid) {
+			t.Errorf("got %v, want %v", err, ErrStreamEventInvalid)
+		}
+	})
+}
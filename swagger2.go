@@ -0,0 +1,254 @@
+package specs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Swagger2Warning describes a construct of the OpenAPI 3 document that cannot be represented
+// in Swagger 2.0 and has been dropped or simplified during the conversion.
+type Swagger2Warning struct {
+	Path    string
+	Message string
+}
+
+func (w Swagger2Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Path, w.Message)
+}
+
+// AnnotateSwagger2 annotates t like Annotate and returns the equivalent Swagger 2.0 document.
+func (r *registry[T]) AnnotateSwagger2(t *openapi3.T, filters ...EndpointFilter[T]) (*openapi2.T, []Swagger2Warning, error) {
+	r.Annotate(t, filters...)
+	return ConvertToSwagger2(t)
+}
+
+// ConvertToSwagger2 converts an OpenAPI 3 document into Swagger 2.0 for gateways that cannot import
+// OpenAPI 3. Request bodies become "in: body" (or formData) parameters, components.schemas become
+// definitions and the servers are flattened into host, basePath and schemes. The input document is
+// not modified.
+func ConvertToSwagger2(t *openapi3.T) (*openapi2.T, []Swagger2Warning, error) {
+	doc3, err := cloneOpenAPI3(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc3.Components == nil {
+		doc3.Components = &openapi3.Components{}
+	}
+
+	warnings := collectSwagger2Warnings(doc3)
+
+	// operations without a payload are annotated with an empty request body, which has no
+	// representation in swagger 2.0
+	for _, pathItem := range doc3.Paths {
+		for _, operation := range pathItem.Operations() {
+			if operation.RequestBody != nil && operation.RequestBody.Value != nil && len(operation.RequestBody.Value.Content) == 0 {
+				operation.RequestBody = nil
+			}
+		}
+	}
+
+	doc2, err := openapi2conv.FromV3(doc3)
+	if err != nil {
+		return nil, warnings, fmt.Errorf("failed to convert to swagger 2.0: %w", err)
+	}
+
+	// openapi2conv only keeps application/json responses and does not declare produces
+	for path, pathItem := range doc3.Paths {
+		operations := pathItem.Operations()
+		for _, method := range sortedKeys(operations) {
+			operation := operations[method]
+			operation2 := doc2.Paths[path].GetOperation(method)
+			if operation2 == nil {
+				continue
+			}
+
+			produces := map[string]struct{}{}
+			for status, responseRef := range operation.Responses {
+				if responseRef.Value == nil {
+					continue
+				}
+				mediaTypes := sortedContentTypes(responseRef.Value.Content)
+				for _, mediaType := range mediaTypes {
+					produces[mediaType] = struct{}{}
+				}
+				response2 := operation2.Responses[status]
+				if response2 == nil || response2.Schema != nil || len(mediaTypes) == 0 {
+					continue
+				}
+				if content := responseRef.Value.Content[mediaTypes[0]]; content.Schema != nil {
+					response2.Schema, _ = openapi2conv.FromV3SchemaRef(content.Schema, doc3.Components)
+				}
+			}
+			for mediaType := range produces {
+				operation2.Produces = append(operation2.Produces, mediaType)
+			}
+			sort.Strings(operation2.Produces)
+		}
+	}
+
+	return doc2, warnings, nil
+}
+
+func cloneOpenAPI3(t *openapi3.T) (*openapi3.T, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal openapi document: %w", err)
+	}
+	doc := new(openapi3.T)
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal openapi document: %w", err)
+	}
+	return doc, nil
+}
+
+func sortedContentTypes(content openapi3.Content) []string {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+func collectSwagger2Warnings(doc *openapi3.T) []Swagger2Warning {
+	var warnings []Swagger2Warning
+	warn := func(path string, format string, args ...interface{}) {
+		warnings = append(warnings, Swagger2Warning{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, server := range doc.Servers {
+		if len(server.Variables) > 0 {
+			warn(fmt.Sprintf("servers[%d]", i), "server variables are not supported, %s is used verbatim", server.URL)
+		}
+		if i == 0 {
+			continue
+		}
+		first, errFirst := url.Parse(doc.Servers[0].URL)
+		other, errOther := url.Parse(server.URL)
+		if errFirst == nil && errOther == nil && (first.Host != other.Host || first.Path != other.Path) {
+			warn(fmt.Sprintf("servers[%d]", i), "only the first server determines host and basePath, %s is dropped", server.URL)
+		}
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		operations := doc.Paths[path].Operations()
+		for _, method := range sortedKeys(operations) {
+			operation := operations[method]
+			location := fmt.Sprintf("paths.%s.%s", path, strings.ToLower(method))
+
+			for _, parameterRef := range operation.Parameters {
+				if parameterRef.Value == nil {
+					continue
+				}
+				if parameterRef.Value.In == openapi3.ParameterInCookie {
+					warn(location, "cookie parameter %s is not supported", parameterRef.Value.Name)
+				}
+				if parameterRef.Value.Schema != nil {
+					collectSwagger2SchemaWarnings(location+".parameters."+parameterRef.Value.Name, parameterRef.Value.Schema, warn, map[*openapi3.Schema]bool{})
+				}
+			}
+
+			if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+				content := operation.RequestBody.Value.Content
+				if len(content) > 1 {
+					warn(location+".requestBody", "only a single body schema is supported, the schema of %s is used for all of %s", findSwagger2BodyMediaType(content), strings.Join(sortedContentTypes(content), ", "))
+				}
+				for _, mediaType := range sortedContentTypes(content) {
+					collectSwagger2SchemaWarnings(location+".requestBody."+mediaType, content[mediaType].Schema, warn, map[*openapi3.Schema]bool{})
+				}
+			}
+
+			for _, status := range sortedKeys(operation.Responses) {
+				responseRef := operation.Responses[status]
+				if responseRef.Value == nil {
+					continue
+				}
+				content := responseRef.Value.Content
+				if len(content) > 1 {
+					warn(location+".responses."+status, "only a single response schema is supported for %s", strings.Join(sortedContentTypes(content), ", "))
+				}
+				if len(responseRef.Value.Links) > 0 {
+					warn(location+".responses."+status, "links are not supported")
+				}
+				for _, mediaType := range sortedContentTypes(content) {
+					collectSwagger2SchemaWarnings(location+".responses."+status+"."+mediaType, content[mediaType].Schema, warn, map[*openapi3.Schema]bool{})
+				}
+			}
+
+			if len(operation.Callbacks) > 0 {
+				warn(location, "callbacks are not supported")
+			}
+		}
+	}
+
+	if doc.Components != nil {
+		names := make([]string, 0, len(doc.Components.Schemas))
+		for name := range doc.Components.Schemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			collectSwagger2SchemaWarnings("components.schemas."+name, doc.Components.Schemas[name], warn, map[*openapi3.Schema]bool{})
+		}
+	}
+	return warnings
+}
+
+func findSwagger2BodyMediaType(content openapi3.Content) string {
+	if _, ok := content["application/json"]; ok {
+		return "application/json"
+	}
+	return sortedContentTypes(content)[0]
+}
+
+func collectSwagger2SchemaWarnings(location string, schemaRef *openapi3.SchemaRef, warn func(string, string, ...interface{}), visited map[*openapi3.Schema]bool) {
+	if schemaRef == nil || schemaRef.Ref != "" || schemaRef.Value == nil || visited[schemaRef.Value] {
+		return
+	}
+	schema := schemaRef.Value
+	visited[schema] = true
+
+	if len(schema.OneOf) > 0 {
+		warn(location, "oneOf is not supported")
+	}
+	if len(schema.AnyOf) > 0 {
+		warn(location, "anyOf is not supported")
+	}
+	if schema.Not != nil {
+		warn(location, "not is not supported")
+	}
+	if schema.Nullable {
+		warn(location, "nullable is only representable as the x-nullable extension")
+	}
+	if schema.WriteOnly {
+		warn(location, "writeOnly is not supported")
+	}
+
+	for _, child := range schema.AllOf {
+		collectSwagger2SchemaWarnings(location, child, warn, visited)
+	}
+	collectSwagger2SchemaWarnings(location+".items", schema.Items, warn, visited)
+	collectSwagger2SchemaWarnings(location+".additionalProperties", schema.AdditionalProperties.Schema, warn, visited)
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		collectSwagger2SchemaWarnings(location+"."+name, schema.Properties[name], warn, visited)
+	}
+}
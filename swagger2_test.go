@@ -0,0 +1,145 @@
+package specs
+
+import (
+	"mime/multipart"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type swagger2TestUser struct {
+	ID      string              `json:"id"`
+	Name    string              `json:"name" validate:"required"`
+	Friends []*swagger2TestUser `json:"friends"`
+}
+
+type swagger2TestParams struct {
+	ID string `json:"id"`
+}
+
+type swagger2TestAvatar struct {
+	Avatar *multipart.FileHeader `form:"avatar" validate:"required"`
+	Alt    string                `form:"alt"`
+}
+
+func TestConvertToSwagger2(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.POST("/users", "").Payload(swagger2TestUser{}).Response(201, swagger2TestUser{}, "User created")
+	r.GET("/users/{id}", "").Parameters(swagger2TestParams{}).Response(200, swagger2TestUser{}, "User found", MediaTypeXML, MediaTypeJSON)
+	r.DELETE("/users/{id}", "").Parameters(swagger2TestParams{}).Response(204, nil, "User deleted")
+	r.PUT("/users/{id}/avatar", "").Parameters(swagger2TestParams{}).Payload(swagger2TestAvatar{}, MediaTypeMultipartForm).Response(204, nil, "Avatar uploaded")
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "test", Version: "1.0.0"},
+		Servers: openapi3.Servers{{URL: "https://api.example.com/v1"}},
+	}
+	doc2, warnings, err := r.AnnotateSwagger2(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Path != "paths./users/{id}.get.responses.200" {
+		t.Errorf("got warnings %v", warnings)
+	}
+
+	t.Run("should flatten the server", func(t *testing.T) {
+		if doc2.Host != "api.example.com" || doc2.BasePath != "/v1" || !reflect.DeepEqual(doc2.Schemes, []string{"https"}) {
+			t.Errorf("got host %s, basePath %s, schemes %v", doc2.Host, doc2.BasePath, doc2.Schemes)
+		}
+	})
+	t.Run("should declare the payload as body parameter", func(t *testing.T) {
+		params := doc2.Paths["/users"].Post.Parameters
+		if len(params) != 1 || params[0].In != "body" || params[0].Schema == nil {
+			t.Errorf("got parameters %+v", params)
+		}
+	})
+	t.Run("should declare form payloads as formData parameters", func(t *testing.T) {
+		in := map[string]string{}
+		for _, param := range doc2.Paths["/users/{id}/avatar"].Put.Parameters {
+			in[param.Name] = param.In + ":" + param.Type
+		}
+		if want := map[string]string{"id": "path:string", "avatar": "formData:file", "alt": "formData:string"}; !reflect.DeepEqual(in, want) {
+			t.Errorf("got %v, want %v", in, want)
+		}
+	})
+	t.Run("should declare cycles as definitions", func(t *testing.T) {
+		if doc2.Definitions["swagger2TestUser"] == nil {
+			t.Errorf("got definitions %v", doc2.Definitions)
+		}
+	})
+	t.Run("should produce every response media type", func(t *testing.T) {
+		get := doc2.Paths["/users/{id}"].Get
+		if !reflect.DeepEqual(get.Produces, []string{MediaTypeJSON, MediaTypeXML}) || get.Responses["200"].Schema == nil {
+			t.Errorf("got produces %v, responses %v", get.Produces, get.Responses)
+		}
+		if response := doc2.Paths["/users/{id}"].Delete.Responses["204"]; response == nil || response.Schema != nil {
+			t.Errorf("got response %+v", response)
+		}
+	})
+	t.Run("should not modify the input document", func(t *testing.T) {
+		if doc.Paths["/users"].Post.RequestBody == nil {
+			t.Error("got request body removed")
+		}
+	})
+}
+
+func TestConvertToSwagger2Warnings(t *testing.T) {
+	cookie := &openapi3.ParameterRef{Value: openapi3.NewCookieParameter("session").WithSchema(openapi3.NewStringSchema())}
+	oneOf := openapi3.NewOneOfSchema(openapi3.NewStringSchema(), openapi3.NewIntegerSchema())
+	response := openapi3.NewResponse().WithDescription("ok").WithJSONSchema(openapi3.NewObjectSchema().WithProperty("value", oneOf))
+	response.Links = openapi3.Links{"self": &openapi3.LinkRef{Value: &openapi3.Link{OperationID: "get"}}}
+	operation := func() *openapi3.Operation {
+		return &openapi3.Operation{
+			Parameters: openapi3.Parameters{cookie},
+			Responses:  openapi3.Responses{"200": &openapi3.ResponseRef{Value: response}, "404": &openapi3.ResponseRef{Value: response}},
+			Callbacks:  openapi3.Callbacks{"done": &openapi3.CallbackRef{Value: &openapi3.Callback{}}},
+		}
+	}
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    &openapi3.Info{Title: "test", Version: "1.0.0"},
+		Servers: openapi3.Servers{
+			{URL: "https://api.example.com/v1"},
+			{URL: "https://{env}.example.com/v1", Variables: map[string]*openapi3.ServerVariable{"env": {Default: "api"}}},
+			{URL: "https://other.example.com/v1"},
+		},
+		Paths: openapi3.Paths{"/items": &openapi3.PathItem{
+			Get:    operation(),
+			Post:   operation(),
+			Delete: operation(),
+		}},
+	}
+
+	want := []string{
+		"servers[1]: server variables are not supported, https://{env}.example.com/v1 is used verbatim",
+		"servers[2]: only the first server determines host and basePath, https://other.example.com/v1 is dropped",
+	}
+	for _, method := range []string{"delete", "get", "post"} {
+		location := "paths./items." + method
+		want = append(want,
+			location+": cookie parameter session is not supported",
+			location+".responses.200: links are not supported",
+			location+".responses.200.application/json.value: oneOf is not supported",
+			location+".responses.404: links are not supported",
+			location+".responses.404.application/json.value: oneOf is not supported",
+			location+": callbacks are not supported",
+		)
+	}
+
+	// map iteration is random, the order must be stable nevertheless
+	for i := 0; i < 10; i++ {
+		_, warnings, err := ConvertToSwagger2(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]string, 0, len(warnings))
+		for _, warning := range warnings {
+			got = append(got, warning.String())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got warnings\n%v\nwant\n%v", got, want)
+		}
+	}
+}
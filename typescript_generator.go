@@ -0,0 +1,512 @@
+package specs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	ErrTypeScriptGenerationFailed = errors.New("typescript generation failed")
+)
+
+var (
+	typeScriptIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+const (
+	typeScriptHeader      = "// Code generated by github.com/jakoblorz/specs. DO NOT EDIT.\n\n"
+	typeScriptTypesFile   = "types.ts"
+	typeScriptRuntimeFile = "runtime.ts"
+	typeScriptDefaultTag  = "default"
+)
+
+// typeScriptTypes collects the interfaces emitted for named go types.
+type typeScriptTypes struct {
+	names      map[reflect.Type]string
+	taken      map[string]reflect.Type
+	interfaces map[string]string
+}
+
+func newTypeScriptTypes() *typeScriptTypes {
+	return &typeScriptTypes{
+		names:      map[reflect.Type]string{},
+		taken:      map[string]reflect.Type{},
+		interfaces: map[string]string{},
+	}
+}
+
+func (ts *typeScriptTypes) name(t reflect.Type) string {
+	if name, ok := ts.names[t]; ok {
+		return name
+	}
+	base := goIdentifier(schemaTypeName(t), true)
+	name := base
+	for n := 2; ; n++ {
+		if _, ok := ts.taken[name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(n)
+	}
+	ts.names[t] = name
+	ts.taken[name] = t
+	return name
+}
+
+// typeExpr returns the typescript type expression for t. The schema is the one generated for
+// the same type by the SchemaRefGenerator and is used to derive enums and required properties.
+func (ts *typeScriptTypes) typeExpr(t reflect.Type, schemaRef *openapi3.SchemaRef) string {
+	var schema *openapi3.Schema
+	if schemaRef != nil {
+		schema = schemaRef.Value
+	}
+	if schema != nil && len(schema.Enum) > 0 {
+		literals := make([]string, 0, len(schema.Enum))
+		for _, value := range schema.Enum {
+			b, _ := jsonMarshalNoEscape(value)
+			literals = append(literals, string(b))
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return "string"
+	case rawMessageType:
+		return "unknown"
+	case fileHeaderType, fileType:
+		return "Blob"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		var items *openapi3.SchemaRef
+		if schema != nil {
+			items = schema.Items
+		}
+		elem := ts.typeExpr(t.Elem(), items)
+		if strings.ContainsAny(elem, "|&") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		var additionalProperties *openapi3.SchemaRef
+		if schema != nil {
+			additionalProperties = schema.AdditionalProperties.Schema
+		}
+		return "Record<string, " + ts.typeExpr(t.Elem(), additionalProperties) + ">"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return ts.objectLiteral(t, schema, "")
+		}
+		if _, ok := ts.names[t]; !ok {
+			name := ts.name(t)
+			ts.interfaces[name] = "" // reserve to stop recursion
+			ts.interfaces[name] = fmt.Sprintf("export interface %s %s\n", name, ts.objectLiteral(t, schema, ""))
+		}
+		return ts.names[t]
+	}
+	return "unknown"
+}
+
+func (ts *typeScriptTypes) objectLiteral(t reflect.Type, schema *openapi3.Schema, indent string) string {
+	required := map[string]bool{}
+	if schema != nil {
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+	}
+
+	b := new(strings.Builder)
+	b.WriteString("{\n")
+	for _, field := range GetTypeInfo(t).Fields {
+		var property *openapi3.SchemaRef
+		if schema != nil {
+			property = schema.Properties[field.Name]
+		}
+		if schema != nil && schema.Properties != nil && property == nil {
+			continue
+		}
+		optional := "?"
+		if required[field.Name] {
+			optional = ""
+		}
+		fmt.Fprintf(b, "%s  %s%s: %s;\n", indent, typeScriptPropertyName(field.Name), optional, ts.typeExpr(field.Type, property))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// typeScriptFormNames returns an object literal mapping the property names of a form payload to
+// the names of its form fields.
+func typeScriptFormNames(v interface{}) string {
+	var names []string
+	for _, field := range GetTypeInfo(reflect.TypeOf(v)).Fields {
+		names = append(names, fmt.Sprintf("%s: %s", typeScriptPropertyName(field.Name), strconv.Quote(field.FormName())))
+	}
+	return "{ " + strings.Join(names, ", ") + " }"
+}
+
+// typeScriptQueryGrammar returns an object literal naming the sort, filter and filterable properties
+// of a query, which are encoded like Sort.EncodeQuery and Filters.EncodeQuery.
+func typeScriptQueryGrammar(g queryGrammar) string {
+	var properties []string
+	if g.sort != nil {
+		properties = append(properties, "sort: "+strconv.Quote(g.sort.Name))
+	}
+	if g.filter != nil {
+		properties = append(properties, "filter: "+strconv.Quote(g.filter.Name))
+	}
+	if len(g.filterable) > 0 {
+		names := make([]string, len(g.filterable))
+		for i, field := range g.filterable {
+			names[i] = strconv.Quote(field.Name)
+		}
+		properties = append(properties, "filterable: ["+strings.Join(names, ", ")+"]")
+	}
+	return "{ " + strings.Join(properties, ", ") + " }"
+}
+
+func typeScriptPropertyName(name string) string {
+	if typeScriptIdentifierRegex.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// containsTypeScriptIdentifier reports whether the identifier occurs in code as a whole word.
+func containsTypeScriptIdentifier(code string, identifier string) bool {
+	isIdentifierByte := func(c byte) bool {
+		return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	for offset := 0; ; {
+		i := strings.Index(code[offset:], identifier)
+		if i == -1 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(identifier)
+		if (start == 0 || !isIdentifierByte(code[start-1])) && (end == len(code) || !isIdentifierByte(code[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func jsonMarshalNoEscape(v interface{}) ([]byte, error) {
+	b := new(bytes.Buffer)
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(v)
+	return bytes.TrimSpace(b.Bytes()), err
+}
+
+type typeScriptOperation struct {
+	methodName string
+	code       string
+}
+
+// GenerateTypeScript returns the typescript sources keyed by file name: the interfaces of all
+// declared types in types.ts, the fetch runtime in runtime.ts and a typed client per tag. Methods
+// are named after the operation IDs, which must be stable (see MethodPathOperationIDGenerator) to
+// keep the output deterministic.
+func (r *registry[T]) GenerateTypeScript() (map[string][]byte, error) {
+	ts := newTypeScriptTypes()
+	schemas := make(openapi3.Schemas)
+	schemaGenerator := NewSchemaRefGenerator(WithTypeInfoCache(NewTypeInfoCache()))
+
+	typeExpr := func(v interface{}) (string, error) {
+		schemaRef, err := schemaGenerator.GenerateSchemaRef(v, schemas)
+		if err != nil {
+			return "", err
+		}
+		return ts.typeExpr(reflect.TypeOf(v), schemaRef), nil
+	}
+
+	var endpoints []*Endpoint[T]
+	for _, endpoint := range r.sortedEndpoints() {
+		if !isChannel(endpoint) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	if err := r.requireStableOperationIDs(endpoints); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrTypeScriptGenerationFailed)
+	}
+
+	operationsByTag := map[string][]typeScriptOperation{}
+	for _, endpoint := range endpoints {
+		methodName := goIdentifier(endpoint.OperationID, false)
+		args := []string{}
+		code := new(strings.Builder)
+
+		if endpoint.Parameters != nil {
+			expr, err := typeExpr(endpoint.Parameters)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "params: "+expr)
+		}
+		if endpoint.Query != nil {
+			expr, err := typeExpr(endpoint.Query)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "query: "+expr)
+		}
+		var payload *Body
+		if len(endpoint.Payload) > 0 && endpoint.Method != http.MethodGet {
+			payload = &endpoint.Payload[0]
+			expr, err := typeExpr(payload.Value)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "payload: "+expr)
+		}
+		args = append(args, "init?: RequestInit")
+
+		result := "void"
+		if status, ok := successResponseStatus(endpoint); ok && endpoint.Response[status].Value != nil {
+			expr, err := typeExpr(endpoint.Response[status].Value)
+			if err != nil {
+				return nil, err
+			}
+			result = expr
+		}
+		for _, status := range sortedResponseStatuses(endpoint.Response) {
+			if response := endpoint.Response[status]; response.Value != nil {
+				if _, err := typeExpr(response.Value); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		pathExpr := new(strings.Builder)
+		for _, segment := range parsePathTemplate(endpoint.Path).Segments {
+			if segment.IsParam() {
+				fmt.Fprintf(pathExpr, "/${encodeURIComponent(String(params[%q]))}", segment.Param)
+			} else {
+				fmt.Fprintf(pathExpr, "/%s", segment.Literal)
+			}
+		}
+		if pathExpr.Len() == 0 {
+			pathExpr.WriteString("/")
+		}
+
+		fmt.Fprintf(code, "  /** %s %s", endpoint.Method, endpoint.Path)
+		if endpoint.Title != "" {
+			fmt.Fprintf(code, " - %s", endpoint.Title)
+		}
+		if endpoint.Deprecated {
+			fmt.Fprintf(code, " @deprecated")
+		}
+		fmt.Fprintf(code, " */\n")
+		fmt.Fprintf(code, "  %s(%s): Promise<%s> {\n", methodName, strings.Join(args, ", "), result)
+		fmt.Fprintf(code, "    return request<%s>(this.options, {\n", result)
+		fmt.Fprintf(code, "      method: %q,\n", endpoint.Method)
+		fmt.Fprintf(code, "      path: `%s`,\n", pathExpr.String())
+		if endpoint.Query != nil {
+			fmt.Fprintf(code, "      query,\n")
+			if grammar, _ := newQueryGrammar(reflect.TypeOf(endpoint.Query)); !grammar.empty() {
+				fmt.Fprintf(code, "      grammar: %s,\n", typeScriptQueryGrammar(grammar))
+			}
+		}
+		if payload != nil {
+			fmt.Fprintf(code, "      body: payload,\n      contentType: %q,\n", payload.MediaType)
+			if isFormMediaType(payload.MediaType) {
+				fmt.Fprintf(code, "      form: %s,\n", typeScriptFormNames(payload.Value))
+			}
+		}
+		fmt.Fprintf(code, "      init,\n    });\n  }\n")
+
+		tags := endpoint.Tags
+		if len(tags) == 0 {
+			tags = []string{typeScriptDefaultTag}
+		}
+		for _, tag := range tags {
+			operationsByTag[tag] = append(operationsByTag[tag], typeScriptOperation{methodName: methodName, code: code.String()})
+		}
+	}
+
+	files := map[string][]byte{
+		typeScriptRuntimeFile: []byte(typeScriptHeader + typeScriptRuntime),
+	}
+
+	names := make([]string, 0, len(ts.interfaces))
+	for name := range ts.interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	types := new(bytes.Buffer)
+	types.WriteString(typeScriptHeader)
+	for i, name := range names {
+		if i > 0 {
+			types.WriteString("\n")
+		}
+		types.WriteString(ts.interfaces[name])
+	}
+	files[typeScriptTypesFile] = types.Bytes()
+
+	for tag, operations := range operationsByTag {
+		sort.SliceStable(operations, func(i, j int) bool {
+			return operations[i].methodName < operations[j].methodName
+		})
+
+		client := new(bytes.Buffer)
+		client.WriteString(typeScriptHeader)
+		var used []string
+		for _, name := range names {
+			for _, operation := range operations {
+				if containsTypeScriptIdentifier(operation.code, name) {
+					used = append(used, name)
+					break
+				}
+			}
+		}
+		if len(used) > 0 {
+			fmt.Fprintf(client, "import type { %s } from \"./types\";\n", strings.Join(used, ", "))
+		}
+		fmt.Fprintf(client, "import { ClientOptions, request } from \"./runtime\";\n\n")
+		fmt.Fprintf(client, "export class %sClient {\n  constructor(private readonly options: ClientOptions) {}\n", goIdentifier(tag, true))
+		for _, operation := range operations {
+			client.WriteString("\n" + operation.code)
+		}
+		client.WriteString("}\n")
+		files[goIdentifier(tag, false)+".ts"] = client.Bytes()
+	}
+	return files, nil
+}
+
+// WriteTypeScript writes the output of GenerateTypeScript into dir.
+func (r *registry[T]) WriteTypeScript(dir string) error {
+	files, err := r.GenerateTypeScript()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const typeScriptRuntime = `export interface ClientOptions {
+  baseUrl: string;
+  fetch?: typeof fetch;
+  headers?: Record<string, string>;
+}
+
+export class ApiError extends Error {
+  constructor(readonly status: number, readonly body: unknown) {
+    super(` + "`request failed with status ${status}`" + `);
+  }
+}
+
+export interface RequestOptions {
+  method: string;
+  path: string;
+  query?: object;
+  body?: unknown;
+  contentType?: string;
+  form?: Record<string, string>;
+  grammar?: QueryGrammar;
+  init?: RequestInit;
+}
+
+// QueryGrammar names the sort and filter properties of a query. Filterable properties are only sent
+// as conditions of the filter.
+export interface QueryGrammar {
+  sort?: string;
+  filter?: string;
+  filterable?: string[];
+}
+
+function encodeBody(req: RequestOptions): BodyInit | undefined {
+  if (req.body === undefined) return undefined;
+  if (req.form === undefined) return JSON.stringify(req.body);
+
+  const multipart = new FormData();
+  const urlencoded = new URLSearchParams();
+  for (const [key, value] of Object.entries(req.body as object)) {
+    if (value === undefined || value === null) continue;
+    const name = req.form[key] ?? key;
+    for (const v of Array.isArray(value) ? value : [value]) {
+      if (req.contentType !== "multipart/form-data") {
+        urlencoded.append(name, String(v));
+      } else if (v instanceof Blob) {
+        multipart.append(name, v);
+      } else {
+        multipart.append(name, String(v));
+      }
+    }
+  }
+  return req.contentType === "multipart/form-data" ? multipart : urlencoded;
+}
+
+export async function request<T>(options: ClientOptions, req: RequestOptions): Promise<T> {
+  const url = new URL(options.baseUrl.replace(/\/$/, "") + req.path);
+  for (const [key, value] of Object.entries(req.query ?? {})) {
+    if (value === undefined || value === null || req.grammar?.filterable?.includes(key)) continue;
+    if (key === req.grammar?.sort) {
+      const keys = (value as { Key?: string; Desc?: boolean }[]).map((k) => (k.Desc ? "-" : "") + (k.Key ?? ""));
+      if (keys.length > 0) url.searchParams.set(key, keys.join(","));
+      continue;
+    }
+    if (key === req.grammar?.filter) {
+      for (const f of value as { Field?: string; Operator?: string; Values?: string[] }[]) {
+        url.searchParams.set(` + "`${key}[${f.Field}][${f.Operator}]`" + `, (f.Values ?? []).join(","));
+      }
+      continue;
+    }
+    for (const v of Array.isArray(value) ? value : [value]) {
+      url.searchParams.append(key, String(v));
+    }
+  }
+
+  const headers: Record<string, string> = { Accept: "application/json", ...options.headers };
+  // the boundary of multipart bodies is set by fetch
+  if (req.contentType && req.contentType !== "multipart/form-data") headers["Content-Type"] = req.contentType;
+
+  const res = await (options.fetch ?? fetch)(url.toString(), {
+    ...req.init,
+    method: req.method,
+    headers: { ...headers, ...(req.init?.headers as Record<string, string> | undefined) },
+    body: encodeBody(req),
+  });
+
+  const text = await res.text();
+  const body = text.length > 0 && (res.headers.get("Content-Type") ?? "").includes("json") ? JSON.parse(text) : text;
+  if (!res.ok) {
+    throw new ApiError(res.status, body);
+  }
+  return body as T;
+}
+`
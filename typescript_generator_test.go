@@ -0,0 +1,92 @@
+package specs
+
+import (
+	"errors"
+	"mime/multipart"
+	"sort"
+	"testing"
+	"time"
+)
+
+type typeScriptTestParams struct {
+	ID string `json:"id"`
+}
+
+type typeScriptTestQuery struct {
+	Role   string  `json:"role" validate:"omitempty,oneof=admin member"`
+	Limit  *int    `json:"limit"`
+	Sort   Sort    `json:"sort" sortable:"name"`
+	Filter Filters `json:"filter"`
+	Status string  `json:"status" filterable:"eq,in"`
+}
+
+type typeScriptTestUser struct {
+	ID        string                `json:"id" validate:"required"`
+	FullName  string                `json:"full-name"`
+	Role      string                `json:"role" validate:"oneof=admin member"`
+	Friends   []*typeScriptTestUser `json:"friends"`
+	CreatedAt time.Time             `json:"createdAt"`
+	Meta      map[string]int        `json:"meta"`
+}
+
+type typeScriptTestAvatar struct {
+	Avatar *multipart.FileHeader `form:"avatar" validate:"required"`
+	Alt    string                `json:"alt" form:"alt-text"`
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	r := NewRegistry[string](OperationIDGenerator(MethodPathOperationIDGenerator))
+	r.GET("/users", "").Tags("users").Title("List users").Query(typeScriptTestQuery{}).Response(200, []typeScriptTestUser{}, "Users found")
+	r.GET("/users/{id}", "").Tags("users").Parameters(typeScriptTestParams{}).Response(200, typeScriptTestUser{}, "User found").Deprecated()
+	r.PUT("/users/{id}/avatar", "").Tags("users", "media").Parameters(typeScriptTestParams{}).
+		Payload(typeScriptTestAvatar{}, MediaTypeMultipartForm).
+		Response(204, nil, "Avatar uploaded")
+	r.GET("/health", "").Response(204, nil, "Healthy")
+
+	files, err := r.GenerateTypeScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if want := []string{"default.ts", "media.ts", "runtime.ts", "types.ts", "users.ts"}; !equalStrings(names, want) {
+		t.Fatalf("got files %v, want %v", names, want)
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			assertGolden(t, "typescript_"+name+".golden", files[name])
+		})
+	}
+}
+
+func TestGenerateTypeScriptFailed(t *testing.T) {
+	r := NewRegistry[string]()
+	r.GET("/users", "").Response(200, typeScriptTestUser{}, "")
+	if _, err := r.GenerateTypeScript(); !errors.Is(err, ErrTypeScriptGenerationFailed) {
+		t.Errorf("got %v, want %v", err, ErrTypeScriptGenerationFailed)
+	}
+}
+
+func TestContainsTypeScriptIdentifier(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code: "Promise<User>", want: true},
+		{code: "User[]", want: true},
+		{code: "User", want: true},
+		{code: "Promise<UserPage>"},
+		{code: "Promise<$User>"},
+		{code: "getUser_ | UserPage | User", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := containsTypeScriptIdentifier(tt.code, "User"); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
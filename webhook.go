@@ -0,0 +1,309 @@
+package specs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+var (
+	ErrWebhookAnnotationFailed = errors.New("webhook annotation failed")
+	ErrWebhookInvalid          = errors.New("webhook invalid")
+	ErrWebhookDeliveryFailed   = errors.New("webhook delivery failed")
+	ErrWebhookSignatureInvalid = errors.New("webhook signature invalid")
+)
+
+const (
+	WebhookIDHeader        = "Webhook-Id"
+	WebhookEventHeader     = "Webhook-Event"
+	WebhookSignatureHeader = "Webhook-Signature"
+)
+
+// Webhook declares a request the api sends to the urls registered by its consumers, e.g. when a user
+// changed. Webhooks are annotated as x-webhooks (webhooks in OpenAPI 3.1) and sent using a
+// WebhookSender. Webhooks without responses expect a 200 response.
+func (r *registry[T]) Webhook(name string, method string, payload interface{}) Builder[T] {
+	if name == "" {
+		panic(fmt.Errorf("webhook name is empty: %w", ErrWebhookAnnotationFailed))
+	}
+	if _, ok := r.meta.webhooks[name]; ok {
+		panic(fmt.Errorf("webhook %s already defined: %w", name, ErrWebhookAnnotationFailed))
+	}
+	if !validMethod(method) {
+		panic(fmt.Errorf("invalid method %q of webhook %s: %w", method, name, ErrWebhookAnnotationFailed))
+	}
+	if payload != nil && method == http.MethodGet {
+		panic(fmt.Errorf("webhook %s sends a payload with method GET: %w", name, ErrWebhookAnnotationFailed))
+	}
+
+	e := &Endpoint[T]{
+		OperationID: name,
+		Method:      method,
+		Source:      callerSource(),
+	}
+	r.meta.webhooks[name] = e
+
+	// webhooks are not routes, their operation ID is independent of the registry
+	b := &builder[T]{e: e, routes: map[string]*Endpoint[T]{}, codecs: r.options.Codecs}
+	if payload != nil {
+		b.Payload(payload)
+	}
+	return b
+}
+
+// annotateWebhooks adds the webhooks matching all filters to t and returns them.
+func (r *registry[T]) annotateWebhooks(t *openapi3.T, filters []EndpointFilter[T], schemaGenerator *SchemaRefGenerator, formSchemaGenerator *SchemaRefGenerator, xmlSchemaGenerator *SchemaRefGenerator, schemas openapi3.Schemas) []*Endpoint[T] {
+	var endpoints []*Endpoint[T]
+	webhooks := map[string]*openapi3.PathItem{}
+	for _, name := range sortedKeys(r.meta.webhooks) {
+		e := r.meta.webhooks[name]
+		if !matchesFilters(e, filters) {
+			continue
+		}
+		endpoints = append(endpoints, e)
+
+		operation := annotateOperation(e, schemaGenerator, formSchemaGenerator, xmlSchemaGenerator, schemas)
+		if len(operation.Responses) == 0 {
+			description := "Webhook received"
+			operation.Responses = openapi3.Responses{
+				strconv.Itoa(http.StatusOK): &openapi3.ResponseRef{Value: &openapi3.Response{Description: &description}},
+			}
+		}
+		webhooks[name] = &openapi3.PathItem{}
+		webhooks[name].SetOperation(e.Method, operation)
+	}
+
+	if len(webhooks) > 0 {
+		if t.Extensions == nil {
+			t.Extensions = map[string]interface{}{}
+		}
+		t.Extensions["x-webhooks"] = webhooks
+	}
+	return endpoints
+}
+
+type WebhookSenderOption func(*webhookSenderOptions)
+
+type webhookSenderOptions struct {
+	client *http.Client
+}
+
+// WebhookHTTPClient replaces the client delivering webhooks, http.DefaultClient by default.
+func WebhookHTTPClient(client *http.Client) WebhookSenderOption {
+	return func(o *webhookSenderOptions) {
+		o.client = client
+	}
+}
+
+type webhookTarget struct {
+	method  string
+	payload reflect.Type
+	schema  *openapi3.Schema
+}
+
+// WebhookSender delivers the webhooks of a registry. Payloads are validated against the declared
+// schema and signed with the secret, see VerifyWebhookSignature.
+type WebhookSender struct {
+	secret   []byte
+	client   *http.Client
+	webhooks map[string]webhookTarget
+}
+
+// NewWebhookSender returns a WebhookSender of the webhooks declared on the registry.
+func NewWebhookSender[T interface{}](r *registry[T], secret []byte, opts ...WebhookSenderOption) *WebhookSender {
+	options := &webhookSenderOptions{
+		client: http.DefaultClient,
+	}
+	for _, applyOption := range opts {
+		applyOption(options)
+	}
+
+	s := &WebhookSender{
+		secret:   secret,
+		client:   options.client,
+		webhooks: make(map[string]webhookTarget, len(r.meta.webhooks)),
+	}
+	schemas := make(openapi3.Schemas)
+	schemaGenerator := NewSchemaRefGenerator(WithTypeInfoCache(NewTypeInfoCache()))
+	for name, e := range r.meta.webhooks {
+		target := webhookTarget{method: e.Method}
+		if payload := firstPayload(e.Payload); payload != nil {
+			schemaRef, err := schemaGenerator.GenerateSchemaRef(payload, schemas)
+			if err != nil {
+				panic(fmt.Errorf("failed to generate the schema of webhook %s: %w", name, err))
+			}
+			target.payload = removeIndirect(reflect.TypeOf(payload))
+			target.schema = nullableCollections(ResolveSchemaRefs(schemaRef, schemas).Value, map[*openapi3.Schema]*openapi3.Schema{})
+		}
+		s.webhooks[name] = target
+	}
+	return s
+}
+
+// nullableCollections returns a copy of schema whose properties of arrays and maps allow null unless
+// they are required, since nil slices and maps are encoded as null.
+func nullableCollections(schema *openapi3.Schema, copies map[*openapi3.Schema]*openapi3.Schema) *openapi3.Schema {
+	if schema == nil {
+		return nil
+	}
+	if c, ok := copies[schema]; ok {
+		return c
+	}
+	c := *schema
+	copies[schema] = &c
+
+	required := map[string]struct{}{}
+	for _, name := range schema.Required {
+		required[name] = struct{}{}
+	}
+	if schema.Properties != nil {
+		c.Properties = make(openapi3.Schemas, len(schema.Properties))
+		for name, property := range schema.Properties {
+			c.Properties[name] = nullableCollectionsRef(property, copies)
+			if _, ok := required[name]; ok || property == nil || property.Value == nil {
+				continue
+			}
+			if value := c.Properties[name].Value; !value.Nullable && (value.Type == "array" || (value.Type == "object" && value.AdditionalProperties.Schema != nil)) {
+				nullable := *value
+				nullable.Nullable = true
+				c.Properties[name] = openapi3.NewSchemaRef("", &nullable)
+			}
+		}
+	}
+	c.Items = nullableCollectionsRef(schema.Items, copies)
+	c.AdditionalProperties.Schema = nullableCollectionsRef(schema.AdditionalProperties.Schema, copies)
+	return &c
+}
+
+func nullableCollectionsRef(ref *openapi3.SchemaRef, copies map[*openapi3.Schema]*openapi3.Schema) *openapi3.SchemaRef {
+	if ref == nil || ref.Value == nil {
+		return ref
+	}
+	return openapi3.NewSchemaRef("", nullableCollections(ref.Value, copies))
+}
+
+// Send validates the payload of the webhook name, signs it and delivers it to url. Receivers
+// responding with a status other than 2xx fail with ErrWebhookDeliveryFailed.
+func (s *WebhookSender) Send(ctx context.Context, url string, name string, payload interface{}) error {
+	target, ok := s.webhooks[name]
+	if !ok {
+		return fmt.Errorf("webhook %s is not declared: %w", name, ErrWebhookInvalid)
+	}
+
+	var body []byte
+	if target.payload != nil {
+		if payload == nil || removeIndirect(reflect.TypeOf(payload)) != target.payload {
+			return fmt.Errorf("payload of webhook %s must be %v, got %T: %w", name, target.payload, payload, ErrWebhookInvalid)
+		}
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook %s: %v: %w", name, err, ErrWebhookInvalid)
+		}
+		var value interface{}
+		if err := json.Unmarshal(b, &value); err != nil {
+			return fmt.Errorf("failed to unmarshal webhook %s: %v: %w", name, err, ErrWebhookInvalid)
+		}
+		if err := target.schema.VisitJSON(value); err != nil {
+			return fmt.Errorf("payload of webhook %s does not match its schema: %v: %w", name, err, ErrWebhookInvalid)
+		}
+		body = b
+	} else if payload != nil {
+		return fmt.Errorf("webhook %s declares no payload: %w", name, ErrWebhookInvalid)
+	}
+
+	id, err := gonanoid.New()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, target.method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(WebhookIDHeader, id)
+	req.Header.Set(WebhookEventHeader, name)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(s.secret, time.Now(), body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook %s: %v: %w", name, err, ErrWebhookDeliveryFailed)
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("receiver of webhook %s responded with %d: %w", name, res.StatusCode, ErrWebhookDeliveryFailed)
+	}
+	return nil
+}
+
+// TypedWebhook returns a function sending the webhook name, whose payload is checked at compile time.
+// It panics if the webhook is not declared or is declared with a payload other than V.
+func TypedWebhook[V interface{}](s *WebhookSender, name string) func(ctx context.Context, url string, payload V) error {
+	target, ok := s.webhooks[name]
+	if !ok {
+		panic(fmt.Errorf("webhook %s is not declared: %w", name, ErrWebhookAnnotationFailed))
+	}
+	if t := removeIndirect(reflect.TypeOf((*V)(nil)).Elem()); t != target.payload {
+		panic(fmt.Errorf("webhook %s is declared with %v, not %v: %w", name, target.payload, t, ErrWebhookAnnotationFailed))
+	}
+	return func(ctx context.Context, url string, payload V) error {
+		return s.Send(ctx, url, name, payload)
+	}
+}
+
+// SignWebhook returns the signature of the body sent at, in the form t=<unix time>,v1=<hex hmac-sha256>.
+// The hmac is computed over "<unix time>.<body>".
+func SignWebhook(secret []byte, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(webhookMAC(secret, timestamp, body))
+}
+
+// VerifyWebhookSignature verifies the signature of a received webhook, see SignWebhook. Signatures
+// older than tolerance are rejected to prevent replays, a tolerance of 0 accepts any age.
+func VerifyWebhookSignature(secret []byte, signature string, body []byte, tolerance time.Duration) error {
+	var timestamp, mac string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			mac = value
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", timestamp, ErrWebhookSignatureInvalid)
+	}
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return fmt.Errorf("signature is older than %v: %w", tolerance, ErrWebhookSignatureInvalid)
+	}
+	got, err := hex.DecodeString(mac)
+	if err != nil || !hmac.Equal(got, webhookMAC(secret, timestamp, body)) {
+		return ErrWebhookSignatureInvalid
+	}
+	return nil
+}
+
+func webhookMAC(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,252 @@
+package specs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type webhookTestSubscription struct {
+	CallbackURL string `json:"callbackUrl" validate:"required,url"`
+}
+
+type webhookTestEvent struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type webhookTestGroup struct {
+	Name    string            `json:"name"`
+	Members []string          `json:"members"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type webhookTestUser struct {
+	ID      string             `json:"id" validate:"required,max=8"`
+	Friends []*webhookTestUser `json:"friends,omitempty"`
+}
+
+func TestAnnotateCallbacksAndWebhooks(t *testing.T) {
+	r := NewRegistry[string]()
+	r.POST("/subscriptions", "").
+		Payload(webhookTestSubscription{}).
+		Response(201, nil, "Subscribed").
+		Callback("onEvent", "{$request.body#/callbackUrl}", http.MethodPost, webhookTestEvent{}, Respond(204, nil, "Event received"))
+	r.Webhook("userChanged", http.MethodPost, webhookTestUser{}).Title("User changed").Tags("users")
+	r.Webhook("ping", http.MethodPost, nil).Visibility(VisibilityInternal)
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc, VisibilityFilter[string](VisibilityPublic))
+	loader := openapi3.NewLoader()
+	if err := loader.ResolveRefsIn(doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("should annotate callbacks", func(t *testing.T) {
+		callback := doc.Paths.Find("/subscriptions").Post.Callbacks["onEvent"]
+		if callback == nil || (*callback.Value)["{$request.body#/callbackUrl}"] == nil {
+			t.Fatalf("got callbacks %v", doc.Paths.Find("/subscriptions").Post.Callbacks)
+		}
+		operation := (*callback.Value)["{$request.body#/callbackUrl}"].Post
+		if operation.RequestBody.Value.Content.Get("application/json").Schema.Value.Properties["id"] == nil || operation.Responses.Get(204) == nil {
+			t.Errorf("got operation %+v", operation)
+		}
+	})
+	t.Run("should annotate webhooks matching the filters", func(t *testing.T) {
+		webhooks, _ := doc.Extensions["x-webhooks"].(map[string]*openapi3.PathItem)
+		if len(webhooks) != 1 || webhooks["userChanged"] == nil {
+			t.Fatalf("got webhooks %v", webhooks)
+		}
+		operation := webhooks["userChanged"].Post
+		if operation.Summary != "User changed" || operation.Responses.Get(200) == nil {
+			t.Errorf("got operation %+v", operation)
+		}
+		if doc.Tags.Get("users") == nil {
+			t.Errorf("got tags %v", doc.Tags)
+		}
+	})
+	t.Run("should convert webhooks to OpenAPI 3.1", func(t *testing.T) {
+		doc31, err := ConvertToOpenAPI31(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := doc31["webhooks"].(map[string]interface{})["userChanged"]; !ok {
+			t.Errorf("got %v", doc31["webhooks"])
+		}
+	})
+}
+
+func TestCallbackRejectsInvalidDeclarations(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *registry[string])
+		want     error
+	}{
+		{
+			name: "should reject unnamed callbacks",
+			register: func(r *registry[string]) {
+				r.POST("/", "").Callback("", "{$request.body#/url}", http.MethodPost, nil)
+			},
+			want: ErrCallbackAnnotationFailed,
+		},
+		{
+			name: "should reject invalid expressions",
+			register: func(r *registry[string]) {
+				r.POST("/", "").Callback("a", "{$request.body#/url", http.MethodPost, nil)
+			},
+			want: ErrCallbackAnnotationFailed,
+		},
+		{
+			name: "should reject unknown expressions",
+			register: func(r *registry[string]) {
+				r.POST("/", "").Callback("a", "{request.body#/url}", http.MethodPost, nil)
+			},
+			want: ErrCallbackAnnotationFailed,
+		},
+		{
+			name: "should reject invalid methods",
+			register: func(r *registry[string]) {
+				r.POST("/", "").Callback("a", "{$request.body#/url}", "post", nil)
+			},
+			want: ErrCallbackAnnotationFailed,
+		},
+		{
+			name: "should reject duplicate callbacks",
+			register: func(r *registry[string]) {
+				r.POST("/", "").Callback("a", "{$request.body#/url}", http.MethodPost, nil).Callback("a", "{$request.body#/url}", http.MethodPost, nil)
+			},
+			want: ErrCallbackAnnotationFailed,
+		},
+		{
+			name: "should reject callbacks sending payloads with GET",
+			register: func(r *registry[string]) {
+				r.POST("/", "").Callback("a", "{$request.body#/url}", http.MethodGet, webhookTestEvent{})
+			},
+			want: ErrCallbackAnnotationFailed,
+		},
+		{
+			name: "should reject webhooks sending payloads with GET",
+			register: func(r *registry[string]) {
+				r.Webhook("a", http.MethodGet, webhookTestEvent{})
+			},
+			want: ErrWebhookAnnotationFailed,
+		},
+		{
+			name: "should reject duplicate webhooks",
+			register: func(r *registry[string]) {
+				r.Webhook("a", http.MethodPost, nil)
+				r.Webhook("a", http.MethodPost, nil)
+			},
+			want: ErrWebhookAnnotationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, tt.want) {
+					t.Errorf("got %v, want %v", err, tt.want)
+				}
+			}()
+			tt.register(NewRegistry[string]())
+		})
+	}
+}
+
+func TestWebhookSender(t *testing.T) {
+	secret := []byte("secret")
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		if err := VerifyWebhookSignature(secret, req.Header.Get(WebhookSignatureHeader), body, time.Minute); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if req.Header.Get(WebhookIDHeader) == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		received = append(received, req.Header.Get(WebhookEventHeader)+" "+string(body))
+	}))
+	defer server.Close()
+
+	r := NewRegistry[string]()
+	r.Webhook("userChanged", http.MethodPost, webhookTestUser{})
+	r.Webhook("groupChanged", http.MethodPost, webhookTestGroup{})
+	r.Webhook("ping", http.MethodPost, nil)
+	s := NewWebhookSender(r, secret)
+	tests := []struct {
+		name    string
+		sender  *WebhookSender
+		webhook string
+		payload interface{}
+		want    error
+	}{
+		{name: "should send valid payloads", sender: s, webhook: "userChanged", payload: webhookTestUser{ID: "1", Friends: []*webhookTestUser{{ID: "2"}}}},
+		{name: "should send pointers to payloads", sender: s, webhook: "userChanged", payload: &webhookTestUser{ID: "1"}},
+		{name: "should send nil slices and maps", sender: s, webhook: "groupChanged", payload: webhookTestGroup{Name: "admins"}},
+		{name: "should send webhooks without payload", sender: s, webhook: "ping"},
+		{name: "should reject undeclared webhooks", sender: s, webhook: "missing", want: ErrWebhookInvalid},
+		{name: "should reject payloads of other types", sender: s, webhook: "userChanged", payload: webhookTestEvent{ID: "1"}, want: ErrWebhookInvalid},
+		{name: "should reject payloads not matching the schema", sender: s, webhook: "userChanged", payload: webhookTestUser{ID: "123456789"}, want: ErrWebhookInvalid},
+		{name: "should reject payloads of nested types not matching the schema", sender: s, webhook: "userChanged", payload: webhookTestUser{ID: "1", Friends: []*webhookTestUser{{ID: "123456789"}}}, want: ErrWebhookInvalid},
+		{name: "should reject payloads of webhooks without payload", sender: s, webhook: "ping", payload: webhookTestEvent{}, want: ErrWebhookInvalid},
+		{name: "should fail if the receiver rejects the webhook", sender: NewWebhookSender(r, []byte("other")), webhook: "ping", want: ErrWebhookDeliveryFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sender.Send(context.Background(), server.URL, tt.webhook, tt.payload); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if len(received) != 4 || received[0] != `userChanged {"id":"1","friends":[{"id":"2"}]}` {
+		t.Errorf("got %q", received)
+	}
+
+	t.Run("should send typed webhooks", func(t *testing.T) {
+		if err := TypedWebhook[webhookTestUser](s, "userChanged")(context.Background(), server.URL, webhookTestUser{ID: "1"}); err != nil {
+			t.Error(err)
+		}
+	})
+	t.Run("should reject typed webhooks of other types", func(t *testing.T) {
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, ErrWebhookAnnotationFailed) {
+				t.Errorf("got %v, want %v", err, ErrWebhookAnnotationFailed)
+			}
+		}()
+		TypedWebhook[webhookTestEvent](s, "userChanged")
+	})
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	secret, body := []byte("secret"), []byte(`{"id":"1"}`)
+	tests := []struct {
+		name      string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{name: "should accept valid signatures", signature: SignWebhook(secret, time.Now(), body), body: body},
+		{name: "should reject modified bodies", signature: SignWebhook(secret, time.Now(), body), body: []byte(`{"id":"2"}`), want: ErrWebhookSignatureInvalid},
+		{name: "should reject other secrets", signature: SignWebhook([]byte("other"), time.Now(), body), body: body, want: ErrWebhookSignatureInvalid},
+		{name: "should reject old signatures", signature: SignWebhook(secret, time.Now().Add(-time.Hour), body), body: body, want: ErrWebhookSignatureInvalid},
+		{name: "should reject malformed signatures", signature: "v1=abc", body: body, want: ErrWebhookSignatureInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyWebhookSignature(secret, tt.signature, tt.body, time.Minute); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package specs
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	xmlNameType = reflect.TypeOf(xml.Name{})
+)
+
+// isXMLMediaType reports whether bodies of the media type are encoded as XML, e.g. application/xml,
+// text/xml or application/atom+xml.
+func isXMLMediaType(mediaType string) bool {
+	mediaType, err := normalizeMediaType(mediaType)
+	if err != nil {
+		return false
+	}
+	return mediaType == MediaTypeXML || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// documentedInXML reports whether the field is an element or attribute of the XML encoding of its
+// struct. The XMLName field names the element of the struct itself.
+func (f Field) documentedInXML() bool {
+	if f.Type == xmlNameType {
+		return false
+	}
+	x := f.fieldInfo_XML
+	return x == nil || !(x.XML_Omit || x.XML_CharData || x.XML_InnerXML || x.XML_Comment)
+}
+
+// xmlElementName returns the name of the element of the struct t as encoded by encoding/xml, which is
+// the name of its XMLName field or the name of the type without type arguments.
+func xmlElementName(t reflect.Type) string {
+	// XMLName fields are usually not encoded as json and thus not part of the type info
+	if t.Kind() == reflect.Struct {
+		if f, ok := t.FieldByName("XMLName"); ok && f.Type == xmlNameType {
+			if name, x := new(fieldInfo_XML).Resolve(f); !x.XML_Omit && f.Tag.Get("xml") != "" {
+				return name
+			}
+		}
+	}
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i != -1 {
+		name = name[:i]
+	}
+	return name
+}
+
+// withXMLPropertyRef adds the property of the field to schema, nested in the elements enclosing it
+// (a>b). Slices tagged a>b are documented as a wrapped array a of b elements. It returns the schema
+// the property was added to.
+func withXMLPropertyRef(schema *openapi3.Schema, field *Field, ref *openapi3.SchemaRef) *openapi3.Schema {
+	x := field.fieldInfo_XML
+	if x == nil {
+		x = &fieldInfo_XML{XML_Name: field.Name}
+	}
+	isComponent := strings.HasPrefix(ref.Ref, "#/components/")
+	isList := !isComponent && ref.Value != nil && ref.Value.Type == "array"
+
+	parents, name := x.XML_Parents, x.XML_Name
+	wrapped := isList && len(parents) > 0
+	if wrapped {
+		parents, name = parents[:len(parents)-1], parents[len(parents)-1]
+	}
+	for _, parent := range parents {
+		parentRef, ok := schema.Properties[parent]
+		if !ok || parentRef.Value == nil || strings.HasPrefix(parentRef.Ref, "#/components/") {
+			parentRef = openapi3.NewObjectSchema().NewRef()
+			parentRef.Value.XML = &openapi3.XML{Name: parent}
+			schema.WithPropertyRef(parent, parentRef)
+		}
+		schema = parentRef.Value
+	}
+
+	switch {
+	case isList:
+		if items := ref.Value.Items; items != nil && items.Value != nil && !strings.HasPrefix(items.Ref, "#/components/") {
+			items.Value.XML = &openapi3.XML{Name: x.XML_Name}
+		}
+		ref.Value.XML = &openapi3.XML{Name: name, Wrapped: wrapped}
+	case !isComponent && ref.Value != nil:
+		ref.Value.XML = &openapi3.XML{Name: name, Attribute: x.XML_Attr}
+	}
+	schema.WithPropertyRef(name, ref)
+	return schema
+}
@@ -0,0 +1,142 @@
+package specs
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type xmlTestUser struct {
+	XMLName xml.Name       `json:"-" xml:"user"`
+	ID      string         `json:"id" xml:"id,attr"`
+	Name    string         `json:"name" xml:"full-name,omitempty" validate:"required"`
+	Roles   []string       `json:"roles" xml:"roles>role"`
+	Tags    []string       `json:"tags" xml:"tag"`
+	City    string         `json:"city" xml:"address>city"`
+	Street  string         `json:"street" xml:"address>street"`
+	Note    string         `json:"note" xml:",chardata"`
+	Secret  string         `json:"secret" xml:"-"`
+	Friends []*xmlTestUser `json:"friends" xml:"friends>friend"`
+	Age     int            `json:"age"`
+}
+
+func TestFieldsAppendXML(t *testing.T) {
+	fields := Fields{}.Append(nil, reflect.TypeOf(xmlTestUser{}))
+	got := map[string]fieldInfo_XML{}
+	for _, field := range fields {
+		got[field.Name] = *field.fieldInfo_XML
+	}
+
+	tests := []struct {
+		field string
+		want  fieldInfo_XML
+	}{
+		{field: "id", want: fieldInfo_XML{XML_Name: "id", XML_Attr: true}},
+		{field: "name", want: fieldInfo_XML{XML_Name: "full-name", XML_OmitEmpty: true}},
+		{field: "roles", want: fieldInfo_XML{XML_Name: "role", XML_Parents: []string{"roles"}}},
+		{field: "note", want: fieldInfo_XML{XML_Name: "Note", XML_CharData: true}},
+		{field: "secret", want: fieldInfo_XML{XML_Omit: true}},
+		{field: "age", want: fieldInfo_XML{XML_Name: "Age"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if !reflect.DeepEqual(got[tt.field], tt.want) {
+				t.Errorf("got %+v, want %+v", got[tt.field], tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateXML(t *testing.T) {
+	r := NewRegistry[string]()
+	r.POST("/users", "").
+		Payload(xmlTestUser{}, MediaTypeXML).
+		Response(200, xmlTestUser{}, "User created", MediaTypeJSON, MediaTypeXML)
+
+	doc := &openapi3.T{OpenAPI: "3.0.3", Info: &openapi3.Info{Title: "test", Version: "1.0.0"}}
+	r.Annotate(doc)
+	loader := openapi3.NewLoader()
+	if err := loader.ResolveRefsIn(doc, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		t.Fatal(err)
+	}
+
+	operation := doc.Paths.Find("/users").Post
+	schema := operation.RequestBody.Value.Content.Get(MediaTypeXML).Schema.Value
+	t.Run("should name the root element", func(t *testing.T) {
+		if schema.XML == nil || schema.XML.Name != "user" {
+			t.Errorf("got %+v", schema.XML)
+		}
+	})
+	t.Run("should name the properties by their xml tags", func(t *testing.T) {
+		want := []string{"Age", "address", "friends", "full-name", "id", "roles", "tag"}
+		if got := sortedKeys(schema.Properties); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if !reflect.DeepEqual(schema.Required, []string{"full-name"}) {
+			t.Errorf("got required %v", schema.Required)
+		}
+	})
+	t.Run("should declare attributes", func(t *testing.T) {
+		if x := schema.Properties["id"].Value.XML; x == nil || !x.Attribute || x.Name != "id" {
+			t.Errorf("got %+v", x)
+		}
+	})
+	t.Run("should declare wrapped and unwrapped arrays", func(t *testing.T) {
+		roles := schema.Properties["roles"].Value
+		if roles.XML == nil || !roles.XML.Wrapped || roles.Items.Value.XML.Name != "role" {
+			t.Errorf("got roles %+v", roles)
+		}
+		tags := schema.Properties["tag"].Value
+		if tags.XML == nil || tags.XML.Wrapped || tags.Items.Value.XML.Name != "tag" {
+			t.Errorf("got tags %+v", tags)
+		}
+	})
+	t.Run("should nest the elements of a>b", func(t *testing.T) {
+		address := schema.Properties["address"].Value
+		if address.Type != "object" || address.Properties["city"] == nil || address.Properties["street"] == nil {
+			t.Errorf("got address %+v", address)
+		}
+	})
+	t.Run("should declare cycles as XML components", func(t *testing.T) {
+		if ref := schema.Properties["friends"].Value.Items.Ref; ref != "#/components/schemas/xmlTestUserXML" {
+			t.Errorf("got %s", ref)
+		}
+		if doc.Components.Schemas["xmlTestUserXML"] == nil || doc.Components.Schemas["xmlTestUser"] == nil {
+			t.Errorf("got components %v", doc.Components.Schemas)
+		}
+	})
+	t.Run("should keep the json names of other media types", func(t *testing.T) {
+		response := operation.Responses.Get(200).Value.Content
+		if json := response.Get(MediaTypeJSON).Schema.Value; json.XML != nil || json.Properties["name"] == nil || json.Properties["note"] == nil {
+			t.Errorf("got %+v", json)
+		}
+		if xml := response.Get(MediaTypeXML).Schema.Value; xml.Properties["full-name"] == nil {
+			t.Errorf("got %+v", xml)
+		}
+	})
+}
+
+func TestIsXMLMediaType(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      bool
+	}{
+		{mediaType: "application/xml", want: true},
+		{mediaType: "text/xml; charset=utf-8", want: true},
+		{mediaType: "application/atom+xml", want: true},
+		{mediaType: "application/json"},
+		{mediaType: "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			if got := isXMLMediaType(tt.mediaType); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}